@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/alexissavin/terraform-provider-solidserver/solidserver"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/plugin"
 )
 
@@ -9,4 +10,7 @@ func main() {
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: solidserver.Provider,
 	})
+
+	// Close the SOLIDserver sessions and audit logs once Terraform stopped the plugin
+	sdsclient.Shutdown()
 }
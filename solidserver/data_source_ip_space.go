@@ -1,11 +1,9 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 )
 
 func dataSourceipspace() *schema.Resource {
@@ -33,65 +31,25 @@ func dataSourceipspace() *schema.Resource {
 }
 
 func dataSourceipspaceRead(d *schema.ResourceData, meta interface{}) error {
-	d.SetId("")
-
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-	// Useful ?
-	if s == nil {
-		return fmt.Errorf("no SOLIDserver known on space %s", d.Get("name").(string))
-	}
+	d.SetId("")
 
 	log.Printf("[DEBUG] SOLIDServer - Looking for space: %s\n", d.Get("name").(string))
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("WHERE", "site_name='"+d.Get("name").(string)+"'")
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_site_list", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.SetId(buf[0]["site_id"].(string))
-			d.Set("name", buf[0]["site_name"].(string))
-			d.Set("class", buf[0]["site_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["site_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to read information from space: %s (%s)\n", d.Get("name").(string), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to read information from space: %s\n", d.Get("name").(string))
-		}
+	space, err := s.GetSpaceByName(ctx, d.Get("name").(string))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find space: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(space.ID)
+	d.Set("name", space.Name)
+	d.Set("class", space.Class)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), space.ClassParameters))
+
+	return nil
 }
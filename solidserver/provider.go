@@ -1,31 +1,88 @@
 package solidserver
 
 import (
+	"context"
+	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"strings"
 )
 
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Required:    false,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_HOST", nil),
-				Description: "SOLIDServer Hostname or IP address",
+				Description: "SOLIDServer Hostname or IP address, or a comma separated list of SOLIDserver cluster members tried in order",
 			},
 			"username": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Required:    false,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_USERNAME", nil),
 				Description: "SOLIDServer API user's ID",
 			},
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Required:    false,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_PASSWORD", nil),
+				Sensitive:   true,
 				Description: "SOLIDServer API user's password",
 			},
+			"base_url": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_BASEURL", nil),
+				Description: "Full URL of the SOLIDServer API (ex: https://proxy.local:8443/solidserver), replaces host, port and path_prefix",
+			},
+			"port": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_PORT", 0),
+				Description: "TCP port of the SOLIDServer API, when the host does not specify one (Default : 443)",
+			},
+			"path_prefix": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_PATHPREFIX", nil),
+				Description: "Path prefix of the SOLIDServer API, when it is published behind a reverse proxy",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_PROXYURL", nil),
+				Description: "URL of the HTTP proxy used to reach the SOLIDServer (Default : HTTPS_PROXY, NO_PROXY is honoured)",
+			},
+			"password_file": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_PASSWORDFILE", nil),
+				Description: "File containing the SOLIDServer API user's password, instead of password",
+			},
+			"credentials_command": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_CREDENTIALSCOMMAND", nil),
+				Description: "Command printing the SOLIDServer API credentials as JSON ({\"username\": \"...\", \"password\": \"...\"}), run again when they are rejected",
+			},
+			"netrc": {
+				Type:        schema.TypeBool,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_NETRC", false),
+				Description: "Look up the SOLIDServer API credentials of the host in $NETRC or ~/.netrc (Default : disabled)",
+			},
 			"sslverify": {
 				Type:        schema.TypeBool,
 				Required:    false,
@@ -40,6 +97,152 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_ADDITIONALTRUSTCERTSFILE", nil),
 				Description: "PEM formatted file with additional certificates to trust for TLS connection",
 			},
+			"max_idle_connections": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_MAXIDLECONNECTIONS", sdsclient.DefaultMaxIdleConns),
+				Description: "Maximum number of idle connections kept open to the SOLIDserver (Default : 16)",
+			},
+			"max_connections_per_host": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_MAXCONNECTIONSPERHOST", sdsclient.DefaultMaxConnsPerHost),
+				Description: "Maximum number of connections opened to the SOLIDserver (Default : 16)",
+			},
+			"timeout": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_TIMEOUT", sdsclient.DefaultTimeout),
+				Description: "Timeout of a single API call in seconds (Default : 16)",
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_MAXRETRIES", sdsclient.DefaultMaxRetries),
+				Description: "Maximum number of retries of a failed API call (Default : 3)",
+			},
+			"retry_backoff_base": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_RETRYBACKOFFBASE", sdsclient.DefaultRetryBackoffBase),
+				Description: "Delay before the first retry in milliseconds, doubled on each retry (Default : 128)",
+			},
+			"retry_backoff_cap": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_RETRYBACKOFFCAP", sdsclient.DefaultRetryBackoffCap),
+				Description: "Maximum delay between two retries in milliseconds (Default : 8000)",
+			},
+			"retryable_statuses": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_RETRYABLESTATUSES", sdsclient.DefaultRetryableStatuses),
+				Description: "Comma separated list of HTTP status codes on which API calls are retried (Default : 429,502,503,504)",
+			},
+			"retryable_network_errors": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_RETRYABLENETWORKERRORS", sdsclient.DefaultRetryableNetworkErrors),
+				Description: "Comma separated list of network errors (dns, refused, reset, eof, timeout) on which API calls are retried (Default : dns,refused,reset,eof,timeout)",
+			},
+			"max_concurrent_requests": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_MAXCONCURRENTREQUESTS", sdsclient.DefaultMaxConcurrentRequests),
+				Description: "Maximum number of API calls sent at the same time, 0 for unlimited (Default : 8)",
+			},
+			"requests_per_second": {
+				Type:        schema.TypeFloat,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_REQUESTSPERSECOND", sdsclient.DefaultRequestsPerSecond),
+				Description: "Maximum number of API calls per second, 0 for unlimited (Default : 0)",
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_READONLY", false),
+				Description: "Refuse every API call which could change the SOLIDServer, for plan and refresh only runs (Default : disabled)",
+			},
+			"list_page_size": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_LISTPAGESIZE", sdsclient.DefaultListPageSize),
+				Description: "Number of objects retrieved by each call of a list service (Default : 500)",
+			},
+			"list_max_results": {
+				Type:        schema.TypeInt,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_LISTMAXRESULTS", sdsclient.DefaultListMaxResults),
+				Description: "Maximum number of objects retrieved from a list service, 0 for unlimited (Default : 0)",
+			},
+			"audit_log_file": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_AUDITLOGFILE", nil),
+				Description: "File to which every API call is appended as a JSON line, with secrets redacted",
+			},
+			"audit_redact_parameters": {
+				Type:        schema.TypeString,
+				Required:    false,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("SOLIDServer_AUDITREDACTPARAMETERS", nil),
+				Description: "Comma separated list of additional parameters and class parameters redacted from the audit log",
+			},
+			"auth_mode": {
+				Type:         schema.TypeString,
+				Required:     false,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("SOLIDServer_AUTHMODE", sdsclient.AuthModeHeaders),
+				ValidateFunc: resourceauthmodevalidate,
+				Description:  "Authentication mode: 'headers' sends the credentials on every call, 'session' logs in once and reuses the session (Default : headers)",
+			},
+			"client_cert_file": {
+				Type:          schema.TypeString,
+				Required:      false,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("SOLIDServer_CLIENTCERTFILE", nil),
+				ConflictsWith: []string{"client_cert_pem"},
+				Description:   "PEM formatted file with the client certificate used for mutual TLS authentication",
+			},
+			"client_key_file": {
+				Type:          schema.TypeString,
+				Required:      false,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("SOLIDServer_CLIENTKEYFILE", nil),
+				ConflictsWith: []string{"client_key_pem"},
+				Description:   "PEM formatted file with the private key of the client certificate",
+			},
+			"client_cert_pem": {
+				Type:          schema.TypeString,
+				Required:      false,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("SOLIDServer_CLIENTCERTPEM", nil),
+				ConflictsWith: []string{"client_cert_file"},
+				Description:   "PEM formatted client certificate used for mutual TLS authentication",
+			},
+			"client_key_pem": {
+				Type:          schema.TypeString,
+				Required:      false,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("SOLIDServer_CLIENTKEYPEM", nil),
+				ConflictsWith: []string{"client_key_file"},
+				Description:   "PEM formatted private key of the client certificate",
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
@@ -50,36 +253,159 @@ func Provider() terraform.ResourceProvider {
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"solidserver_ip_space":    resourceipspace(),
-			"solidserver_ip_subnet":   resourceipsubnet(),
-			"solidserver_ip6_subnet":  resourceip6subnet(),
-			"solidserver_ip_address":  resourceipaddress(),
-			"solidserver_ip6_address": resourceip6address(),
-			"solidserver_ip_alias":    resourceipalias(),
-			"solidserver_ip6_alias":   resourceip6alias(),
-			"solidserver_ip_mac":      resourceipmac(),
-			"solidserver_ip6_mac":     resourceip6mac(),
-			"solidserver_device":      resourcedevice(),
-			"solidserver_vlan_domain": resourcevlandomain(),
-			"solidserver_vlan":        resourcevlan(),
-			"solidserver_dns_zone":    resourcednszone(),
-			"solidserver_dns_rr":      resourcednsrr(),
-			"solidserver_user":        resourceuser(),
-			"solidserver_usergroup":   resourceusergroup(),
+			"solidserver_ip_space":         resourceipspace(),
+			"solidserver_ip_subnet":        resourceipsubnet(),
+			"solidserver_ip6_subnet":       resourceip6subnet(),
+			"solidserver_ip_pool":          resourceippool(),
+			"solidserver_ip6_pool":         resourceip6pool(),
+			"solidserver_ip_address":       resourceipaddress(),
+			"solidserver_ip6_address":      resourceip6address(),
+			"solidserver_ip_address_block": resourceipaddressblock(),
+			"solidserver_ip_alias":         resourceipalias(),
+			"solidserver_ip6_alias":        resourceip6alias(),
+			"solidserver_ip_mac":           resourceipmac(),
+			"solidserver_ip6_mac":          resourceip6mac(),
+			"solidserver_device":           resourcedevice(),
+			"solidserver_vlan_domain":      resourcevlandomain(),
+			"solidserver_vlan":             resourcevlan(),
+			"solidserver_dns_zone":         resourcednszone(),
+			"solidserver_dns_rr":           resourcednsrr(),
+			"solidserver_user":             resourceuser(),
+			"solidserver_usergroup":        resourceusergroup(),
 		},
+	}
 
-		ConfigureFunc: ProviderConfigure,
+	// Every API call is abandoned once terraform is interrupted
+	p.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerconfigure(d, p.StopContext())
 	}
+
+	return p
 }
 
 func ProviderConfigure(d *schema.ResourceData) (interface{}, error) {
-	s := NewSOLIDserver(
+	return providerconfigure(d, context.Background())
+}
+
+func providerconfigure(d *schema.ResourceData, stopContext context.Context) (interface{}, error) {
+	if d.Get("host").(string) == "" && d.Get("base_url").(string) == "" {
+		return nil, fmt.Errorf("SOLIDServer - Either a host or a base_url is required")
+	}
+
+	hasClientCert := d.Get("client_cert_file").(string) != "" || d.Get("client_cert_pem").(string) != ""
+
+	credentials, err := providercredentials(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A client certificate can replace the username and password
+	if d.Get("username").(string) == "" && credentials == nil && !hasClientCert {
+		return nil, fmt.Errorf("SOLIDServer - Either a username and password or a client certificate are required")
+	}
+
+	retry, retryErr := sdsclient.NewRetryPolicy(
+		d.Get("timeout").(int),
+		d.Get("max_retries").(int),
+		d.Get("retry_backoff_base").(int),
+		d.Get("retry_backoff_cap").(int),
+		d.Get("retryable_statuses").(string),
+		d.Get("retryable_network_errors").(string),
+	)
+
+	if retryErr != nil {
+		return nil, retryErr
+	}
+
+	var audit *sdsclient.AuditLog
+
+	if auditLogFile := d.Get("audit_log_file").(string); auditLogFile != "" {
+		audit, err = sdsclient.NewAuditLog(auditLogFile, strings.Split(d.Get("audit_redact_parameters").(string), ","))
+
+		if err != nil {
+			return nil, fmt.Errorf("SOLIDServer - Unable to open the audit log %s: %s", auditLogFile, err)
+		}
+	}
+
+	s, err := sdsclient.NewSOLIDserver(
 		d.Get("host").(string),
 		d.Get("username").(string),
 		d.Get("password").(string),
 		d.Get("sslverify").(bool),
 		d.Get("additional_trust_certs_file").(string),
+		sdsclient.SOLIDserverOptions{
+			BaseURL:         d.Get("base_url").(string),
+			Port:            d.Get("port").(int),
+			PathPrefix:      d.Get("path_prefix").(string),
+			ProxyURL:        d.Get("proxy_url").(string),
+			MaxIdleConns:    d.Get("max_idle_connections").(int),
+			MaxConnsPerHost: d.Get("max_connections_per_host").(int),
+			Retry:           retry,
+			ReadOnly:        d.Get("read_only").(bool),
+			ListPageSize:    d.Get("list_page_size").(int),
+			ListMaxResults:  d.Get("list_max_results").(int),
+			AuditLog:        audit,
+			Credentials:     credentials,
+			Limiter:         sdsclient.NewLimiter(d.Get("max_concurrent_requests").(int), d.Get("requests_per_second").(float64)),
+			AuthMode:        d.Get("auth_mode").(string),
+			ClientCertFile:  d.Get("client_cert_file").(string),
+			ClientKeyFile:   d.Get("client_key_file").(string),
+			ClientCertPEM:   d.Get("client_cert_pem").(string),
+			ClientKeyPEM:    d.Get("client_key_pem").(string),
+			StopContext:     stopContext,
+		},
 	)
 
+	if err != nil {
+		// The audit log is only closed by Shutdown once the client is configured
+		audit.Close()
+		return nil, err
+	}
+
 	return s, nil
 }
+
+// Build the credential source replacing the password argument, if any
+func providercredentials(d *schema.ResourceData) (sdsclient.CredentialSource, error) {
+	username := d.Get("username").(string)
+	sources := []string{}
+
+	for _, arg := range []string{"password", "password_file", "credentials_command"} {
+		if d.Get(arg).(string) != "" {
+			sources = append(sources, arg)
+		}
+	}
+
+	if len(sources) > 1 {
+		return nil, fmt.Errorf("SOLIDServer - Only one of password, password_file and credentials_command can be set (got: %s)", strings.Join(sources, ", "))
+	}
+
+	switch {
+	case d.Get("password").(string) != "":
+		return nil, nil
+	case d.Get("password_file").(string) != "":
+		if username == "" {
+			return nil, fmt.Errorf("SOLIDServer - A username is required with password_file")
+		}
+		return sdsclient.NewPasswordFileCredentials(username, d.Get("password_file").(string)), nil
+	case d.Get("credentials_command").(string) != "":
+		return sdsclient.NewCommandCredentials(username, d.Get("credentials_command").(string)), nil
+	case d.Get("netrc").(bool):
+		hosts := sdsclient.SplitHosts(d.Get("host").(string))
+
+		if baseURL := d.Get("base_url").(string); baseURL != "" {
+			_, host, _, err := sdsclient.ParseBaseURL(baseURL)
+
+			if err != nil {
+				return nil, err
+			}
+
+			hosts = []string{host}
+		}
+
+		return sdsclient.NewNetrcCredentials(username, "", hosts), nil
+	}
+
+	return nil, nil
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -13,32 +15,62 @@ import (
 var testProviders map[string]terraform.ResourceProvider
 var testProvider *schema.Provider
 
+// Acceptance tests run against the SOLIDserver given by the environment
+// The other tests run against the in-process fake SOLIDserver (sdsclient/sdsfake)
 func testAccPreCheck(t *testing.T) {
 	log.Printf("[DEBUG] - testPreCheck\n")
-}
 
-func init() {
 	if os.Getenv("SOLIDServer_HOST") == "" {
-		fmt.Println("[ERROR] use SOLIDServer_HOST as SOLIDserver target")
-		return
+		t.Fatal("[ERROR] use SOLIDServer_HOST as SOLIDserver target")
 	}
 
 	if os.Getenv("SOLIDServer_USERNAME") == "" {
-		fmt.Println("[ERROR] use SOLIDServer_USERNAME as SOLIDserver user for API")
-		return
+		t.Fatal("[ERROR] use SOLIDServer_USERNAME as SOLIDserver user for API")
 	}
 
 	if os.Getenv("SOLIDServer_PASSWORD") == "" {
-		fmt.Println("[ERROR] use SOLIDServer_PASSWORD as SOLIDserver password for API")
-		return
+		t.Fatal("[ERROR] use SOLIDServer_PASSWORD as SOLIDserver password for API")
 	}
 
 	if os.Getenv("SOLIDServer_SSLVERIFY") == "" {
 		fmt.Println("[WARN] use SOLIDServer_SSLVERIFY=false to bypass certificate validation")
 	}
+}
 
+func init() {
 	testProvider = Provider().(*schema.Provider)
 	testProviders = map[string]terraform.ResourceProvider{
 		"solidserver": testProvider,
 	}
 }
+
+func TestProvider_Configure(t *testing.T) {
+	f, _ := newFakeSOLIDserver(t)
+	defer f.Close()
+
+	d := schema.TestResourceDataRaw(t, testProvider.Schema, map[string]interface{}{
+		"base_url": f.URL,
+		"username": sdsfake.Username,
+		"password": sdsfake.Password,
+	})
+
+	meta, err := ProviderConfigure(d)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s := meta.(*sdsclient.SOLIDserver); s.Version.String() != sdsfake.Version {
+		t.Errorf("expected version %s, got %s", sdsfake.Version, s.Version)
+	}
+
+	d = schema.TestResourceDataRaw(t, testProvider.Schema, map[string]interface{}{
+		"base_url": f.URL,
+		"username": sdsfake.Username,
+		"password": "wrong",
+	})
+
+	if _, err := ProviderConfigure(d); err == nil {
+		t.Errorf("expected an authentication failure")
+	}
+}
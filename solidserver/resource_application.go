@@ -1,24 +1,25 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 )
 
 func resourceapplication() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceapplicationCreate,
-		Read:   resourceapplicationRead,
-		Update: resourceapplicationUpdate,
-		Delete: resourceapplicationDelete,
-		Exists: resourceapplicationExists,
+		Create:        resourceapplicationCreate,
+		Read:          resourceapplicationRead,
+		Update:        resourceapplicationUpdate,
+		Delete:        resourceapplicationDelete,
+		Exists:        resourceapplicationExists,
+		CustomizeDiff: resourcerequires(sdsclient.CapabilityApplications, nil),
 		Importer: &schema.ResourceImporter{
 			State: resourceapplicationImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -44,284 +45,103 @@ func resourceapplication() *schema.Resource {
 	}
 }
 
-func resourceapplicationExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("appapplication_id", d.Id())
-
-	if s.Version < 710 {
-		// Reporting a failure
-		return false, fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
+func resourceapplicationspec(d *schema.ResourceData) sdsclient.ApplicationSpec {
+	return sdsclient.ApplicationSpec{
+		Name:            d.Get("name").(string),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
 	}
+}
 
-	log.Printf("[DEBUG] Checking existence of application (oid): %s\n", d.Id())
-
-	// Sending read request
-	resp, body, err := s.Request("get", "rest/app_application_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
+func resourceapplicationExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to find application (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find application (oid): %s\n", d.Id())
-		}
+	log.Printf("[DEBUG] Checking existence of application (oid): %s\n", d.Id())
 
-		// Unset local ID
-		d.SetId("")
-	}
+	_, err := s.GetApplication(ctx, d.Id())
 
-	// Reporting a failure
-	return false, err
+	return resourceexists(d, err)
 }
 
 func resourceapplicationCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("add_flag", "new_only")
-	parameters.Add("appapplication_name", d.Get("name").(string))
-	parameters.Add("appapplication_class_name", d.Get("class").(string))
-	parameters.Add("appapplication_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
+	application, err := s.CreateApplication(ctx, resourceapplicationspec(d))
 
-	if s.Version < 710 {
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
+		return err
 	}
 
-	// Sending creation request
-	resp, body, err := s.Request("post", "rest/app_application_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	d.SetId(application.ID)
 
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created application (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
-
-		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to create application: %s\n", d.Get("name").(string))
-	}
-
-	// Reporting a failure
-	return err
+	return nil
 }
 
 func resourceapplicationUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("appapplication_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("appapplication_name", d.Get("name").(string))
-	parameters.Add("appapplication_class_name", d.Get("class").(string))
-	parameters.Add("appapplication_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
+	application, err := s.UpdateApplication(ctx, d.Id(), resourceapplicationspec(d))
 
-	if s.Version < 710 {
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
+		return err
 	}
 
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/app_application_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated application (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
-
-		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update application: %s\n", d.Get("name").(string))
-	}
+	d.SetId(application.ID)
 
-	// Reporting a failure
-	return err
+	return nil
 }
 
 func resourceapplicationDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("appapplication_id", d.Id())
-
-	if s.Version < 710 {
+	if err := s.DeleteApplication(ctx, d.Id()); err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
+		return err
 	}
 
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/app_application_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Reporting a failure
-				return fmt.Errorf("SOLIDServer - Unable to delete application : %s (%s)\n", d.Get("name"), errMsg)
-			}
-		}
-
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted application (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
+	// Unset local ID
+	d.SetId("")
 
-		// Reporting a success
-		return nil
-	}
-
-	// Reporting a failure
-	return err
+	// Reporting a success
+	return nil
 }
 
 func resourceapplicationRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("appapplication_id", d.Id())
-
-	if s.Version < 710 {
-		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
-	}
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/app_application_info", &parameters)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("name", buf[0]["appapplication_name"].(string))
-			d.Set("class", buf[0]["appapplication_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["appapplication_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find application: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find application (oid): %s\n", d.Id())
-		}
+	application, err := s.GetApplication(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find application: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.Set("name", application.Name)
+	d.Set("class", application.Class)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), application.ClassParameters))
+
+	return nil
 }
 
 func resourceapplicationImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("appapplication_id", d.Id())
-
-	if s.Version < 710 {
-		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Object not supported in this SOLIDserver version")
-	}
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/app_application_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("name", buf[0]["appapplication_name"].(string))
-			d.Set("class", buf[0]["appapplication_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["appapplication_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return []*schema.ResourceData{d}, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to import application(oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import application (oid): %s\n", d.Id())
-		}
-
+	if err := resourceapplicationRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import application (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
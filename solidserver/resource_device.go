@@ -1,13 +1,11 @@
 package solidserver
 
 import (
-	"encoding/json"
 	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 	"regexp"
-	"strings"
 )
 
 func resourcedevice() *schema.Resource {
@@ -21,6 +19,8 @@ func resourcedevice() *schema.Resource {
 			State: resourcedeviceImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:             schema.TypeString,
@@ -58,253 +58,102 @@ func resourcedevicenamevalidateformat(v interface{}, _ string) ([]string, []erro
 }
 
 func resourcedeviceExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("hostdev_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of device (oid): %s\n", d.Id())
 
-	// Sending read request
-	resp, body, err := s.Request("get", "rest/hostdev_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	_, err := s.GetDevice(ctx, d.Id())
 
-		// Checking answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to find device (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find device (oid): %s\n", d.Id())
-		}
+	return resourceexists(d, err)
+}
 
-		// Unset local ID
-		d.SetId("")
+func resourcedevicespec(d *schema.ResourceData) sdsclient.DeviceSpec {
+	return sdsclient.DeviceSpec{
+		Name:            d.Get("name").(string),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
 	}
-
-	// Reporting a failure
-	return false, err
 }
 
 func resourcedeviceCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("add_flag", "new_only")
-	parameters.Add("hostdev_name", strings.ToLower(d.Get("name").(string)))
-	parameters.Add("hostdev_class_name", d.Get("class").(string))
-	parameters.Add("hostdev_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
-
-	// Sending creation request
-	resp, body, err := s.Request("post", "rest/hostdev_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created device (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
+	device, err := s.CreateDevice(ctx, resourcedevicespec(d))
+
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to create device: %s\n", strings.ToLower(d.Get("name").(string)))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(device.ID)
+
+	return nil
 }
 
 func resourcedeviceUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("hostdev_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("hostdev_name", strings.ToLower(d.Get("name").(string)))
-	parameters.Add("hostdev_class_name", d.Get("class").(string))
-	parameters.Add("hostdev_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
-
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/hostdev_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated device (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+
+	device, err := s.UpdateDevice(ctx, d.Id(), resourcedevicespec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update device: %s\n", strings.ToLower(d.Get("name").(string)))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(device.ID)
+
+	return nil
 }
 
 func resourcedeviceDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("hostdev_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/hostdev_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Reporting a failure
-				return fmt.Errorf("SOLIDServer - Unable to delete device : %s (%s)", strings.ToLower(d.Get("name").(string)), errMsg)
-			}
-		}
-
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted device (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+	if err := s.DeleteDevice(ctx, d.Id()); err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourcedeviceRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("hostdev_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/hostdev_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("name", strings.ToLower(buf[0]["hostdev_name"].(string)))
-			d.Set("class", buf[0]["hostdev_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["hostdev_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find device: %s (%s)\n", strings.ToLower(d.Get("name").(string)), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find device (oid): %s\n", d.Id())
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	device, err := s.GetDevice(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find device: %s\n", strings.ToLower(d.Get("name").(string)))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.Set("name", device.Name)
+	d.Set("class", device.Class)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), device.ClassParameters))
+
+	return nil
 }
 
 func resourcedeviceImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("hostdev_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/hostdev_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("name", strings.ToLower(buf[0]["hostdev_name"].(string)))
-			d.Set("class", buf[0]["hostdev_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["hostdev_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return []*schema.ResourceData{d}, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to import device(oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import device (oid): %s\n", d.Id())
-		}
-
+	if err := resourcedeviceRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import device (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
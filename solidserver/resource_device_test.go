@@ -0,0 +1,50 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestDevice_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcedevice()
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"name":             "SRV01",
+		"class_parameters": map[string]interface{}{"rack": "a1"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "srv01",
+		"class_parameters": map[string]interface{}{"rack": "a1"},
+	})
+
+	// Device names are unique
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": "srv01"})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate device, got: %v", err)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"name":             "srv01",
+		"class_parameters": map[string]interface{}{"rack": "b2"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"class_parameters": map[string]interface{}{"rack": "b2"},
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{"name": "srv01"})
+
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("hostdev", "hostdev_name", "srv01"); len(rows) != 0 {
+		t.Errorf("expected the device to be deleted, got %v", rows)
+	}
+}
@@ -1,12 +1,10 @@
 package solidserver
 
 import (
-	"encoding/json"
 	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
-	"strconv"
 	"strings"
 )
 
@@ -21,6 +19,8 @@ func resourcednsrr() *schema.Resource {
 			State: resourcednsrrImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"dnsserver": {
 				Type:        schema.TypeString,
@@ -86,260 +86,111 @@ func resourcednsrrvalidatetype(v interface{}, _ string) ([]string, []error) {
 }
 
 func resourcednsrrExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("rr_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of RR (oid): %s\n", d.Id())
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_rr_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	_, err := s.GetRR(ctx, d.Id())
 
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find RR (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find RR (oid): %s\n", d.Id())
-		}
+	return resourceexists(d, err)
+}
 
-		// Unset local ID
-		d.SetId("")
+func resourcednsrrspec(d *schema.ResourceData) sdsclient.RRSpec {
+	return sdsclient.RRSpec{
+		Server: d.Get("dnsserver").(string),
+		View:   d.Get("dnsview_name").(string),
+		Name:   d.Get("name").(string),
+		Type:   d.Get("type").(string),
+		Value:  d.Get("value").(string),
+		TTL:    d.Get("ttl").(int),
 	}
-
-	// Reporting a failure
-	return false, err
 }
 
 func resourcednsrrCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("add_flag", "new_only")
-	parameters.Add("dns_name", d.Get("dnsserver").(string))
-	parameters.Add("rr_name", d.Get("name").(string))
-	parameters.Add("rr_type", strings.ToUpper(d.Get("type").(string)))
-	parameters.Add("value1", d.Get("value").(string))
-	parameters.Add("rr_ttl", strconv.Itoa(d.Get("ttl").(int)))
-
-	// Add dnsview_name parameter if it is supplied
-	if len(d.Get("dnsview_name").(string)) != 0 {
-		parameters.Add("dnsview_name", d.Get("dnsview_name").(string))
-	}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	// Sending the creation request
-	resp, body, err := s.Request("post", "rest/dns_rr_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created RR (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	rr, err := s.CreateRR(ctx, resourcednsrrspec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to create RR: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(rr.ID)
+
+	return nil
 }
 
 func resourcednsrrUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("rr_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("dns_name", d.Get("dnsserver").(string))
-	parameters.Add("rr_name", d.Get("name").(string))
-	parameters.Add("rr_type", strings.ToUpper(d.Get("type").(string)))
-	parameters.Add("value1", d.Get("value").(string))
-	parameters.Add("rr_ttl", strconv.Itoa(d.Get("ttl").(int)))
-
-	// Add dnsview_name parameter if it is supplied
-	if len(d.Get("dnsview_name").(string)) != 0 {
-		parameters.Add("dnsview_name", d.Get("dnsview_name").(string))
-	}
-
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/dns_rr_add", &parameters)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated RR (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	rr, err := s.UpdateRR(ctx, d.Id(), resourcednsrrspec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update RR: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(rr.ID)
+
+	return nil
 }
 
 func resourcednsrrDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("rr_id", d.Id())
-
-	// Add dnsview_name parameter if it is supplied
-	if len(d.Get("dnsview_name").(string)) != 0 {
-		parameters.Add("dnsview_name", d.Get("dnsview_name").(string))
-	}
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/dns_rr_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete RR: %s (%s)\n", d.Get("name"), errMsg)
-			}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	if err := s.DeleteRR(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted RR (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete RR: %s (%s)\n", d.Get("name"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourcednsrrRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("rr_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_rr_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			ttl, _ := strconv.Atoi(buf[0]["ttl"].(string))
-
-			d.Set("dnsserver", buf[0]["dns_name"].(string))
-			d.Set("name", buf[0]["rr_full_name"].(string))
-			d.Set("type", buf[0]["rr_type"].(string))
-			d.Set("value", buf[0]["value1"].(string))
-			d.Set("ttl", ttl)
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find RR: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find RR (oid): %s\n", d.Id())
-		}
+	rr, err := s.GetRR(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find RR: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.Set("dnsserver", rr.Server)
+	d.Set("name", rr.Name)
+	d.Set("type", rr.Type)
+	d.Set("value", rr.Value)
+	d.Set("ttl", rr.TTL)
+
+	return nil
 }
 
 func resourcednsrrImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("rr_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_rr_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			ttl, _ := strconv.Atoi(buf[0]["ttl"].(string))
-
-			d.Set("dnsserver", buf[0]["dns_name"].(string))
-			d.Set("name", buf[0]["rr_full_name"].(string))
-			d.Set("type", buf[0]["rr_type"].(string))
-			d.Set("value", buf[0]["value1"].(string))
-			d.Set("ttl", ttl)
-
-			// Add dnsview_name parameter if it is supplied
-			if len(d.Get("dnsview_name").(string)) != 0 {
-				d.Set("dnsview_name", buf[0]["dnsview_name"].(string))
-			}
-
-			return []*schema.ResourceData{d}, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to import RR (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import RR (oid): %s\n", d.Id())
-		}
-
+	if err := resourcednsrrRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import RR (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,78 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestDNSRR_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcednsrr()
+
+	testDNSZone(t, s, "ns1.example.com", "example.com")
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "www.example.com",
+		"type":      "A",
+		"value":     "10.0.0.1",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "www.example.com",
+		"type":      "A",
+		"value":     "10.0.0.1",
+		"ttl":       3600,
+	})
+
+	if rows := f.Rows("dns_rr", "rr_id", d.Id()); len(rows) != 1 || rows[0]["dnszone_name"] != "example.com" {
+		t.Errorf("expected the RR to be added to its zone, got %v", rows)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "www.example.com",
+		"type":      "A",
+		"value":     "10.0.0.1",
+		"ttl":       300,
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"ttl": 300})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"name":  "www.example.com",
+		"type":  "A",
+		"value": "10.0.0.1",
+		"ttl":   300,
+	})
+
+	testResourceDelete(t, s, r, d)
+}
+
+func TestDNSRR_Invalid(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcednsrr()
+
+	testDNSZone(t, s, "ns1.example.com", "example.com")
+
+	cases := []map[string]interface{}{
+		// No zone serves the name
+		{"dnsserver": "ns1.example.com", "name": "www.example.org", "type": "A", "value": "10.0.0.1"},
+		// The zone is on another server
+		{"dnsserver": "ns2.example.com", "name": "www.example.com", "type": "A", "value": "10.0.0.1"},
+		// Not an IPv6 address
+		{"dnsserver": "ns1.example.com", "name": "www.example.com", "type": "AAAA", "value": "10.0.0.1"},
+	}
+
+	for _, raw := range cases {
+		if err := r.Create(schema.TestResourceDataRaw(t, r.Schema, raw), s); err == nil {
+			t.Errorf("expected an error creating %v", raw)
+		}
+	}
+}
@@ -1,11 +1,10 @@
 package solidserver
 
 import (
-	"encoding/json"
 	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 	"strings"
 )
 
@@ -20,6 +19,8 @@ func resourcednszone() *schema.Resource {
 			State: resourcednszoneImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"dnsserver": {
 				Type:        schema.TypeString,
@@ -48,12 +49,13 @@ func resourcednszone() *schema.Resource {
 				Default:     "",
 			},
 			"type": {
-				Type:         schema.TypeString,
-				Description:  "The type of the zone to create (Supported: Master).",
-				ValidateFunc: resourcednszonevalidatetype,
-				Optional:     true,
-				ForceNew:     true,
-				Default:      "Master",
+				Type:             schema.TypeString,
+				Description:      "The type of the zone to create (Supported: Master).",
+				ValidateFunc:     resourcednszonevalidatetype,
+				DiffSuppressFunc: resourcediffsuppresslowercase,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "Master",
 			},
 			"createptr": {
 				Type:        schema.TypeBool,
@@ -90,314 +92,119 @@ func resourcednszonevalidatetype(v interface{}, _ string) ([]string, []error) {
 }
 
 func resourcednszoneExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("dnszone_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of DNS zone (oid): %s\n", d.Id())
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_zone_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
+	_, err := s.GetZone(ctx, d.Id())
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to find DNS zone (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find DNS zone (oid): %s\n", d.Id())
-		}
+	return resourceexists(d, err)
+}
 
-		// Unset local ID
-		d.SetId("")
+func resourcednszonespec(d *schema.ResourceData) sdsclient.ZoneSpec {
+	return sdsclient.ZoneSpec{
+		Server:          d.Get("dnsserver").(string),
+		View:            d.Get("view").(string),
+		Name:            d.Get("name").(string),
+		Type:            d.Get("type").(string),
+		Space:           d.Get("space").(string),
+		CreatePTR:       d.Get("createptr").(bool),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
 	}
-
-	// Reporting a failure
-	return false, err
 }
 
 func resourcednszoneCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	// Gather required ID(s) from provided information
-	siteID, siteErr := ipsiteidbyname(d.Get("space").(string), meta)
-	if siteErr != nil {
-		// Reporting a failure
-		return siteErr
-	}
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("add_flag", "new_only")
-	parameters.Add("dns_name", d.Get("dnsserver").(string))
-	if strings.Compare(d.Get("view").(string), "#") != 0 {
-		parameters.Add("dnsview_name", d.Get("view").(string))
-	}
-	parameters.Add("dnszone_name", d.Get("name").(string))
-	parameters.Add("dnszone_type", strings.ToLower(d.Get("type").(string)))
-	parameters.Add("dnszone_site_id", siteID)
-	parameters.Add("dnszone_class_name", d.Get("class").(string))
-
-	// Building class_parameters
-	classParameters := urlfromclassparams(d.Get("class_parameters"))
-	// Generate class parameter for createptr if required
-	if d.Get("createptr").(bool) {
-		classParameters.Add("dnsptr", "1")
-	} else {
-		classParameters.Add("dnsptr", "0")
-	}
-	parameters.Add("dnszone_class_parameters", classParameters.Encode())
-
-	// Sending the creation request
-	resp, body, err := s.Request("post", "rest/dns_zone_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created DNS zone (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	zone, err := s.CreateZone(ctx, resourcednszonespec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to create DNS zone: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(zone.ID)
+
+	return nil
 }
 
 func resourcednszoneUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	// Gather required ID(s) from provided information
-	siteID, siteErr := ipsiteidbyname(d.Get("space").(string), meta)
-	if siteErr != nil {
-		// Reporting a failure
-		return siteErr
-	}
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("dnszone_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("dnszone_site_id", siteID)
-	parameters.Add("dnszone_class_name", d.Get("class").(string))
-
-	// Building class_parameters
-	classParameters := urlfromclassparams(d.Get("class_parameters"))
-	// Generate class parameter for createptr if required
-	if d.Get("createptr").(bool) {
-		classParameters.Add("dnsptr", "1")
-	} else {
-		classParameters.Add("dnsptr", "0")
-	}
-	parameters.Add("dnszone_class_parameters", classParameters.Encode())
-
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/dns_zone_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated DNS Zone (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	zone, err := s.UpdateZone(ctx, d.Id(), resourcednszonespec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update Zone: %s", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(zone.ID)
+
+	return nil
 }
 
 func resourcednszoneDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("dnszone_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/dns_zone_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete Zone: %s (%s)\n", d.Get("name"), errMsg)
-			}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	if err := s.DeleteZone(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted Zone (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete Zone: %s (%s)\n", d.Get("name"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourcednszoneRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("dnszone_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_zone_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("dnsserver", buf[0]["dns_name"].(string))
-			d.Set("view", buf[0]["dnsview_name"].(string))
-			d.Set("name", buf[0]["dnszone_name"].(string))
-			d.Set("type", buf[0]["dnszone_type"].(string))
-
-			if buf[0]["dnszone_site_name"].(string) != "#" {
-				d.Set("space", buf[0]["dnszone_site_name"].(string))
-			} else {
-				d.Set("space", "")
-			}
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["dnszone_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			if createptr, createptrExist := retrievedClassParameters["dnsptr"]; createptrExist {
-				if createptr[0] == "1" {
-					d.Set("createptr", true)
-				} else {
-					d.Set("createptr", false)
-				}
-			}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find DNS zone: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find DNS zone (oid): %s\n", d.Id())
-		}
+	zone, err := s.GetZone(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find DNS zone: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
-}
+	d.Set("dnsserver", zone.Server)
+	d.Set("view", zone.View)
+	d.Set("name", zone.Name)
+	d.Set("type", zone.Type)
+	d.Set("space", zone.Space)
 
-func resourcednszoneImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("dnszone_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/dns_zone_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("dnsserver", buf[0]["dns_name"].(string))
-			d.Set("view", buf[0]["dnsview_name"].(string))
-			d.Set("name", buf[0]["dnszone_name"].(string))
-			d.Set("type", buf[0]["dnszone_type"].(string))
-
-			if buf[0]["dnszone_site_name"].(string) != "#" {
-				d.Set("space", buf[0]["dnszone_site_name"].(string))
-			} else {
-				d.Set("space", "")
-			}
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["dnszone_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return []*schema.ResourceData{d}, nil
-		}
+	if _, createptrExist := zone.ClassParameters["dnsptr"]; createptrExist {
+		d.Set("createptr", zone.CreatePTR)
+	}
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to import DNS zone (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import DNS zone (oid): %s\n", d.Id())
-		}
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), zone.ClassParameters))
+
+	return nil
+}
 
+func resourcednszoneImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourcednszoneRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import DNS zone (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,76 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a zone on the fake SOLIDserver
+func testDNSZone(t *testing.T, s *sdsclient.SOLIDserver, dnsserver string, name string) *schema.ResourceData {
+	return testResourceCreate(t, s, resourcednszone(), map[string]interface{}{
+		"dnsserver": dnsserver,
+		"name":      name,
+	})
+}
+
+func TestDNSZone_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcednszone()
+
+	testIPSpace(t, s, "office")
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"dnsserver":        "ns1.example.com",
+		"name":             "example.com",
+		"space":            "office",
+		"createptr":        true,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"dnsserver":        "ns1.example.com",
+		"view":             "#",
+		"name":             "example.com",
+		"type":             "master",
+		"space":            "office",
+		"createptr":        true,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	// Zones are unique on a DNS server
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "example.com",
+	})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate zone, got: %v", err)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "example.com",
+		"createptr": false,
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"space":     "",
+		"createptr": false,
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"dnsserver": "ns1.example.com",
+		"name":      "example.com",
+	})
+
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("dns_zone", "dnszone_name", "example.com"); len(rows) != 0 {
+		t.Errorf("expected the zone to be deleted, got %v", rows)
+	}
+}
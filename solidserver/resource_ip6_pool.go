@@ -0,0 +1,221 @@
+package solidserver
+
+import (
+	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+	"log"
+)
+
+func resourceip6pool() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceip6poolCreate,
+		Read:   resourceip6poolRead,
+		Update: resourceip6poolUpdate,
+		Delete: resourceip6poolDelete,
+		Exists: resourceip6poolExists,
+		Importer: &schema.ResourceImporter{
+			State: resourceip6poolImportState,
+		},
+
+		Timeouts: resourcetimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"space": {
+				Type:        schema.TypeString,
+				Description: "The name of the space into which creating the IP v6 pool.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"subnet": {
+				Type:        schema.TypeString,
+				Description: "The name of the subnet into which creating the IP v6 pool.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"start": {
+				Type:         schema.TypeString,
+				Description:  "The first IP v6 address of the IP v6 pool.",
+				ValidateFunc: resourceip6addressrequestvalidateformat,
+				Required:     true,
+				ForceNew:     true,
+			},
+			"end": {
+				Type:          schema.TypeString,
+				Description:   "The last IP v6 address of the IP v6 pool, computed from its size if not set.",
+				ValidateFunc:  resourceip6addressrequestvalidateformat,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"size"},
+			},
+			"size": {
+				Type:          schema.TypeInt,
+				Description:   "The number of IP v6 addresses of the IP v6 pool, computed from its end if not set.",
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"end"},
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the IP v6 pool to create.",
+				Required:    true,
+				ForceNew:    false,
+			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Description: "The read-only property of the IP v6 pool, its addresses can't be allocated when set.",
+				Optional:    true,
+				ForceNew:    false,
+				Default:     false,
+			},
+			"class": {
+				Type:        schema.TypeString,
+				Description: "The class associated to the IP v6 pool.",
+				Optional:    true,
+				ForceNew:    false,
+				Default:     "",
+			},
+			"class_parameters": {
+				Type:        schema.TypeMap,
+				Description: "The class parameters associated to the IP v6 pool.",
+				Optional:    true,
+				ForceNew:    false,
+				Default:     map[string]string{},
+			},
+		},
+	}
+}
+
+func resourceip6poolspec(d *schema.ResourceData) sdsclient.PoolSpec {
+	return sdsclient.PoolSpec{
+		Space:           d.Get("space").(string),
+		Subnet:          d.Get("subnet").(string),
+		Name:            d.Get("name").(string),
+		Start:           d.Get("start").(string),
+		End:             d.Get("end").(string),
+		Size:            d.Get("size").(int),
+		ReadOnly:        d.Get("read_only").(bool),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
+	}
+}
+
+func resourceip6poolExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	log.Printf("[DEBUG] Checking existence of IP v6 pool (oid): %s\n", d.Id())
+
+	_, err := s.GetPool6(ctx, d.Id())
+
+	return resourceexists(d, err)
+}
+
+func resourceip6poolCreate(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	pool, err := s.CreatePool6(ctx, resourceip6poolspec(d))
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	d.SetId(pool.ID)
+	d.Set("end", pool.End)
+
+	// The size of the largest pools is only known from their end
+	if pool.Size > 0 {
+		d.Set("size", pool.Size)
+	}
+
+	return nil
+}
+
+func resourceip6poolUpdate(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+
+	pool, err := s.UpdatePool6(ctx, d.Id(), resourceip6poolspec(d))
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	d.SetId(pool.ID)
+
+	return nil
+}
+
+func resourceip6poolDelete(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	// A pool already deleted is not reported, any other failure keeps it in the state
+	if err := s.DeletePool6(ctx, d.Id()); err != nil && !sdsclient.IsNotFound(err) {
+		// Reporting a failure
+		return err
+	}
+
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
+}
+
+func resourceip6poolRead(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	pool, err := s.GetPool6(ctx, d.Id())
+
+	if err != nil {
+		// Only unset the local ID once the pool is known to be gone
+		_, err = resourceexists(d, err)
+
+		// Reporting a failure
+		return err
+	}
+
+	d.Set("space", pool.Space)
+	d.Set("subnet", pool.Subnet)
+	d.Set("name", pool.Name)
+	d.Set("start", pool.Start)
+	d.Set("end", pool.End)
+
+	// The size of the largest pools is only known from their end
+	if pool.Size > 0 {
+		d.Set("size", pool.Size)
+	}
+	d.Set("read_only", pool.ReadOnly)
+	d.Set("class", pool.Class)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), pool.ClassParameters))
+
+	return nil
+}
+
+func resourceip6poolImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	id := d.Id()
+
+	if err := resourceip6poolRead(d, meta); err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	if d.Id() == "" {
+		// Reporting a failure
+		return nil, fmt.Errorf("SOLIDServer - Unable to find IP v6 pool (oid): %s", id)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,190 @@
+package solidserver
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a space, an IP v6 block and a /64 terminal subnet (2001:db8::/64) on the fake SOLIDserver
+func testIP6AddressSubnet(t *testing.T, s *sdsclient.SOLIDserver) {
+	testIPSpace(t, s, "office")
+	testIP6Block(t, s, "office", "lan", "2001:0db8:0000:0000:0000:0000:0000:0000", 48)
+	testIP6Subnet(t, s, "office", "lan", "servers", 64)
+}
+
+func TestIP6Pool_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6pool()
+
+	testIP6AddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"name":             "dhcp",
+		"start":            "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"size":             16,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"start":            "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"end":              "2001:0db8:0000:0000:0000:0000:0000:001f",
+		"size":             16,
+		"read_only":        false,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":     "office",
+		"subnet":    "servers",
+		"name":      "dhcp-ro",
+		"start":     "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"size":      16,
+		"read_only": true,
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":      "dhcp-ro",
+		"end":       "2001:0db8:0000:0000:0000:0000:0000:001f",
+		"read_only": true,
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space":     "office",
+		"subnet":    "servers",
+		"name":      "dhcp-ro",
+		"start":     "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"end":       "2001:0db8:0000:0000:0000:0000:0000:001f",
+		"size":      16,
+		"read_only": true,
+	})
+
+	testResourceDelete(t, s, r, d)
+
+	if n := len(f.Rows("ip6_pool6", "subnet6_name", "servers")); n != 0 {
+		t.Errorf("expected the pool to be deleted, got %d", n)
+	}
+}
+
+func TestIP6Pool_End(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6pool()
+
+	testIP6AddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "vips",
+		"start":  "2001:0db8:0000:0000:0000:0000:0000:0002",
+		"end":    "2001:0db8:0000:0000:0000:0000:0000:0004",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"start": "2001:0db8:0000:0000:0000:0000:0000:0002",
+		"end":   "2001:0db8:0000:0000:0000:0000:0000:0004",
+		"size":  3,
+	})
+
+	// The size of a pool too large for an int is left unknown
+	d = testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "slaac",
+		"start":  "2001:0db8:0000:0000:8000:0000:0000:0000",
+		"end":    "2001:0db8:0000:0000:ffff:ffff:ffff:ffff",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"end":  "2001:0db8:0000:0000:ffff:ffff:ffff:ffff",
+		"size": 0,
+	})
+
+	// A pool needs an end or a size after its start
+	for _, raw := range []map[string]interface{}{
+		{"space": "office", "subnet": "servers", "name": "empty", "start": "2001:0db8:0000:0000:0000:0000:0000:0005"},
+		{"space": "office", "subnet": "servers", "name": "reversed", "start": "2001:0db8:0000:0000:0000:0000:0000:0006", "end": "2001:0db8:0000:0000:0000:0000:0000:0005"},
+	} {
+		if err := r.Create(schema.TestResourceDataRaw(t, r.Schema, raw), s); err == nil {
+			t.Errorf("expected an error creating the pool %s", raw["name"])
+		}
+	}
+}
+
+func TestIP6Pool_Gone(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6pool()
+
+	testIP6AddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "dhcp",
+		"start":  "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"size":   16,
+	})
+
+	id := d.Id()
+	f.Remove("ip6_pool6", "pool6_id", id)
+
+	// A pool deleted behind the provider's back is dropped from the state
+	if err := r.Read(d, s); err != nil || d.Id() != "" {
+		t.Errorf("expected the pool to be dropped from the state, got oid: %q, error: %v", d.Id(), err)
+	}
+
+	imported := r.TestResourceData()
+	imported.SetId(id)
+
+	if _, err := r.Importer.State(imported, s); err == nil {
+		t.Errorf("expected an error importing a deleted pool")
+	}
+
+	// Deleting it again is not a failure
+	d.SetId(id)
+
+	if err := r.Delete(d, s); err != nil || d.Id() != "" {
+		t.Errorf("expected the deleted pool to be dropped from the state, got oid: %q, error: %v", d.Id(), err)
+	}
+}
+
+func TestIP6Pool_DeleteError(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6pool()
+
+	testIP6AddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "dhcp",
+		"start":  "2001:0db8:0000:0000:0000:0000:0000:0010",
+		"size":   16,
+	})
+
+	deletepool := f.Handle("rest/ip6_pool6_delete", func(method string, p url.Values) sdsfake.Answer {
+		return sdsfake.Error(http.StatusForbidden, 2020, "Access denied to IP v6 pool (oid: %s)", p.Get("pool6_id"))
+	})
+
+	// A pool the SOLIDserver refuses to delete is kept in the state
+	if err := r.Delete(d, s); err == nil || d.Id() == "" {
+		t.Errorf("expected an error keeping the pool in the state, got oid: %q, error: %v", d.Id(), err)
+	}
+
+	f.Handle("rest/ip6_pool6_delete", deletepool)
+	testResourceDelete(t, s, r, d)
+}
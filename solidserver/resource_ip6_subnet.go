@@ -1,15 +1,11 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"math/big"
-	"math/rand"
-	"net/url"
 	"strconv"
-	"time"
 )
 
 func resourceip6subnet() *schema.Resource {
@@ -23,6 +19,9 @@ func resourceip6subnet() *schema.Resource {
 			State: resourceip6subnetImportState,
 		},
 
+		CustomizeDiff: resourcereservedrangesDiff(128, false),
+		Timeouts:      resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"space": {
 				Type:        schema.TypeString,
@@ -68,6 +67,7 @@ func resourceip6subnet() *schema.Resource {
 				Computed:    true,
 				ForceNew:    true,
 			},
+			"reserved_ranges": reservedrangesschema(),
 			"name": {
 				Type:        schema.TypeString,
 				Description: "The name of the IP subnet to create.",
@@ -100,427 +100,188 @@ func resourceip6subnet() *schema.Resource {
 }
 
 func resourceip6subnetExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet6_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of IP v6 subnet (oid): %s\n", d.Id())
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip6_block6_subnet6_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
+	_, err := s.GetSubnet6(ctx, d.Id())
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 subnet (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 subnet (oid): %s\n", d.Id())
-		}
+	return resourceexists(d, err)
+}
 
-		// Unset local ID
-		d.SetId("")
+func resourceip6subnetspec(d *schema.ResourceData) sdsclient.SubnetSpec {
+	return sdsclient.SubnetSpec{
+		Space:           d.Get("space").(string),
+		Block:           d.Get("block").(string),
+		RequestIP:       d.Get("request_ip").(string),
+		PrefixLength:    d.Get("size").(int),
+		Name:            d.Get("name").(string),
+		Terminal:        d.Get("terminal").(bool),
+		GatewayOffset:   d.Get("gateway_offset").(int),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
 	}
-
-	return false, err
 }
 
 func resourceip6subnetCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	var blockID string = ""
-	var gateway string = ""
+	subnet, err := s.CreateSubnet6(ctx, resourceip6subnetspec(d))
 
-	// Gather required ID(s) from provided information
-	siteID, siteErr := ipsiteidbyname(d.Get("space").(string), meta)
-	if siteErr != nil {
+	if err != nil {
 		// Reporting a failure
-		return siteErr
-	}
-
-	// If a block is specified, look for free IP subnet within this block
-	if len(d.Get("block").(string)) > 0 {
-		var blockErr error = nil
-
-		blockID, blockErr = ip6subnetidbyname(siteID, d.Get("block").(string), false, meta)
-
-		if blockErr != nil {
-			// Reporting a failure
-			return blockErr
-		}
-	} else {
-		// However, we can't create a block as a terminal subnet
-		if d.Get("terminal").(bool) {
-			return fmt.Errorf("SOLIDServer - Can't create a terminal IP v6 block subnet: %s", d.Get("name").(string))
-		}
+		return err
 	}
 
-	subnetAddresses, subnetErr := ip6subnetfindbysize(siteID, blockID, d.Get("request_ip").(string), d.Get("size").(int), meta)
+	d.SetId(subnet.ID)
+	d.Set("prefix", subnet.Address+"/"+strconv.Itoa(subnet.PrefixLength))
 
-	if subnetErr != nil {
-		// Reporting a failure
-		return subnetErr
+	if subnet.Gateway != "" {
+		d.Set("gateway", subnet.Gateway)
 	}
 
-	for i := 0; i < len(subnetAddresses); i++ {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("site_id", siteID)
-		parameters.Add("subnet6_name", d.Get("name").(string))
-		parameters.Add("subnet6_addr", hexip6toip6(subnetAddresses[i]))
-		parameters.Add("subnet6_prefix", strconv.Itoa(d.Get("size").(int)))
-		parameters.Add("subnet6_class_name", d.Get("class").(string))
-
-		// New only
-		parameters.Add("add_flag", "new_only")
-
-		// If no block specified, create an IP block
-		if len(d.Get("block").(string)) == 0 {
-			parameters.Add("subnet_level", "0")
-		}
-
-		// Specify if subnet is terminal
-		if d.Get("terminal").(bool) {
-			parameters.Add("is_terminal", "1")
-		} else {
-			parameters.Add("is_terminal", "0")
-		}
-
-		// Building class_parameters
-		classParameters := url.Values{}
-
-		// Generate class parameter for the gateway if required
-		goffset := d.Get("gateway_offset").(int)
-
-		if goffset != 0 {
-			bigStartAddr, _ := new(big.Int).SetString(subnetAddresses[i], 16)
-
-			if goffset > 0 {
-				bigOffset := big.NewInt(int64(goffset))
-				gateway = hexip6toip6(BigIntToHexStr(bigStartAddr.Add(bigStartAddr, bigOffset)))
-			} else {
-				bigEndAddr := bigStartAddr.Add(bigStartAddr, prefix6lengthtosize(int64(d.Get("size").(int))))
-				bigOffset := big.NewInt(int64(abs(goffset)))
-				gateway = hexip6toip6(BigIntToHexStr(bigEndAddr.Sub(bigEndAddr, bigOffset)))
-			}
-
-			classParameters.Add("gateway", gateway)
-			log.Printf("[DEBUG] SOLIDServer - Subnet computed gateway: %s\n", gateway)
-		}
-
-		for k, v := range d.Get("class_parameters").(map[string]interface{}) {
-			classParameters.Add(k, v.(string))
-		}
-		parameters.Add("subnet6_class_parameters", classParameters.Encode())
-
-		// Random Delay
-		time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
-
-		// Sending the creation request
-		resp, body, err := s.Request("post", "rest/ip6_subnet6_add", &parameters)
-
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
-
-			// Checking the answer
-			if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-				if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-					log.Printf("[DEBUG] SOLIDServer - Created IP v6 subnet (oid): %s\n", oid)
-					d.SetId(oid)
-					d.Set("prefix", hexip6toip6(subnetAddresses[i])+"/"+strconv.Itoa(d.Get("size").(int)))
-					if goffset != 0 {
-						d.Set("gateway", gateway)
-					}
-					return nil
-				}
-			} else {
-				log.Printf("[DEBUG] SOLIDServer - Failed IP v6 subnet registration, trying another one.\n")
-			}
-		} else {
+	if ranges := addressrangesfromattr(d.Get("reserved_ranges")); len(ranges) > 0 {
+		if err := s.ReserveSubnet6Ranges(ctx, subnet, ranges); err != nil {
 			// Reporting a failure
 			return err
 		}
 	}
 
-	// Reporting a failure
-	return fmt.Errorf("SOLIDServer - Unable to create IP v6 subnet: %s", d.Get("name").(string))
+	return nil
 }
 
 func resourceip6subnetUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet6_id", d.Id())
-	parameters.Add("subnet6_name", d.Get("name").(string))
-	parameters.Add("subnet6_class_name", d.Get("class").(string))
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	// Edit only
-	parameters.Add("add_flag", "edit_only")
+	spec := resourceip6subnetspec(d)
 
-	if d.Get("terminal").(bool) {
-		parameters.Add("is_terminal", "1")
-	} else {
-		parameters.Add("is_terminal", "0")
+	if spec.GatewayOffset != 0 {
+		// Keeping the gateway computed on creation
+		spec.Gateway = d.Get("gateway").(string)
 	}
 
-	// Building class_parameters
-	classParameters := url.Values{}
+	subnet, err := s.UpdateSubnet6(ctx, d.Id(), spec)
 
-	// Generate class parameter for the gateway if required
-	goffset := d.Get("gateway_offset").(int)
-
-	if goffset != 0 {
-		classParameters.Add("gateway", d.Get("gateway").(string))
-		log.Printf("[DEBUG] SOLIDServer - Subnet updated gateway: %s\n", d.Get("gateway").(string))
+	if err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	for k, v := range d.Get("class_parameters").(map[string]interface{}) {
-		classParameters.Add(k, v.(string))
-	}
-	parameters.Add("subnet6_class_parameters", classParameters.Encode())
-
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/ip6_subnet6_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated IP v6 subnet (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	d.SetId(subnet.ID)
 
-		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update IP v6 subnet: %s\n", d.Get("name").(string))
+	if d.HasChange("reserved_ranges") {
+		return resourceip6subnetrangesUpdate(ctx, d, meta)
 	}
 
-	// Reporting a failure
-	return err
+	return nil
 }
 
-func resourceip6subnetgatewayDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+// Release the reserved ranges removed from the configuration, then reserve the new ones
+func resourceip6subnetrangesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
 
-	if d.Get("gateway") != nil {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("site_name", d.Get("space").(string))
-		parameters.Add("hostaddr", d.Get("gateway").(string))
+	subnet, err := s.GetSubnet6(ctx, d.Id())
 
-		// Sending the deletion request
-		resp, body, err := s.Request("delete", "rest/ip6_address6_delete", &parameters)
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
 
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
+	o, n := d.GetChange("reserved_ranges")
+	previous := addressrangesfromattr(o)
+	ranges := addressrangesfromattr(n)
 
-			// Checking the answer
-			if resp.StatusCode != 204 && len(buf) > 0 {
-				if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-					log.Printf("[DEBUG] SOLIDServer - Unable to delete IP v6 subnet's gateway : %s (%s)\n", d.Get("gateway").(string), errMsg)
-				}
-			}
+	if err := s.ReleaseSubnet6Ranges(ctx, subnet, addressrangesdiff(previous, ranges)); err != nil {
+		// Reporting a failure
+		return err
+	}
 
-			// Log deletion
-			log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 subnet's gateway: %s\n", d.Get("gateway").(string))
+	return s.ReserveSubnet6Ranges(ctx, subnet, addressrangesdiff(ranges, previous))
+}
 
-			// Reporting a success
-			return nil
-		}
+func resourceip6subnetgatewayDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
 
-		// Reporting a failure
-		return err
+	if gateway := d.Get("gateway").(string); gateway != "" {
+		if err := s.DeleteAddress6ByIP(ctx, d.Get("space").(string), gateway); err != nil {
+			log.Printf("[DEBUG] SOLIDServer - Unable to delete IP v6 subnet's gateway : %s (%s)\n", gateway, err)
+		}
 	}
 
-	// Reporting a success (nothing done)
+	// Reporting a success
 	return nil
 }
 
 func resourceip6subnetDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
 	// Delete related resources such as the Gateway
-	if d.Get("gateway_offset") != 0 {
-		resourceip6subnetgatewayDelete(d, meta)
+	if d.Get("gateway_offset").(int) != 0 {
+		resourceip6subnetgatewayDelete(ctx, d, meta)
 	}
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet6_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/ip6_subnet6_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete IP v6 subnet : %s (%s)\n", d.Get("name"), errMsg)
-			}
+	if err := s.DeleteSubnet6(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 subnet (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete IP v6 subnet : %s (%s)\n", d.Get("name"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourceip6subnetRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet6_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip6_block6_subnet6_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("block", buf[0]["parent_subnet6_name"].(string))
-			d.Set("name", buf[0]["subnet6_name"].(string))
-			d.Set("class", buf[0]["subnet6_class_name"].(string))
-
-			if buf[0]["is_terminal"].(string) == "1" {
-				d.Set("terminal", true)
-			} else {
-				d.Set("terminal", false)
-			}
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["subnet6_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			if gateway, gatewayExist := retrievedClassParameters["gateway"]; gatewayExist {
-				d.Set("gateway", gateway[0])
-			}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 subnet: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 subnet (oid): %s\n", d.Id())
-		}
+	subnet, err := s.GetSubnet6(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find IP v6 subnet: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
-}
+	d.Set("space", subnet.Space)
+	d.Set("block", subnet.Block)
+	d.Set("name", subnet.Name)
+	d.Set("terminal", subnet.Terminal)
+	d.Set("class", subnet.Class)
 
-func resourceip6subnetImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet6_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip6_block6_subnet6_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("block", buf[0]["parent_subnet6_name"].(string))
-			d.Set("name", buf[0]["subnet6_name"].(string))
-			d.Set("class", buf[0]["subnet6_class_name"].(string))
-
-			// Setting local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["subnet6_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			if gateway, gatewayExist := retrievedClassParameters["gateway"]; gatewayExist {
-				d.Set("gateway", gateway[0])
-			}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return []*schema.ResourceData{d}, nil
-		}
+	if subnet.Address != "" && subnet.PrefixLength > 0 {
+		d.Set("prefix", subnet.Address+"/"+strconv.Itoa(subnet.PrefixLength))
+	}
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to import IP v6 subnet (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import IP v6 subnet (oid): %s\n", d.Id())
-		}
+	if subnet.Gateway != "" {
+		d.Set("gateway", subnet.Gateway)
+	}
+
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), subnet.ClassParameters))
 
+	return nil
+}
+
+func resourceip6subnetImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourceip6subnetRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import IP v6 subnet (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
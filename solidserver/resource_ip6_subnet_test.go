@@ -0,0 +1,195 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create an IP v6 block on the fake SOLIDserver
+func testIP6Block(t *testing.T, s *sdsclient.SOLIDserver, space string, name string, address string, size int) *schema.ResourceData {
+	return testResourceCreate(t, s, resourceip6subnet(), map[string]interface{}{
+		"space":      space,
+		"request_ip": address,
+		"size":       size,
+		"name":       name,
+		"terminal":   false,
+	})
+}
+
+// Create a terminal IP v6 subnet on the fake SOLIDserver
+func testIP6Subnet(t *testing.T, s *sdsclient.SOLIDserver, space string, block string, name string, size int) *schema.ResourceData {
+	return testResourceCreate(t, s, resourceip6subnet(), map[string]interface{}{
+		"space": space,
+		"block": block,
+		"size":  size,
+		"name":  name,
+	})
+}
+
+func TestIP6Subnet_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6subnet()
+
+	testIPSpace(t, s, "office")
+	block := testIP6Block(t, s, "office", "lan", "2001:0db8:0000:0000:0000:0000:0000:0000", 48)
+
+	testResourceAttributes(t, block, map[string]interface{}{
+		"prefix":   "2001:0db8:0000:0000:0000:0000:0000:0000/48",
+		"terminal": false,
+		"block":    "",
+	})
+
+	testIP6Subnet(t, s, "office", "lan", "servers", 64)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"block":            "lan",
+		"size":             64,
+		"name":             "desktops",
+		"class_parameters": map[string]interface{}{"vlan": "10"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"prefix":           "2001:0db8:0000:0001:0000:0000:0000:0000/64",
+		"block":            "lan",
+		"terminal":         true,
+		"class_parameters": map[string]interface{}{"vlan": "10"},
+	})
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":            "office",
+		"block":            "lan",
+		"size":             64,
+		"name":             "laptops",
+		"class_parameters": map[string]interface{}{"vlan": "20"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "laptops",
+		"class_parameters": map[string]interface{}{"vlan": "20"},
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space":  "office",
+		"block":  "lan",
+		"name":   "laptops",
+		"prefix": "2001:0db8:0000:0001:0000:0000:0000:0000/64",
+	})
+
+	testResourceDelete(t, s, r, d)
+	testResourceDelete(t, s, r, block)
+
+	if rows := f.Rows("ip6_subnet6", "site_name", "office"); len(rows) != 0 {
+		t.Errorf("expected every IP v6 subnet to be deleted, got %v", rows)
+	}
+}
+
+func TestIP6Subnet_ReservedRanges(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceip6subnet()
+
+	testIPSpace(t, s, "office")
+	testIP6Block(t, s, "office", "lan", "2001:0db8:0000:0000:0000:0000:0000:0000", 48)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  64,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "routers", "offset": 1, "size": 5},
+			map[string]interface{}{"name": "dhcp", "offset": -20, "size": 20},
+		},
+	})
+
+	if rows := f.Rows("ip6_address6", "ip6_name", "routers"); len(rows) != 5 {
+		t.Errorf("expected 5 addresses reserved for the routers, got %v", rows)
+	}
+
+	if rows := f.Rows("ip6_address6", "ip6_name", "dhcp"); len(rows) != 20 ||
+		len(f.Rows("ip6_address6", "hostaddr", "2001:0db8:0000:0000:ffff:ffff:ffff:ffec")) != 1 ||
+		len(f.Rows("ip6_address6", "hostaddr", "2001:0db8:0000:0000:ffff:ffff:ffff:ffff")) != 1 {
+		t.Errorf("expected the last 20 addresses to be reserved for the DHCP, got %v", rows)
+	}
+
+	// Reserved addresses are never found free
+	address := testResourceCreate(t, s, resourceip6address(), map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "www",
+	})
+
+	testResourceAttributes(t, address, map[string]interface{}{"address": "2001:0db8:0000:0000:0000:0000:0000:0006"})
+
+	// Ranges added later are reserved in place
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  64,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "vips", "offset": 10, "size": 2},
+		},
+	})
+
+	if rows := f.Rows("ip6_address6", "ip6_name", "vips"); len(rows) != 2 {
+		t.Errorf("expected 2 addresses reserved for the VIPs, got %v", rows)
+	}
+
+	// A range overlapping a used address is rolled back
+	overlapping := map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  64,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "nat", "offset": 20, "size": 1},
+			map[string]interface{}{"name": "nat", "offset": 5, "size": 2},
+		},
+	}
+
+	if _, err := r.Apply(d.State(), testResourceDiff(t, s, r, d, overlapping), s); err == nil {
+		t.Errorf("expected an error reserving a range over a used address")
+	}
+
+	if rows := f.Rows("ip6_address6", "ip6_name", "nat"); len(rows) != 0 {
+		t.Errorf("expected the partially reserved range to be released, got %v", rows)
+	}
+
+	testResourceDelete(t, s, r, d)
+}
+
+func TestIP6Subnet_ReservedRangesGateway(t *testing.T) {
+	for _, c := range []struct {
+		ranges        []sdsclient.AddressRange
+		gatewayOffset int
+		bits          int
+		prefixLength  int
+		broadcast     bool
+		overlaps      bool
+	}{
+		{[]sdsclient.AddressRange{{Name: "routers", Offset: 1, Size: 5}}, 1, 128, 64, false, true},
+		{[]sdsclient.AddressRange{{Name: "routers", Offset: 1, Size: 5}}, 6, 128, 64, false, false},
+		{[]sdsclient.AddressRange{{Name: "dhcp", Offset: -20, Size: 20}}, -1, 128, 64, false, true},
+		{[]sdsclient.AddressRange{{Name: "dhcp", Offset: -20, Size: 20}}, -21, 128, 64, false, false},
+		{[]sdsclient.AddressRange{{Name: "dhcp", Offset: -20, Size: 20}}, 1, 128, 64, false, false},
+		{[]sdsclient.AddressRange{{Name: "routers", Offset: 1, Size: 5}}, -1, 128, 126, false, true},
+		{[]sdsclient.AddressRange{{Name: "dhcp", Offset: 4, Size: 3}}, -1, 32, 29, true, true},
+		{[]sdsclient.AddressRange{{Name: "dhcp", Offset: 4, Size: 2}}, -1, 32, 29, true, false},
+		// The check is skipped while the size or the gateway are unknown
+		{[]sdsclient.AddressRange{{Name: "routers", Offset: 1, Size: 5}}, 1, 128, 0, false, false},
+		{[]sdsclient.AddressRange{{Name: "routers", Offset: 1, Size: 5}}, 0, 128, 64, false, false},
+	} {
+		err := addressrangesgateway(c.ranges, c.gatewayOffset, c.bits, c.prefixLength, c.broadcast)
+
+		if (err != nil) != c.overlaps {
+			t.Errorf("expected an overlap of %v with the gateway at offset %d of a /%d to be %t, got %v", c.ranges, c.gatewayOffset, c.prefixLength, c.overlaps, err)
+		}
+	}
+}
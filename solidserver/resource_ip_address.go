@@ -1,12 +1,9 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
-	"regexp"
 )
 
 func resourceipaddress() *schema.Resource {
@@ -20,6 +17,8 @@ func resourceipaddress() *schema.Resource {
 			State: resourceipaddressImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"space": {
 				Type:        schema.TypeString,
@@ -33,6 +32,13 @@ func resourceipaddress() *schema.Resource {
 				Required:    true,
 				ForceNew:    true,
 			},
+			"pool": {
+				Type:        schema.TypeString,
+				Description: "The name of the pool of the subnet from which allocating the IP address.",
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+			},
 			"request_ip": {
 				Type:         schema.TypeString,
 				Description:  "The optionally requested IP address.",
@@ -87,347 +93,117 @@ func resourceipaddress() *schema.Resource {
 }
 
 func resourceipaddressExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of IP address (oid): %s\n", d.Id())
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_address_info", &parameters)
+	_, err := s.GetAddress(ctx, d.Id())
 
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s\n", d.Id())
-		}
+	return resourceexists(d, err)
+}
 
-		// Unset local ID
-		d.SetId("")
+func resourceipaddressspec(d *schema.ResourceData) sdsclient.AddressSpec {
+	return sdsclient.AddressSpec{
+		Space:           d.Get("space").(string),
+		Subnet:          d.Get("subnet").(string),
+		Pool:            d.Get("pool").(string),
+		RequestIP:       d.Get("request_ip").(string),
+		Name:            d.Get("name").(string),
+		MAC:             d.Get("mac").(string),
+		Device:          d.Get("device").(string),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
 	}
-
-	return false, err
 }
 
 func resourceipaddressCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	var ipAddresses []string = nil
-	var deviceID string = ""
-
-	// Gather required ID(s) from provided information
-	siteID, siteErr := ipsiteidbyname(d.Get("space").(string), meta)
-
-	if siteErr != nil {
-		// Reporting a failure
-		return siteErr
-	}
+	address, err := s.CreateAddress(ctx, resourceipaddressspec(d))
 
-	subnetID, SubnetErr := ipsubnetidbyname(siteID, d.Get("subnet").(string), true, meta)
-	if SubnetErr != nil {
+	if err != nil {
 		// Reporting a failure
-		return SubnetErr
+		return err
 	}
 
-	// Retrieving device ID
-	if len(d.Get("device").(string)) > 0 {
-		var deviceErr error = nil
-
-		deviceID, deviceErr = hostdevidbyname(d.Get("device").(string), meta)
-
-		if deviceErr != nil {
-			// Reporting a failure
-			return deviceErr
-		}
-	}
-
-	// Determining if an IP address was submitted in or if we should get one from the IPAM
-	if len(d.Get("request_ip").(string)) > 0 {
-		ipAddresses = []string{d.Get("request_ip").(string)}
-	} else {
-		var ipErr error = nil
-
-		ipAddresses, ipErr = ipaddressfindfree(subnetID, meta)
-
-		if ipErr != nil {
-			// Reporting a failure
-			return ipErr
-		}
-	}
-
-	for i := 0; i < len(ipAddresses); i++ {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("site_id", siteID)
-		parameters.Add("add_flag", "new_only")
-		parameters.Add("name", d.Get("name").(string))
-		parameters.Add("hostaddr", ipAddresses[i])
-		parameters.Add("hostdev_id", deviceID)
-		parameters.Add("ip_class_name", d.Get("class").(string))
-
-		if d.Get("mac").(string) != "" {
-			parameters.Add("mac_addr", d.Get("mac").(string))
-		}
-
-		// Building class_parameters
-		parameters.Add("ip_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
-
-		// Sending the creation request
-		resp, body, err := s.Request("post", "rest/ip_add", &parameters)
-
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
-
-			// Checking the answer
-			if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-				if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-					log.Printf("[DEBUG] SOLIDServer - Created IP address (oid): %s\n", oid)
-					d.SetId(oid)
-					d.Set("address", ipAddresses[i])
-					return nil
-				}
-			} else {
-				log.Printf("[DEBUG] SOLIDServer - Failed IP address registration (%s), trying another one.\n", ipAddresses[i])
-			}
-		} else {
-			// Reporting a failure
-			return err
-		}
-	}
+	d.SetId(address.ID)
+	d.Set("address", address.Address)
 
-	// Reporting a failure
-	return fmt.Errorf("SOLIDServer - Unable to create IP address: %s", d.Get("name").(string))
+	return nil
 }
 
 func resourceipaddressUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	var deviceID string = ""
-
-	// Retrieving device ID
-	if len(d.Get("device").(string)) > 0 {
-		var err error = nil
-
-		deviceID, err = hostdevidbyname(d.Get("device").(string), meta)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-		if err != nil {
-			// Reporting a failure
-			return err
-		}
-	}
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("name", d.Get("name").(string))
-	parameters.Add("hostdev_id", deviceID)
-	parameters.Add("ip_class_name", d.Get("class").(string))
-
-	if d.Get("mac").(string) != "" {
-		parameters.Add("mac_addr", d.Get("mac").(string))
-	}
-
-	// Building class_parameters
-	parameters.Add("ip_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
-
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/ip_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated IP address (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	address, err := s.UpdateAddress(ctx, d.Id(), resourceipaddressspec(d))
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update IP address: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(address.ID)
+
+	return nil
 }
 
 func resourceipaddressDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/ip_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete IP address : %s (%s)\n", d.Get("name"), errMsg)
-			}
+	if err := s.DeleteAddress(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted IP address's oid: %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete IP address : %s (%s)\n", d.Get("name"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourceipaddressRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_address_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("subnet", buf[0]["subnet_name"].(string))
-			d.Set("address", hexiptoip(buf[0]["ip_addr"].(string)))
-			d.Set("name", buf[0]["name"].(string))
-
-			if macIgnore, _ := regexp.MatchString("^EIP:", buf[0]["mac_addr"].(string)); !macIgnore {
-				d.Set("mac", buf[0]["mac_addr"].(string))
-			} else {
-				d.Set("mac", "")
-			}
-
-			d.Set("class", buf[0]["ip_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["ip_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP address: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s\n", d.Id())
-		}
+	address, err := s.GetAddress(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find IP address: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.Set("space", address.Space)
+	d.Set("subnet", address.Subnet)
+	d.Set("address", address.Address)
+	d.Set("name", address.Name)
+	d.Set("mac", address.MAC)
+	d.Set("class", address.Class)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), address.ClassParameters))
+
+	return nil
 }
 
 func resourceipaddressImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_address_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("subnet", buf[0]["subnet_name"].(string))
-			d.Set("address", hexiptoip(buf[0]["ip_addr"].(string)))
-			d.Set("name", buf[0]["name"].(string))
-			d.Set("mac", buf[0]["mac_addr"].(string))
-			d.Set("class", buf[0]["ip_class_name"].(string))
-
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["ip_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return []*schema.ResourceData{d}, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to import IP address (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import IP address (oid): %s\n", d.Id())
-		}
-
+	if err := resourceipaddressRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import IP address (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,244 @@
+package solidserver
+
+import (
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+	"log"
+	"strings"
+)
+
+func resourceipaddressblock() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceipaddressblockCreate,
+		Read:   resourceipaddressblockRead,
+		Update: resourceipaddressblockUpdate,
+		Delete: resourceipaddressblockDelete,
+		Exists: resourceipaddressblockExists,
+		Importer: &schema.ResourceImporter{
+			State: resourceipaddressblockImportState,
+		},
+
+		Timeouts: resourcetimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"space": {
+				Type:        schema.TypeString,
+				Description: "The name of the space into which creating the IP addresses.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"subnet": {
+				Type:        schema.TypeString,
+				Description: "The name of the subnet into which creating the IP addresses.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"pool": {
+				Type:        schema.TypeString,
+				Description: "The name of the pool of the subnet from which allocating the IP addresses.",
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Description: "The number of contiguous IP addresses to create.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name_template": {
+				Type:        schema.TypeString,
+				Description: "The name of the IP addresses, formatted with the index of each address starting at 1 (ex: node-%02d).",
+				Required:    true,
+				ForceNew:    false,
+			},
+			"addresses": {
+				Type:        schema.TypeList,
+				Description: "The provisionned IP addresses, in order.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"class": {
+				Type:        schema.TypeString,
+				Description: "The class associated to the IP addresses.",
+				Optional:    true,
+				ForceNew:    false,
+				Default:     "",
+			},
+			"class_parameters": {
+				Type:        schema.TypeMap,
+				Description: "The class parameters associated to the IP addresses.",
+				Optional:    true,
+				ForceNew:    false,
+				Default:     map[string]string{},
+			},
+		},
+	}
+}
+
+// The oid of an IP address block lists the oids of its addresses, in order
+func resourceipaddressblockids(d *schema.ResourceData) []string {
+	return strings.Split(d.Id(), ",")
+}
+
+func resourceipaddressblockExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	log.Printf("[DEBUG] Checking existence of IP address block (oid): %s\n", d.Id())
+
+	var err error = nil
+
+	// The block only vanishes once every address is gone, Read shrinks it to the addresses left
+	for _, id := range resourceipaddressblockids(d) {
+		if _, err = s.GetAddress(ctx, id); err == nil || !sdsclient.IsNotFound(err) {
+			break
+		}
+	}
+
+	return resourceexists(d, err)
+}
+
+func resourceipaddressblockCreate(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	addresses, err := s.CreateAddressBlock(ctx, sdsclient.AddressBlockSpec{
+		Space:           d.Get("space").(string),
+		Subnet:          d.Get("subnet").(string),
+		Pool:            d.Get("pool").(string),
+		Size:            d.Get("size").(int),
+		NameTemplate:    d.Get("name_template").(string),
+		Class:           d.Get("class").(string),
+		ClassParameters: classparamsfromattr(d.Get("class_parameters")),
+	})
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	ids := []string{}
+	ipAddresses := []string{}
+
+	for _, address := range addresses {
+		ids = append(ids, address.ID)
+		ipAddresses = append(ipAddresses, address.Address)
+	}
+
+	d.SetId(strings.Join(ids, ","))
+	d.Set("addresses", ipAddresses)
+
+	return nil
+}
+
+func resourceipaddressblockUpdate(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
+
+	ids := resourceipaddressblockids(d)
+
+	names, err := sdsclient.AddressBlockNames(d.Get("name_template").(string), len(ids))
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	for i, id := range ids {
+		if _, err := s.UpdateAddress(ctx, id, sdsclient.AddressSpec{
+			Space:           d.Get("space").(string),
+			Subnet:          d.Get("subnet").(string),
+			Name:            names[i],
+			Class:           d.Get("class").(string),
+			ClassParameters: classparamsfromattr(d.Get("class_parameters")),
+		}); err != nil {
+			// Reporting a failure
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceipaddressblockDelete(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	for _, id := range resourceipaddressblockids(d) {
+		if err := s.DeleteAddress(ctx, id); err != nil {
+			if !sdsclient.IsAPIError(err) {
+				// Reporting a failure
+				return err
+			}
+
+			log.Printf("[DEBUG] SOLIDServer - Unable to delete IP address (oid) of block: %s (%s)\n", id, err)
+		}
+	}
+
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
+}
+
+func resourceipaddressblockRead(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
+
+	ids := []string{}
+	ipAddresses := []string{}
+
+	for _, id := range resourceipaddressblockids(d) {
+		address, err := s.GetAddress(ctx, id)
+
+		if err != nil {
+			if !sdsclient.IsNotFound(err) {
+				// Reporting a failure
+				return sdsclient.WrapError(err, "SOLIDServer - Unable to read IP address (oid) of block: %s", id)
+			}
+
+			// The block shrinks to the addresses left, its smaller size re-creates it
+			log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid) of block: %s (%s)\n", id, err)
+			continue
+		}
+
+		// The first address left holds the attributes shared by the block
+		if len(ids) == 0 {
+			d.Set("space", address.Space)
+			d.Set("subnet", address.Subnet)
+			d.Set("class", address.Class)
+			d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), address.ClassParameters))
+		}
+
+		ids = append(ids, address.ID)
+		ipAddresses = append(ipAddresses, address.Address)
+	}
+
+	// Without any address left, the block is gone
+	if len(ids) == 0 {
+		log.Printf("[DEBUG] SOLIDServer - Unable to find any IP address of block (oid): %s\n", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.SetId(strings.Join(ids, ","))
+	d.Set("size", len(ids))
+	d.Set("addresses", ipAddresses)
+
+	return nil
+}
+
+func resourceipaddressblockImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourceipaddressblockRead(d, meta); err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
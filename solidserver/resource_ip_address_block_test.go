@@ -0,0 +1,311 @@
+package solidserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a space, a block and a /24 terminal subnet (10.0.0.0/24) on the fake SOLIDserver
+func testIPAddressBlockSubnet(t *testing.T, s *sdsclient.SOLIDserver) {
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+	testIPSubnet(t, s, "office", "lan", "servers", 24)
+}
+
+func TestIPAddressBlock_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddressblock()
+
+	testIPAddressBlockSubnet(t, s)
+
+	// Used addresses split the free ones, the block goes after them
+	for _, ip := range []string{"10.0.0.2", "10.0.0.5"} {
+		testResourceCreate(t, s, resourceipaddress(), map[string]interface{}{
+			"space":      "office",
+			"subnet":     "servers",
+			"request_ip": ip,
+			"name":       "used-" + ip,
+		})
+	}
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"size":             3,
+		"name_template":    "node-%02d",
+		"class_parameters": map[string]interface{}{"cluster": "k8s"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"addresses":        []interface{}{"10.0.0.6", "10.0.0.7", "10.0.0.8"},
+		"size":             3,
+		"class_parameters": map[string]interface{}{"cluster": "k8s"},
+	})
+
+	for i, name := range []string{"node-01", "node-02", "node-03"} {
+		if rows := f.Rows("ip_address", "name", name); len(rows) != 1 || rows[0]["hostaddr"] != d.Get("addresses").([]interface{})[i] {
+			t.Errorf("expected %s to be the address %d of the block, got %v", name, i+1, rows)
+		}
+	}
+
+	// Renaming the addresses in place
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          3,
+		"name_template": "worker-%d",
+	})
+
+	if rows := f.Rows("ip_address", "name", "worker-3"); len(rows) != 1 || rows[0]["hostaddr"] != "10.0.0.8" {
+		t.Errorf("expected the addresses to be renamed, got %v", rows)
+	}
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space":     "office",
+		"subnet":    "servers",
+		"size":      3,
+		"addresses": []interface{}{"10.0.0.6", "10.0.0.7", "10.0.0.8"},
+	})
+
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("ip_address", "subnet_name", "servers"); len(rows) != 2 {
+		t.Errorf("expected only the used addresses to be kept, got %v", rows)
+	}
+}
+
+func TestIPAddressBlock_Contiguous(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddressblock()
+
+	testIPAddressBlockSubnet(t, s)
+
+	// Every other address is used, contiguous free addresses are only found past the first
+	// suggestions, the client asking for 64 free addresses at a time
+	for i := 1; i < 2*64; i += 2 {
+		testResourceCreate(t, s, resourceipaddress(), map[string]interface{}{
+			"space":      "office",
+			"subnet":     "servers",
+			"request_ip": fmt.Sprintf("10.0.0.%d", i),
+			"name":       "used",
+		})
+	}
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          4,
+		"name_template": "lb-%d",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"addresses": []interface{}{"10.0.0.128", "10.0.0.129", "10.0.0.130", "10.0.0.131"},
+	})
+
+	// A subnet without enough contiguous free addresses
+	large := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          200,
+		"name_template": "node-%d",
+	})
+
+	if err := r.Create(large, s); err == nil {
+		t.Errorf("expected an error creating a block larger than the free addresses")
+	}
+}
+
+func TestIPAddressBlock_Pool(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddressblock()
+
+	testIPAddressBlockSubnet(t, s)
+	testResourceCreate(t, s, resourceippool(), map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "nodes",
+		"start":  "10.0.0.100",
+		"size":   8,
+	})
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"pool":          "nodes",
+		"size":          8,
+		"name_template": "node-%02d",
+	})
+
+	if addresses := d.Get("addresses").([]interface{}); len(addresses) != 8 || addresses[0] != "10.0.0.100" || addresses[7] != "10.0.0.107" {
+		t.Errorf("expected the block to fill the pool, got %v", addresses)
+	}
+
+	full := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"pool":          "nodes",
+		"size":          1,
+		"name_template": "node-%02d",
+	})
+
+	if err := r.Create(full, s); err == nil {
+		t.Errorf("expected an error creating a block in a full pool")
+	}
+}
+
+func TestIPAddressBlock_Gone(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddressblock()
+
+	testIPAddressBlockSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          3,
+		"name_template": "node-%d",
+	})
+
+	ids := resourceipaddressblockids(d)
+	f.Remove("ip_address", "hostaddr", "10.0.0.2")
+
+	// A partially missing block shrinks to the addresses left, which are still its own
+	if err := r.Read(d, s); err != nil {
+		t.Fatalf("unexpected error on read: %s", err)
+	}
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"size":      2,
+		"addresses": []interface{}{"10.0.0.1", "10.0.0.3"},
+	})
+
+	if d.Id() != ids[0]+","+ids[2] {
+		t.Errorf("expected the block to keep the oids of the addresses left, got %s", d.Id())
+	}
+
+	// Its smaller size plans to re-create it
+	diff, err := r.Diff(d.State(), testResourceConfig(t, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          3,
+		"name_template": "node-%d",
+	}), s)
+
+	if err != nil || !diff.RequiresNew() {
+		t.Errorf("expected the partially missing block to be re-created, got %#v (%v)", diff, err)
+	}
+
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("ip_address", "subnet_name", "servers"); len(rows) != 0 {
+		t.Errorf("expected the addresses left to be released, got %v", rows)
+	}
+
+	// A block without any address left is dropped from the state
+	d = testResourceCreate(t, s, r, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          2,
+		"name_template": "node-%d",
+	})
+
+	for _, address := range d.Get("addresses").([]interface{}) {
+		f.Remove("ip_address", "hostaddr", address.(string))
+	}
+
+	if err := r.Read(d, s); err != nil || d.Id() != "" {
+		t.Errorf("expected the block to be dropped from the state, got oid: %q, error: %v", d.Id(), err)
+	}
+}
+
+func TestIPAddressBlock_Rollback(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddressblock()
+
+	testIPAddressBlockSubnet(t, s)
+
+	// The third address is taken while the block is created
+	var add sdsfake.Service
+	add = f.Handle("rest/ip_add", func(method string, p url.Values) sdsfake.Answer {
+		if p.Get("hostaddr") == "10.0.0.3" {
+			return sdsfake.Error(http.StatusBadRequest, 2008, "IP address %s is already used", p.Get("hostaddr"))
+		}
+		return add(method, p)
+	})
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":         "office",
+		"subnet":        "servers",
+		"size":          4,
+		"name_template": "node-%02d",
+	})
+
+	if err := r.Create(d, s); err == nil || d.Id() != "" {
+		t.Fatalf("expected the creation of the block to fail, got oid %q and error %v", d.Id(), err)
+	}
+
+	if rows := f.Rows("ip_address", "subnet_name", "servers"); len(rows) != 0 {
+		t.Errorf("expected the addresses created so far to be deleted, got %v", rows)
+	}
+
+	// The rollback goes on once the creation is canceled, the addresses it could not delete are reported
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f.Handle("rest/ip_add", func(method string, p url.Values) sdsfake.Answer {
+		if p.Get("hostaddr") == "10.0.0.3" {
+			cancel()
+			return sdsfake.Error(http.StatusBadRequest, 2008, "IP address %s is already used", p.Get("hostaddr"))
+		}
+		return add(method, p)
+	})
+
+	var deleteaddress sdsfake.Service
+	deleteaddress = f.Handle("rest/ip_delete", func(method string, p url.Values) sdsfake.Answer {
+		if row := f.Get("ip_address", "ip_id", p.Get("ip_id")); row["hostaddr"] == "10.0.0.2" {
+			return sdsfake.Error(http.StatusBadRequest, 2020, "IP address %s is locked", row["hostaddr"])
+		}
+		return deleteaddress(method, p)
+	})
+
+	_, err := s.CreateAddressBlock(ctx, sdsclient.AddressBlockSpec{Space: "office", Subnet: "servers", Size: 4, NameTemplate: "node-%02d"})
+
+	if err == nil || !strings.Contains(err.Error(), "10.0.0.2") {
+		t.Errorf("expected the error to list the IP address which could not be released, got %v", err)
+	}
+
+	if rows := f.Rows("ip_address", "subnet_name", "servers"); len(rows) != 1 || rows[0]["hostaddr"] != "10.0.0.2" {
+		t.Errorf("expected only the locked address to be kept, got %v", rows)
+	}
+
+	f.Handle("rest/ip_delete", deleteaddress)
+
+	// Name templates must tell the addresses apart
+	for _, template := range []string{"node", "node-%d-%d", "node-%s"} {
+		invalid := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"space":         "office",
+			"subnet":        "servers",
+			"size":          2,
+			"name_template": template,
+		})
+
+		if err := r.Create(invalid, s); err == nil || !strings.Contains(err.Error(), "name template") {
+			t.Errorf("expected the name template %s to be rejected, got %v", template, err)
+		}
+	}
+}
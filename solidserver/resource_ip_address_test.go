@@ -0,0 +1,177 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a space, a block and a /29 terminal subnet (10.0.0.0/29) on the fake SOLIDserver
+func testIPAddressSubnet(t *testing.T, s *sdsclient.SOLIDserver) {
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+	testIPSubnet(t, s, "office", "lan", "servers", 29)
+}
+
+func TestIPAddress_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddress()
+
+	testIPAddressSubnet(t, s)
+	testResourceCreate(t, s, resourcedevice(), map[string]interface{}{"name": "srv01"})
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"name":             "web01.example.com",
+		"device":           "srv01",
+		"mac":              "00:11:22:33:44:55",
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"address":          "10.0.0.1",
+		"space":            "office",
+		"subnet":           "servers",
+		"name":             "web01.example.com",
+		"mac":              "00:11:22:33:44:55",
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	if rows := f.Rows("ip_address", "ip_id", d.Id()); len(rows) != 1 || rows[0]["hostdev_name"] != "srv01" {
+		t.Errorf("expected the address to be linked to its device, got %v", rows)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "www01.example.com",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"address": "10.0.0.1",
+		"name":    "www01.example.com",
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space":   "office",
+		"subnet":  "servers",
+		"address": "10.0.0.1",
+		"name":    "www01.example.com",
+	})
+
+	testResourceDelete(t, s, r, d)
+}
+
+func TestIPAddress_Allocation(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddress()
+
+	testIPAddressSubnet(t, s)
+
+	requested := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":      "office",
+		"subnet":     "servers",
+		"request_ip": "10.0.0.2",
+		"name":       "requested",
+	})
+
+	testResourceAttributes(t, requested, map[string]interface{}{"address": "10.0.0.2"})
+
+	// Free addresses skip the used ones, the network and the broadcast addresses
+	for _, expected := range []string{"10.0.0.1", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"} {
+		d := testResourceCreate(t, s, r, map[string]interface{}{
+			"space":  "office",
+			"subnet": "servers",
+			"name":   "host-" + expected,
+		})
+
+		testResourceAttributes(t, d, map[string]interface{}{"address": expected})
+	}
+
+	// The subnet is full
+	full := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "overflow",
+	})
+
+	if err := r.Create(full, s); err == nil {
+		t.Errorf("expected an error creating an address in a full subnet")
+	}
+
+	// A requested address can only be used once
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":      "office",
+		"subnet":     "servers",
+		"request_ip": "10.0.0.2",
+		"name":       "duplicate",
+	})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate address, got: %v", err)
+	}
+}
+
+func TestIPAddress_Pool(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipaddress()
+
+	testIPAddressSubnet(t, s)
+
+	for _, raw := range []map[string]interface{}{
+		{"space": "office", "subnet": "servers", "name": "infra", "start": "10.0.0.1", "size": 2, "read_only": true},
+		{"space": "office", "subnet": "servers", "name": "dhcp", "start": "10.0.0.5", "size": 2},
+	} {
+		testResourceCreate(t, s, resourceippool(), raw)
+	}
+
+	// Addresses are drawn from the pool only
+	for _, expected := range []string{"10.0.0.5", "10.0.0.6"} {
+		d := testResourceCreate(t, s, r, map[string]interface{}{
+			"space":  "office",
+			"subnet": "servers",
+			"pool":   "dhcp",
+			"name":   "lease-" + expected,
+		})
+
+		testResourceAttributes(t, d, map[string]interface{}{"address": expected})
+	}
+
+	full := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"pool":   "dhcp",
+		"name":   "lease-full",
+	})
+
+	if err := r.Create(full, s); err == nil {
+		t.Errorf("expected an error allocating from a full pool, got %s", full.Get("address"))
+	}
+
+	unknown := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"pool":   "unknown",
+		"name":   "lease-unknown",
+	})
+
+	if err := r.Create(unknown, s); err == nil {
+		t.Errorf("expected an error allocating from an unknown pool")
+	}
+
+	// Addresses of read-only pools are never allocated
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "www",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"address": "10.0.0.3"})
+}
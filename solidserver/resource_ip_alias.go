@@ -1,11 +1,9 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 )
 
 func resourceipalias() *schema.Resource {
@@ -15,6 +13,8 @@ func resourceipalias() *schema.Resource {
 		//Update: resourceipaliasUpdate,
 		Delete: resourceipaliasDelete,
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"space": {
 				Type:        schema.TypeString,
@@ -48,139 +48,64 @@ func resourceipalias() *schema.Resource {
 }
 
 func resourceipaliasCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	// Gather required ID(s) from provided information
-	siteID, err := ipsiteidbyname(d.Get("space").(string), meta)
-	if err != nil {
-		// Reporting a failure
-		return err
-	}
+	alias, err := s.CreateAlias(ctx, sdsclient.AliasSpec{
+		Space:   d.Get("space").(string),
+		Address: d.Get("address").(string),
+		Name:    d.Get("name").(string),
+		Type:    d.Get("type").(string),
+	})
 
-	addressID, err := ipaddressidbyip(siteID, d.Get("address").(string), meta)
 	if err != nil {
 		// Reporting a failure
 		return err
 	}
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", addressID)
-	parameters.Add("ip_name", d.Get("name").(string))
-	parameters.Add("ip_name_type", d.Get("type").(string))
-
-	// Sending the creation request
-	resp, body, err := s.Request("post", "rest/ip_alias_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created IP alias (oid): %s\n", oid)
-				d.SetId(oid)
-
-				return nil
-			}
-		}
+	d.SetId(alias.ID)
 
-		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to create IP alias: %s - %s (associated to IP address with ID: %s)\n", d.Get("name").(string), d.Get("type"), addressID)
-	}
-
-	// Reporting a failure
-	return err
+	return nil
 }
 
 func resourceipaliasDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_name_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/ip_alias_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete IP alias : %s - %s (%s)\n", d.Get("name"), d.Get("type"), errMsg)
-			}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	if err := s.DeleteAlias(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted IP alias with oid: %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete IP alias : %s - %s (%s)\n", d.Get("name"), d.Get("type"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourceipaliasRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-	// Gather required ID(s) from provided information
-	siteID, err := ipsiteidbyname(d.Get("space").(string), meta)
-	if err != nil {
-		// Reporting a failure
-		return err
-	}
+	alias, err := s.GetAlias(ctx, d.Get("space").(string), d.Get("address").(string), d.Id())
 
-	addressID, err := ipaddressidbyip(siteID, d.Get("address").(string), meta)
 	if err != nil {
-		// Reporting a failure
-		return err
-	}
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", addressID)
-	parameters.Add("WHERE", "ip_name_id='"+d.Id()+"'")
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_alias_list", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("name", buf[0]["alias_name"].(string))
-			d.Set("type", buf[0]["ip_name_type"].(string))
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP alias: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP alias (oid): %s\n", d.Id())
-		}
-
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find IP alias: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.Set("name", alias.Name)
+	d.Set("type", alias.Type)
+
+	return nil
 }
@@ -1,11 +1,9 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
 	"strings"
 )
 
@@ -16,6 +14,11 @@ func resourceipmac() *schema.Resource {
 		Delete: resourceipmacDelete,
 		Exists: resourceipmacExists,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(DefaultCreateTimeout),
+			Delete: schema.DefaultTimeout(DefaultDeleteTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"space": {
 				Type:        schema.TypeString,
@@ -43,158 +46,88 @@ func resourceipmac() *schema.Resource {
 }
 
 func resourceipmacExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of IP address (oid): %s; associated to the mac: %s\n", d.Id(), d.Get("mac").(string))
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_address_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if ipMac, ipMacExist := buf[0]["mac_addr"].(string); ipMacExist {
-				if strings.ToLower(ipMac) == strings.ToLower(d.Get("mac").(string)) {
-					return true, nil
-				}
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find the IP address (oid): %s; associated to the mac (%s)\n", d.Id(), d.Get("mac").(string))
-			}
-		} else {
-			if len(buf) > 0 {
-				if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-					// Log the error
-					log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s (%s)\n", d.Id(), errMsg)
-				}
-			} else {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s\n", d.Id())
-			}
-		}
+	address, err := s.GetAddress(ctx, d.Id())
+
+	if err != nil {
+		return resourceexists(d, err)
+	}
+
+	if !strings.EqualFold(address.MAC, d.Get("mac").(string)) {
+		// Log the error
+		log.Printf("[DEBUG] SOLIDServer - Unable to find the IP address (oid): %s; associated to the mac (%s)\n", d.Id(), d.Get("mac").(string))
 
 		// Unset local ID
 		d.SetId("")
+
+		return false, nil
 	}
 
-	return false, err
+	return true, nil
 }
 
 func resourceipmacCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("site_name", d.Get("space").(string))
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("hostaddr", d.Get("address").(string))
-	parameters.Add("mac_addr", strings.ToLower(d.Get("mac").(string)))
-	parameters.Add("keep_class_parameters", "1")
-
-	// Sending the creation request
-	resp, body, err := s.Request("put", "rest/ip_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Created IP MAC association (oid) %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		} else {
-			// Reporting a failure
-			return fmt.Errorf("SOLIDServer - Failed to create IP MAC association between %s and %s\n", d.Get("address").(string), d.Get("mac").(string))
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
+
+	oid, err := s.SetAddressMAC(ctx, d.Get("space").(string), d.Get("address").(string), d.Get("mac").(string))
+
+	if err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(oid)
+
+	return nil
 }
 
 func resourceipmacDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("site_name", d.Get("space").(string))
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("hostaddr", d.Get("address").(string))
-	parameters.Add("mac_addr", "")
-	parameters.Add("keep_class_parameters", "1")
-
-	// Sending the creation request
-	resp, body, err := s.Request("put", "rest/ip_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Deleted IP MAC association (oid) %s\n", oid)
-				d.SetId("")
-				return nil
-			}
-		} else {
-			return fmt.Errorf("SOLIDServer - Failed to delete IP MAC association between %s and %s\n", d.Get("address").(string), d.Get("mac").(string))
-		}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
+
+	if _, err := s.SetAddressMAC(ctx, d.Get("space").(string), d.Get("address").(string), ""); err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourceipmacRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("ip_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Reading information about IP address (oid): %s; associated to the mac: %s\n", d.Id(), d.Get("mac").(string))
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_address_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if ipMac, ipMacExist := buf[0]["mac_addr"].(string); ipMacExist {
-				if strings.ToLower(ipMac) == strings.ToLower(d.Get("mac").(string)) {
-					return nil
-				}
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find the IP address (oid): %s; associated to the mac (%s)\n", d.Id(), d.Get("mac").(string))
-			}
-		} else {
-			if len(buf) > 0 {
-				if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-					// Log the error
-					log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s (%s)\n", d.Id(), errMsg)
-				}
-			} else {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP address (oid): %s\n", d.Id())
-			}
-		}
+	address, err := s.GetAddress(ctx, d.Id())
+
+	if err != nil {
+		// Forgetting the mapping once its IP address is gone
+		_, err = resourceexists(d, err)
+
+		return err
+	}
+
+	if !strings.EqualFold(address.MAC, d.Get("mac").(string)) {
+		// Log the error
+		log.Printf("[DEBUG] SOLIDServer - Unable to find the IP address (oid): %s; associated to the mac (%s)\n", d.Id(), d.Get("mac").(string))
 
 		// Unset local ID
 		d.SetId("")
 	}
 
-	return err
+	return nil
 }
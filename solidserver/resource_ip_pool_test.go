@@ -0,0 +1,130 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestIPPool_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceippool()
+
+	testIPAddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"name":             "dhcp",
+		"start":            "10.0.0.3",
+		"size":             3,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"space":            "office",
+		"subnet":           "servers",
+		"start":            "10.0.0.3",
+		"end":              "10.0.0.5",
+		"size":             3,
+		"read_only":        false,
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":     "office",
+		"subnet":    "servers",
+		"name":      "dhcp-ro",
+		"start":     "10.0.0.3",
+		"size":      3,
+		"read_only": true,
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":      "dhcp-ro",
+		"end":       "10.0.0.5",
+		"read_only": true,
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space":     "office",
+		"subnet":    "servers",
+		"name":      "dhcp-ro",
+		"start":     "10.0.0.3",
+		"end":       "10.0.0.5",
+		"size":      3,
+		"read_only": true,
+	})
+
+	testResourceDelete(t, s, r, d)
+
+	if n := len(f.Rows("ip_pool", "subnet_name", "servers")); n != 0 {
+		t.Errorf("expected the pool to be deleted, got %d", n)
+	}
+}
+
+func TestIPPool_End(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceippool()
+
+	testIPAddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "vips",
+		"start":  "10.0.0.2",
+		"end":    "10.0.0.4",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"start": "10.0.0.2",
+		"end":   "10.0.0.4",
+		"size":  3,
+	})
+
+	// A pool needs an end or a size after its start
+	for _, raw := range []map[string]interface{}{
+		{"space": "office", "subnet": "servers", "name": "empty", "start": "10.0.0.5"},
+		{"space": "office", "subnet": "servers", "name": "reversed", "start": "10.0.0.6", "end": "10.0.0.5"},
+	} {
+		if err := r.Create(schema.TestResourceDataRaw(t, r.Schema, raw), s); err == nil {
+			t.Errorf("expected an error creating the pool %s", raw["name"])
+		}
+	}
+}
+
+func TestIPPool_Gone(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceippool()
+
+	testIPAddressSubnet(t, s)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "dhcp",
+		"start":  "10.0.0.3",
+		"size":   3,
+	})
+
+	id := d.Id()
+	f.Remove("ip_pool", "pool_id", id)
+
+	// A pool deleted behind the provider's back is dropped from the state
+	if err := r.Read(d, s); err != nil || d.Id() != "" {
+		t.Errorf("expected the pool to be dropped from the state, got oid: %q, error: %v", d.Id(), err)
+	}
+
+	// Deleting it again is not a failure
+	d.SetId(id)
+
+	if err := r.Delete(d, s); err != nil || d.Id() != "" {
+		t.Errorf("expected the deleted pool to be dropped from the state, got oid: %q, error: %v", d.Id(), err)
+	}
+}
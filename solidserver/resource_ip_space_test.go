@@ -0,0 +1,78 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a space on the fake SOLIDserver
+func testIPSpace(t *testing.T, s *sdsclient.SOLIDserver, name string) *schema.ResourceData {
+	return testResourceCreate(t, s, resourceipspace(), map[string]interface{}{
+		"name": name,
+	})
+}
+
+func TestIPSpace_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipspace()
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"name":             "office",
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "office",
+		"class":            "",
+		"class_parameters": map[string]interface{}{"owner": "netops"},
+	})
+
+	// Space names are unique
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": "office"})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate space, got: %v", err)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"name":             "office",
+		"class":            "branch",
+		"class_parameters": map[string]interface{}{"owner": "secops"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"class":            "branch",
+		"class_parameters": map[string]interface{}{"owner": "secops"},
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"name":  "office",
+		"class": "branch",
+	})
+
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("ip_site", "site_name", "office"); len(rows) != 0 {
+		t.Errorf("expected the space to be deleted, got %v", rows)
+	}
+}
+
+func TestIPSpace_DeletedOutOfBand(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipspace()
+
+	d := testIPSpace(t, s, "office")
+	f.Remove("ip_site", "site_id", d.Id())
+
+	testResourceGone(t, s, r, d.Id())
+
+	if err := r.Read(d, s); !sdsclient.IsNotFound(err) {
+		t.Errorf("expected a not found error reading a deleted space, got: %v", err)
+	}
+}
@@ -1,14 +1,11 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"math/rand"
-	"net/url"
 	"strconv"
-	"time"
 )
 
 func resourceipsubnet() *schema.Resource {
@@ -22,6 +19,9 @@ func resourceipsubnet() *schema.Resource {
 			State: resourceipsubnetImportState,
 		},
 
+		CustomizeDiff: resourceipsubnetgatewayDiff,
+		Timeouts:      resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"space": {
 				Type:        schema.TypeString,
@@ -30,11 +30,27 @@ func resourceipsubnet() *schema.Resource {
 				ForceNew:    true,
 			},
 			"block": {
-				Type:        schema.TypeString,
-				Description: "The name of the block into which creating the IP subnet.",
-				Optional:    true,
-				ForceNew:    true,
-				Default:     "",
+				Type:          schema.TypeString,
+				Description:   "The name of the block into which creating the IP subnet, computed when the block is chosen among blocks or block_class_parameters.",
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"blocks", "block_class_parameters"},
+			},
+			"blocks": {
+				Type:          schema.TypeList,
+				Description:   "The names of the blocks into which creating the IP subnet, tried in order until one has a free subnet of the expected size.",
+				Optional:      true,
+				ForceNew:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"block", "block_class_parameters"},
+			},
+			"block_class_parameters": {
+				Type:          schema.TypeMap,
+				Description:   "The class parameters of the blocks into which creating the IP subnet, matching blocks are tried by name until one has a free subnet of the expected size.",
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"block", "blocks"},
 			},
 			"request_ip": {
 				Type:         schema.TypeString,
@@ -65,15 +81,15 @@ func resourceipsubnet() *schema.Resource {
 				Type:        schema.TypeInt,
 				Description: "Offset for creating the gateway. Default is 0 (No gateway).",
 				Optional:    true,
-				ForceNew:    true,
+				ForceNew:    false,
 				Default:     0,
 			},
 			"gateway": {
 				Type:        schema.TypeString,
 				Description: "The subnet's computed gateway.",
 				Computed:    true,
-				ForceNew:    true,
 			},
+			"reserved_ranges": reservedrangesschema(),
 			"name": {
 				Type:        schema.TypeString,
 				Description: "The name of the IP subnet to create.",
@@ -106,423 +122,249 @@ func resourceipsubnet() *schema.Resource {
 }
 
 func resourceipsubnetExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of IP subnet (oid): %s\n", d.Id())
 
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_block_subnet_info", &parameters)
+	_, err := s.GetSubnet(ctx, d.Id())
 
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
+	return resourceexists(d, err)
+}
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP subnet (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP subnet (oid): %s\n", d.Id())
-		}
+func resourceipsubnetspec(d *schema.ResourceData) sdsclient.SubnetSpec {
+	blocks := []string{}
 
-		// Unset local ID
-		d.SetId("")
+	for _, block := range d.Get("blocks").([]interface{}) {
+		blocks = append(blocks, block.(string))
 	}
 
-	return false, err
+	return sdsclient.SubnetSpec{
+		Space:                d.Get("space").(string),
+		Block:                d.Get("block").(string),
+		Blocks:               blocks,
+		BlockClassParameters: classparamsfromattr(d.Get("block_class_parameters")),
+		RequestIP:            d.Get("request_ip").(string),
+		PrefixLength:         d.Get("size").(int),
+		Name:                 d.Get("name").(string),
+		Terminal:             d.Get("terminal").(bool),
+		GatewayOffset:        d.Get("gateway_offset").(int),
+		Class:                d.Get("class").(string),
+		ClassParameters:      classparamsfromattr(d.Get("class_parameters")),
+	}
 }
 
 func resourceipsubnetCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	var blockID string = ""
-	var gateway string = ""
+	subnet, err := s.CreateSubnet(ctx, resourceipsubnetspec(d))
 
-	// Gather required ID(s) from provided information
-	siteID, siteErr := ipsiteidbyname(d.Get("space").(string), meta)
-	if siteErr != nil {
+	if err != nil {
 		// Reporting a failure
-		return siteErr
+		return err
 	}
 
-	// If a block is specified, look for free IP subnet within this block
-	if len(d.Get("block").(string)) > 0 {
-		var blockErr error = nil
+	d.SetId(subnet.ID)
+	d.Set("block", subnet.Block)
+	d.Set("prefix", subnet.Address+"/"+strconv.Itoa(subnet.PrefixLength))
+	d.Set("netmask", sdsclient.Netmask(subnet.PrefixLength))
 
-		blockID, blockErr = ipsubnetidbyname(siteID, d.Get("block").(string), false, meta)
+	if subnet.Gateway != "" {
+		d.Set("gateway", subnet.Gateway)
+	}
 
-		if blockErr != nil {
+	if ranges := addressrangesfromattr(d.Get("reserved_ranges")); len(ranges) > 0 {
+		if err := s.ReserveSubnetRanges(ctx, subnet, ranges); err != nil {
 			// Reporting a failure
-			return blockErr
-		}
-	} else {
-		// However, we can't create a block as a terminal subnet
-		if d.Get("terminal").(bool) {
-			return fmt.Errorf("SOLIDServer - Can't create a terminal IP block subnet: %s", d.Get("name").(string))
+			return err
 		}
 	}
 
-	subnetAddresses, subnetErr := ipsubnetfindbysize(siteID, blockID, d.Get("request_ip").(string), d.Get("size").(int), meta)
+	return nil
+}
 
-	if subnetErr != nil {
-		// Reporting a failure
-		return subnetErr
-	}
+func resourceipsubnetUpdate(d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	for i := 0; i < len(subnetAddresses); i++ {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("site_id", siteID)
-		parameters.Add("subnet_name", d.Get("name").(string))
-		parameters.Add("subnet_addr", hexiptoip(subnetAddresses[i]))
-		parameters.Add("subnet_prefix", strconv.Itoa(d.Get("size").(int)))
-		parameters.Add("subnet_class_name", d.Get("class").(string))
-
-		// New only
-		parameters.Add("add_flag", "new_only")
-
-		// If no block specified, create an IP block
-		if len(d.Get("block").(string)) == 0 {
-			parameters.Add("subnet_level", "0")
-		}
+	spec := resourceipsubnetspec(d)
 
-		// Specify if subnet is terminal
-		if d.Get("terminal").(bool) {
-			parameters.Add("is_terminal", "1")
-		} else {
-			parameters.Add("is_terminal", "0")
+	if !d.HasChange("gateway_offset") {
+		// Keeping the gateway computed on creation, a subnet without gateway offset keeps the one of its class parameters
+		if spec.GatewayOffset != 0 {
+			spec.Gateway = d.Get("gateway").(string)
 		}
 
-		// Building class_parameters
-		classParameters := url.Values{}
+		subnet, err := s.UpdateSubnet(ctx, d.Id(), spec)
 
-		// Generate class parameter for the gateway if required
-		goffset := d.Get("gateway_offset").(int)
-
-		if goffset != 0 {
-			if goffset > 0 {
-				gateway = longtoip(iptolong(hexiptoip(subnetAddresses[i])) + uint32(goffset))
-			} else {
-				gateway = longtoip(iptolong(hexiptoip(subnetAddresses[i])) + uint32(prefixlengthtosize(d.Get("size").(int))) - uint32(abs(goffset)) - 1)
-			}
-
-			classParameters.Add("gateway", gateway)
-			log.Printf("[DEBUG] SOLIDServer - Subnet computed gateway: %s\n", gateway)
+		if err != nil {
+			// Reporting a failure
+			return err
 		}
 
-		for k, v := range d.Get("class_parameters").(map[string]interface{}) {
-			classParameters.Add(k, v.(string))
-		}
-		parameters.Add("subnet_class_parameters", classParameters.Encode())
-
-		// Random Delay
-		time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
-
-		// Sending the creation request
-		resp, body, err := s.Request("post", "rest/ip_subnet_add", &parameters)
-
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
-
-			// Checking the answer
-			if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-				if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-					log.Printf("[DEBUG] SOLIDServer - Created IP subnet (oid): %s\n", oid)
-					d.SetId(oid)
-					d.Set("prefix", hexiptoip(subnetAddresses[i])+"/"+strconv.Itoa(d.Get("size").(int)))
-					d.Set("netmask", prefixlengthtohexip(d.Get("size").(int)))
-					if goffset != 0 {
-						d.Set("gateway", gateway)
-					}
-					return nil
-				}
-			} else {
-				log.Printf("[DEBUG] SOLIDServer - Failed IP subnet registration, trying another one.\n")
-			}
-		} else {
+		d.SetId(subnet.ID)
+	} else {
+		// Moving the gateway according to its new offset, a subnet without gateway releases the previous one
+		current, err := s.GetSubnet(ctx, d.Id())
+
+		if err != nil {
 			// Reporting a failure
 			return err
 		}
-	}
 
-	// Reporting a failure
-	return fmt.Errorf("SOLIDServer - Unable to create IP subnet: %s", d.Get("name").(string))
-}
+		// Only the gateway reserved for the previous offset is released, never one set through the class parameters
+		previous := ""
 
-func resourceipsubnetUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet_id", d.Id())
-	parameters.Add("subnet_name", d.Get("name").(string))
-	parameters.Add("subnet_class_name", d.Get("class").(string))
+		if o, _ := d.GetChange("gateway_offset"); o.(int) != 0 {
+			previous = sdsclient.SubnetGateway(current.Address, current.PrefixLength, o.(int))
+		}
 
-	// Edit only
-	parameters.Add("add_flag", "edit_only")
+		if spec.GatewayOffset != 0 {
+			spec.Gateway = sdsclient.SubnetGateway(current.Address, current.PrefixLength, spec.GatewayOffset)
+		}
 
-	if d.Get("terminal").(bool) {
-		parameters.Add("is_terminal", "1")
-	} else {
-		parameters.Add("is_terminal", "0")
-	}
+		subnet, err := s.MoveSubnetGateway(ctx, d.Id(), spec, previous)
 
-	// Building class_parameters
-	classParameters := url.Values{}
+		if err != nil {
+			// The subnet was updated even though its previous gateway is still reserved
+			if subnet != nil {
+				d.Set("gateway", subnet.Gateway)
+			}
 
-	// Generate class parameter for the gateway if required
-	goffset := d.Get("gateway_offset").(int)
+			// Reporting a failure
+			return err
+		}
 
-	if goffset != 0 {
-		classParameters.Add("gateway", d.Get("gateway").(string))
-		log.Printf("[DEBUG] SOLIDServer - Subnet updated gateway: %s\n", d.Get("gateway").(string))
+		d.SetId(subnet.ID)
+		d.Set("gateway", subnet.Gateway)
 	}
 
-	for k, v := range d.Get("class_parameters").(map[string]interface{}) {
-		classParameters.Add(k, v.(string))
+	if d.HasChange("reserved_ranges") {
+		return resourceipsubnetrangesUpdate(ctx, d, meta)
 	}
-	parameters.Add("subnet_class_parameters", classParameters.Encode())
 
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/ip_subnet_add", &parameters)
+	return nil
+}
 
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+// Release the reserved ranges removed from the configuration, then reserve the new ones
+func resourceipsubnetrangesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
 
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated IP subnet (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	subnet, err := s.GetSubnet(ctx, d.Id())
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update IP subnet: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
-}
+	o, n := d.GetChange("reserved_ranges")
+	previous := addressrangesfromattr(o)
+	ranges := addressrangesfromattr(n)
 
-func resourceipsubnetgatewayDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	if err := s.ReleaseSubnetRanges(ctx, subnet, addressrangesdiff(previous, ranges)); err != nil {
+		// Reporting a failure
+		return err
+	}
 
-	if d.Get("gateway") != nil {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("site_name", d.Get("space").(string))
-		parameters.Add("hostaddr", d.Get("gateway").(string))
+	return s.ReserveSubnetRanges(ctx, subnet, addressrangesdiff(ranges, previous))
+}
 
-		// Sending the deletion request
-		resp, body, err := s.Request("delete", "rest/ip_delete", &parameters)
+// Reject the reserved ranges overlapping the gateway, and compute the gateway again when its offset changes
+func resourceipsubnetgatewayDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := resourcereservedrangesDiff(32, true)(d, meta); err != nil {
+		// Reporting a failure
+		return err
+	}
 
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
+	if d.Id() != "" && d.HasChange("gateway_offset") {
+		return d.SetNewComputed("gateway")
+	}
 
-			// Checking the answer
-			if resp.StatusCode != 204 && len(buf) > 0 {
-				if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-					log.Printf("[DEBUG] SOLIDServer - Unable to delete IP subnet's gateway : %s (%s)\n", d.Get("gateway").(string), errMsg)
-				}
-			}
+	return nil
+}
 
-			// Log deletion
-			log.Printf("[DEBUG] SOLIDServer - Deleted IP subnet's gateway: %s\n", d.Get("gateway").(string))
+func resourceipsubnetgatewayDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	s := meta.(*sdsclient.SOLIDserver)
 
-			// Reporting a success
-			return nil
+	if gateway := d.Get("gateway").(string); gateway != "" {
+		if err := s.DeleteAddressByIP(ctx, d.Get("space").(string), gateway); err != nil {
+			log.Printf("[DEBUG] SOLIDServer - Unable to delete IP subnet's gateway : %s (%s)\n", gateway, err)
 		}
-
-		// Reporting a failure
-		return err
 	}
 
-	// Reporting a success (nothing done)
+	// Reporting a success
 	return nil
 }
 
 func resourceipsubnetDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
 	// Delete related resources such as the Gateway
-	if d.Get("gateway_offset") != 0 {
-		resourceipsubnetgatewayDelete(d, meta)
+	if d.Get("gateway_offset").(int) != 0 {
+		resourceipsubnetgatewayDelete(ctx, d, meta)
 	}
 
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/ip_subnet_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to delete IP subnet : %s (%s)\n", d.Get("name"), errMsg)
-			}
+	if err := s.DeleteSubnet(ctx, d.Id()); err != nil {
+		if !sdsclient.IsAPIError(err) {
+			// Reporting a failure
+			return err
 		}
 
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted IP subnet (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
-
-		// Reporting a success
-		return nil
+		log.Printf("[DEBUG] SOLIDServer - Unable to delete IP subnet : %s (%s)\n", d.Get("name"), err)
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourceipsubnetRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_block_subnet_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("block", buf[0]["parent_subnet_name"].(string))
-			d.Set("name", buf[0]["subnet_name"].(string))
-			d.Set("class", buf[0]["subnet_class_name"].(string))
-
-			if buf[0]["is_terminal"].(string) == "1" {
-				d.Set("terminal", true)
-			} else {
-				d.Set("terminal", false)
-			}
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-			// Updating local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["subnet_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			if gateway, gatewayExist := retrievedClassParameters["gateway"]; gatewayExist {
-				d.Set("gateway", gateway[0])
-			}
-
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
-
-			d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find IP subnet: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find IP subnet (oid): %s\n", d.Id())
-		}
+	subnet, err := s.GetSubnet(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find IP subnet: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
-}
-
-func resourceipsubnetImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("subnet_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/ip_block_subnet_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
+	d.Set("space", subnet.Space)
+	d.Set("block", subnet.Block)
+	d.Set("name", subnet.Name)
+	d.Set("terminal", subnet.Terminal)
+	d.Set("class", subnet.Class)
 
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			d.Set("space", buf[0]["site_name"].(string))
-			d.Set("block", buf[0]["parent_subnet_name"].(string))
-			d.Set("name", buf[0]["subnet_name"].(string))
-			d.Set("class", buf[0]["subnet_class_name"].(string))
-
-			// Setting local class_parameters
-			currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			retrievedClassParameters, _ := url.ParseQuery(buf[0]["subnet_class_parameters"].(string))
-			computedClassParameters := map[string]string{}
-
-			if gateway, gatewayExist := retrievedClassParameters["gateway"]; gatewayExist {
-				d.Set("gateway", gateway[0])
-			}
+	if subnet.Address != "" && subnet.PrefixLength > 0 {
+		d.Set("prefix", subnet.Address+"/"+strconv.Itoa(subnet.PrefixLength))
+		d.Set("netmask", sdsclient.Netmask(subnet.PrefixLength))
+	}
 
-			for ck := range currentClassParameters {
-				if rv, rvExist := retrievedClassParameters[ck]; rvExist {
-					computedClassParameters[ck] = rv[0]
-				} else {
-					computedClassParameters[ck] = ""
-				}
-			}
+	if subnet.Gateway != "" {
+		d.Set("gateway", subnet.Gateway)
+	}
 
-			d.Set("class_parameters", computedClassParameters)
+	d.Set("class_parameters", classparamstoattr(d.Get("class_parameters"), subnet.ClassParameters))
 
-			return []*schema.ResourceData{d}, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to import IP subnet (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import IP subnet (oid): %s\n", d.Id())
-		}
+	return nil
+}
 
+func resourceipsubnetImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourceipsubnetRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import IP subnet (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,499 @@
+package solidserver
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Create a block on the fake SOLIDserver
+func testIPBlock(t *testing.T, s *sdsclient.SOLIDserver, space string, name string, address string, size int) *schema.ResourceData {
+	return testResourceCreate(t, s, resourceipsubnet(), map[string]interface{}{
+		"space":      space,
+		"request_ip": address,
+		"size":       size,
+		"name":       name,
+		"terminal":   false,
+	})
+}
+
+// Create a terminal subnet on the fake SOLIDserver
+func testIPSubnet(t *testing.T, s *sdsclient.SOLIDserver, space string, block string, name string, size int) *schema.ResourceData {
+	return testResourceCreate(t, s, resourceipsubnet(), map[string]interface{}{
+		"space": space,
+		"block": block,
+		"size":  size,
+		"name":  name,
+	})
+}
+
+func TestIPSubnet_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	block := testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+
+	testResourceAttributes(t, block, map[string]interface{}{
+		"prefix":   "10.0.0.0/16",
+		"terminal": false,
+		"block":    "",
+	})
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":            "office",
+		"block":            "lan",
+		"size":             24,
+		"name":             "servers",
+		"gateway_offset":   -1,
+		"class_parameters": map[string]interface{}{"vlan": "10"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"prefix":           "10.0.0.0/24",
+		"netmask":          "255.255.255.0",
+		"gateway":          "10.0.0.254",
+		"block":            "lan",
+		"terminal":         true,
+		"class_parameters": map[string]interface{}{"vlan": "10"},
+	})
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space":            "office",
+		"block":            "lan",
+		"size":             24,
+		"name":             "dc-servers",
+		"class_parameters": map[string]interface{}{"vlan": "20"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "dc-servers",
+		"class_parameters": map[string]interface{}{"vlan": "20"},
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"name":  "dc-servers",
+	})
+
+	testResourceDelete(t, s, r, d)
+	testResourceDelete(t, s, r, block)
+
+	if rows := f.Rows("ip_subnet", "site_name", "office"); len(rows) != 0 {
+		t.Errorf("expected every subnet to be deleted, got %v", rows)
+	}
+}
+
+func TestIPSubnet_Allocation(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 22)
+
+	// Subnets are allocated one after the other, aligned on their size
+	expected := []string{"10.0.0.0/24", "10.0.1.0/25", "10.0.2.0/24", "10.0.1.128/26"}
+	sizes := []int{24, 25, 24, 26}
+
+	for i, size := range sizes {
+		d := testIPSubnet(t, s, "office", "lan", "subnet-"+expected[i], size)
+		testResourceAttributes(t, d, map[string]interface{}{"prefix": expected[i]})
+	}
+
+	// A requested address is used as the starting point of the search
+	d := testResourceCreate(t, s, resourceipsubnet(), map[string]interface{}{
+		"space":      "office",
+		"block":      "lan",
+		"request_ip": "10.0.3.0",
+		"size":       24,
+		"name":       "requested",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"prefix": "10.0.3.0/24"})
+
+	// The block is full
+	full := schema.TestResourceDataRaw(t, resourceipsubnet().Schema, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  24,
+		"name":  "overflow",
+	})
+
+	if err := resourceipsubnet().Create(full, s); err == nil {
+		t.Errorf("expected an error creating a subnet in a full block")
+	}
+}
+
+func TestIPSubnet_TerminalBlock(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":      "office",
+		"request_ip": "10.0.0.0",
+		"size":       16,
+		"name":       "lan",
+	})
+
+	if err := r.Create(d, s); err == nil {
+		t.Errorf("expected an error creating a terminal block")
+	}
+}
+
+func TestIPSubnet_Blocks(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan-1", "10.0.0.0", 24)
+	testIPBlock(t, s, "office", "lan-2", "10.1.0.0", 23)
+
+	// The first block is used until it is full
+	for i, expected := range []map[string]interface{}{
+		{"block": "lan-1", "prefix": "10.0.0.0/24"},
+		{"block": "lan-2", "prefix": "10.1.0.0/24"},
+		{"block": "lan-2", "prefix": "10.1.1.0/24"},
+	} {
+		d := testResourceCreate(t, s, r, map[string]interface{}{
+			"space":  "office",
+			"blocks": []interface{}{"lan-1", "lan-2"},
+			"size":   24,
+			"name":   "servers-" + strconv.Itoa(i),
+		})
+
+		testResourceAttributes(t, d, expected)
+	}
+
+	// Every block is full
+	full := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":  "office",
+		"blocks": []interface{}{"lan-1", "lan-2"},
+		"size":   24,
+		"name":   "overflow",
+	})
+
+	if err := r.Create(full, s); err == nil || !strings.Contains(err.Error(), "lan-1, lan-2") {
+		t.Errorf("expected an error listing the full blocks, got: %v", err)
+	}
+}
+
+func TestIPSubnet_BlockClassParameters(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+
+	for _, block := range []map[string]interface{}{
+		{"name": "us-1", "request_ip": "10.0.0.0", "region": "us"},
+		{"name": "eu-2", "request_ip": "10.2.0.0", "region": "eu"},
+		{"name": "eu-1", "request_ip": "10.1.0.0", "region": "eu"},
+	} {
+		testResourceCreate(t, s, r, map[string]interface{}{
+			"space":            "office",
+			"request_ip":       block["request_ip"],
+			"size":             24,
+			"name":             block["name"],
+			"terminal":         false,
+			"class_parameters": map[string]interface{}{"region": block["region"]},
+		})
+	}
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space":                  "office",
+		"block_class_parameters": map[string]interface{}{"region": "eu"},
+		"size":                   24,
+		"name":                   "servers",
+	})
+
+	if block := d.Get("block").(string); block != "eu-1" && block != "eu-2" {
+		t.Errorf("expected the subnet to be created in an eu block, got: %s", block)
+	}
+
+	// No block matches the selector
+	none := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"space":                  "office",
+		"block_class_parameters": map[string]interface{}{"region": "ap"},
+		"size":                   24,
+		"name":                   "overflow",
+	})
+
+	if err := r.Create(none, s); err == nil {
+		t.Errorf("expected an error without any matching block")
+	}
+}
+
+func TestIPSubnet_Gateway(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+
+	config := map[string]interface{}{
+		"space":          "office",
+		"block":          "lan",
+		"size":           24,
+		"name":           "servers",
+		"gateway_offset": 1,
+	}
+
+	d := testResourceCreate(t, s, r, config)
+	testResourceAttributes(t, d, map[string]interface{}{"gateway": "10.0.0.1"})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.1"); len(rows) != 1 || rows[0]["name"] != "gateway" {
+		t.Errorf("expected the gateway to be reserved on creation, got %v", rows)
+	}
+
+	// The gateway moves in place, its new address is reserved
+	config["gateway_offset"] = -1
+	d = testResourceUpdate(t, s, r, d, config)
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.1"); len(rows) != 0 {
+		t.Errorf("expected the gateway reserved on creation to be released, got %v", rows)
+	}
+
+	testResourceAttributes(t, d, map[string]interface{}{"gateway": "10.0.0.254"})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.254"); len(rows) != 1 || rows[0]["name"] != "gateway" {
+		t.Errorf("expected the new gateway to be reserved, got %v", rows)
+	}
+
+	// The gateway can't move to an address already used
+	testResourceCreate(t, s, resourceipaddress(), map[string]interface{}{
+		"space":      "office",
+		"subnet":     "servers",
+		"request_ip": "10.0.0.2",
+		"name":       "www",
+	})
+
+	config["gateway_offset"] = 2
+	if _, err := r.Apply(d.State(), testResourceDiff(t, s, r, d, config), s); err == nil {
+		t.Errorf("expected an error moving the gateway to a used address")
+	}
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.2"); len(rows) != 1 || rows[0]["name"] != "www" {
+		t.Errorf("expected the used address to be kept, got %v", rows)
+	}
+
+	// The previous gateway is released
+	config["gateway_offset"] = 3
+	d = testResourceUpdate(t, s, r, d, config)
+
+	testResourceAttributes(t, d, map[string]interface{}{"gateway": "10.0.0.3"})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.254"); len(rows) != 0 {
+		t.Errorf("expected the previous gateway to be released, got %v", rows)
+	}
+
+	// A previous gateway which can't be released is reported
+	deleteaddress := f.Handle("rest/ip_delete", func(method string, p url.Values) sdsfake.Answer {
+		return sdsfake.Error(http.StatusBadRequest, 2020, "IP address %s is locked", p.Get("hostaddr"))
+	})
+
+	config["gateway_offset"] = 4
+	locked, err := r.Apply(d.State(), testResourceDiff(t, s, r, d, config), s)
+
+	if err == nil || !strings.Contains(err.Error(), "10.0.0.3") {
+		t.Errorf("expected an error releasing the previous gateway, got %v", err)
+	}
+
+	testResourceAttributes(t, r.Data(locked), map[string]interface{}{"gateway": "10.0.0.4"})
+
+	f.Handle("rest/ip_delete", deleteaddress)
+
+	f.Remove("ip_address", "hostaddr", "10.0.0.3")
+	d = r.Data(locked)
+
+	// Without gateway, the previous one is released
+	config["gateway_offset"] = 0
+	d = testResourceUpdate(t, s, r, d, config)
+
+	testResourceAttributes(t, d, map[string]interface{}{"gateway": ""})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.4"); len(rows) != 0 {
+		t.Errorf("expected the previous gateway to be released, got %v", rows)
+	}
+
+	if subnet, err := s.GetSubnet(context.Background(), d.Id()); err != nil || subnet.Gateway != "" {
+		t.Errorf("expected the subnet not to have any gateway, got %v (%v)", subnet, err)
+	}
+}
+
+func TestIPSubnet_GatewayClassParameter(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+
+	// A gateway set through the class parameters, without gateway offset
+	config := map[string]interface{}{
+		"space":            "office",
+		"block":            "lan",
+		"size":             24,
+		"name":             "servers",
+		"class_parameters": map[string]interface{}{"gateway": "10.0.0.1"},
+	}
+
+	d := testResourceCreate(t, s, r, config)
+
+	testResourceCreate(t, s, resourceipaddress(), map[string]interface{}{
+		"space":      "office",
+		"subnet":     "servers",
+		"request_ip": "10.0.0.1",
+		"name":       "router",
+	})
+
+	// Updating the subnet keeps its gateway and the address it points to
+	config["name"] = "dmz"
+	d = testResourceUpdate(t, s, r, d, config)
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "dmz",
+		"gateway":          "10.0.0.1",
+		"class_parameters": map[string]interface{}{"gateway": "10.0.0.1"},
+	})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.1"); len(rows) != 1 || rows[0]["name"] != "router" {
+		t.Errorf("expected the router address to be kept, got %v", rows)
+	}
+
+	// Setting a gateway offset never releases the address of the class parameter
+	config["class_parameters"] = map[string]interface{}{}
+	config["gateway_offset"] = -1
+	d = testResourceUpdate(t, s, r, d, config)
+
+	testResourceAttributes(t, d, map[string]interface{}{"gateway": "10.0.0.254"})
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.1"); len(rows) != 1 || rows[0]["name"] != "router" {
+		t.Errorf("expected the router address to be kept, got %v", rows)
+	}
+
+	if rows := f.Rows("ip_address", "hostaddr", "10.0.0.254"); len(rows) != 1 || rows[0]["name"] != "gateway" {
+		t.Errorf("expected the new gateway to be reserved, got %v", rows)
+	}
+}
+
+func TestIPSubnet_ReservedRanges(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  24,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "routers", "offset": 1, "size": 5},
+			map[string]interface{}{"name": "dhcp", "offset": -20, "size": 20},
+		},
+	})
+
+	if rows := f.Rows("ip_address", "name", "routers"); len(rows) != 5 {
+		t.Errorf("expected 5 addresses reserved for the routers, got %v", rows)
+	}
+
+	if rows := f.Rows("ip_address", "name", "dhcp"); len(rows) != 20 ||
+		len(f.Rows("ip_address", "hostaddr", "10.0.0.235")) != 1 || len(f.Rows("ip_address", "hostaddr", "10.0.0.254")) != 1 {
+		t.Errorf("expected 10.0.0.235 to 10.0.0.254 to be reserved for the DHCP, got %v", rows)
+	}
+
+	// Reserved addresses are never found free
+	address := testResourceCreate(t, s, resourceipaddress(), map[string]interface{}{
+		"space":  "office",
+		"subnet": "servers",
+		"name":   "www",
+	})
+
+	testResourceAttributes(t, address, map[string]interface{}{"address": "10.0.0.6"})
+
+	// Ranges added later are reserved in place
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  24,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "vips", "offset": 10, "size": 2},
+		},
+	})
+
+	if rows := f.Rows("ip_address", "name", "vips"); len(rows) != 2 {
+		t.Errorf("expected 2 addresses reserved for the VIPs, got %v", rows)
+	}
+
+	// A range overlapping a used address is rolled back
+	overlapping := map[string]interface{}{
+		"space": "office",
+		"block": "lan",
+		"size":  24,
+		"name":  "servers",
+		"reserved_ranges": []interface{}{
+			map[string]interface{}{"name": "nat", "offset": 20, "size": 1},
+			map[string]interface{}{"name": "nat", "offset": 5, "size": 2},
+		},
+	}
+
+	if _, err := r.Apply(d.State(), testResourceDiff(t, s, r, d, overlapping), s); err == nil {
+		t.Errorf("expected an error reserving a range over a used address")
+	}
+
+	if rows := f.Rows("ip_address", "name", "nat"); len(rows) != 0 {
+		t.Errorf("expected the partially reserved range to be released, got %v", rows)
+	}
+
+	testResourceDelete(t, s, r, d)
+}
+
+func TestIPSubnet_ReservedRangesOutside(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceipsubnet()
+
+	testIPSpace(t, s, "office")
+	testIPBlock(t, s, "office", "lan", "10.0.0.0", 16)
+
+	for i, ranges := range [][]interface{}{
+		{map[string]interface{}{"name": "too-far", "offset": 250, "size": 10}},
+		{map[string]interface{}{"name": "too-large", "offset": -20, "size": 21}},
+		{map[string]interface{}{"name": "empty", "offset": 1, "size": 0}},
+	} {
+		d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+			"space":           "office",
+			"block":           "lan",
+			"size":            24,
+			"name":            "servers-" + strconv.Itoa(i),
+			"reserved_ranges": ranges,
+		})
+
+		if err := r.Create(d, s); err == nil {
+			t.Errorf("expected an error reserving %v", ranges)
+		}
+	}
+
+	if n := f.Count("rest/ip_add"); n != 0 {
+		t.Errorf("expected no address reserved, got %d", n)
+	}
+}
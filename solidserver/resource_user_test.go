@@ -8,9 +8,8 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
-	// "github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
-
 	"github.com/satori/go.uuid"
 )
 
@@ -257,6 +256,67 @@ func TestAccUser_ModifyUserParams(t *testing.T) {
 	})
 }
 
+func TestUser_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceuser()
+
+	netops := testResourceCreate(t, s, resourceusergroup(), map[string]interface{}{"name": "netops"})
+	secops := testResourceCreate(t, s, resourceusergroup(), map[string]interface{}{"name": "secops"})
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"login":      "jdoe",
+		"password":   "s3cr3t",
+		"groups":     []interface{}{netops.Id(), secops.Id()},
+		"first_name": "John",
+		"last_name":  "Doe",
+		"email":      "jdoe@example.com",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"login":      "jdoe",
+		"first_name": "John",
+		"last_name":  "Doe",
+		"email":      "jdoe@example.com",
+	})
+
+	if groups := d.Get("groups").(*schema.Set); groups.Len() != 2 || !groups.Contains(netops.Id()) || !groups.Contains(secops.Id()) {
+		t.Errorf("expected the user to be a member of both groups, got %v", groups.List())
+	}
+
+	// The password is never returned by the SOLIDserver
+	if rows := f.Rows("user", "usr_id", d.Id()); len(rows) != 1 || rows[0]["usr_password"] != "" {
+		t.Errorf("unexpected user: %v", rows)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"login":    "jdoe",
+		"password": "s3cr3t",
+		"groups":   []interface{}{netops.Id()},
+		"email":    "john.doe@example.com",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"email": "john.doe@example.com"})
+
+	if groups := d.Get("groups").(*schema.Set); groups.Len() != 1 || !groups.Contains(netops.Id()) {
+		t.Errorf("expected the user to be removed from the secops group, got %v", groups.List())
+	}
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"login": "jdoe",
+		"email": "john.doe@example.com",
+	})
+
+	id := d.Id()
+	testResourceDelete(t, s, r, d)
+
+	if rows := f.Rows("group_user", "usr_id", id); len(rows) != 0 {
+		t.Errorf("expected the group memberships to be deleted, got %v", rows)
+	}
+}
+
 func Config_TestAccUser_GetGroupAdmin() string {
 	return fmt.Sprintf(`
     data "solidserver_usergroup" "admin" {
@@ -91,6 +91,38 @@ func TestAccUserGroup_GetAdmin(t *testing.T) {
 	})
 }
 
+func TestUserGroup_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourceusergroup()
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"name":        "netops",
+		"description": "Network operations",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":        "netops",
+		"description": "Network operations",
+	})
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"name":        "netops",
+		"description": "Network team",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"description": "Network team"})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"name":        "netops",
+		"description": "Network team",
+	})
+
+	testResourceDelete(t, s, r, d)
+}
+
 func Config_TestAccUserGroup_Create01(group string) string {
 	return fmt.Sprintf(`
     resource "solidserver_usergroup" "t_group_01" {
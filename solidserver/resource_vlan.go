@@ -1,12 +1,9 @@
 package solidserver
 
 import (
-	"encoding/json"
-	"fmt"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
 	"github.com/hashicorp/terraform/helper/schema"
 	"log"
-	"net/url"
-	"strconv"
 )
 
 func resourcevlan() *schema.Resource {
@@ -20,6 +17,8 @@ func resourcevlan() *schema.Resource {
 			State: resourcevlanImportState,
 		},
 
+		Timeouts: resourcetimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"vlan_domain": {
 				Type:        schema.TypeString,
@@ -65,287 +64,106 @@ func resourcevlan() *schema.Resource {
 }
 
 func resourcevlanExists(d *schema.ResourceData, meta interface{}) (bool, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("vlmvlan_id", d.Id())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
 	log.Printf("[DEBUG] Checking existence of vlan (oid): %s\n", d.Id())
 
-	// Sending read request
-	resp, body, err := s.Request("get", "rest/vlmvlan_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			return true, nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to find vlan (oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find vlan (oid): %s\n", d.Id())
-		}
-
-		// Unset local ID
-		d.SetId("")
-	}
+	_, err := s.GetVLAN(ctx, d.Id())
 
-	// Reporting a failure
-	return false, err
+	return resourceexists(d, err)
 }
 
 func resourcevlanCreate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutCreate)
+	defer cancel()
 
-	var vlanIDs []string = nil
+	vlan, err := s.CreateVLAN(ctx, sdsclient.VLANSpec{
+		Domain: d.Get("vlan_domain").(string),
+		VLANID: d.Get("request_id").(int),
+		Name:   d.Get("name").(string),
+	})
 
-	// Determining if a VLAN ID was submitted in or if we should get one from the VLAN Manager
-	if d.Get("request_id").(int) > 0 {
-		vlanIDs = []string{d.Get("request_id").(string)}
-	} else {
-		var vlanErr error = nil
-
-		vlanIDs, vlanErr = vlanidfindfree(d.Get("vlan_domain").(string), meta)
-
-		if vlanErr != nil {
-			// Reporting a failure
-			return vlanErr
-		}
+	if err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	for i := 0; i < len(vlanIDs); i++ {
-		// Building parameters
-		parameters := url.Values{}
-		parameters.Add("add_flag", "new_only")
-		parameters.Add("vlmdomain_name", d.Get("vlan_domain").(string))
-		parameters.Add("vlmvlan_vlan_id", vlanIDs[i])
-		parameters.Add("vlmvlan_name", d.Get("name").(string))
-		//parameters.Add("hostdev_class_name", d.Get("class").(string))
-		//parameters.Add("hostdev_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
-
-		// Sending creation request
-		resp, body, err := s.Request("post", "rest/vlm_vlan_add", &parameters)
-
-		if err == nil {
-			var buf [](map[string]interface{})
-			json.Unmarshal([]byte(body), &buf)
-
-			// Checking the answer
-			if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-				if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-					log.Printf("[DEBUG] SOLIDServer - Created vlan (oid): %s\n", oid)
+	d.Set("vlan_id", vlan.VLANID)
+	d.SetId(vlan.ID)
 
-					vnid, _ := strconv.Atoi(vlanIDs[i])
-					d.Set("vlan_id", vnid)
-					d.SetId(oid)
-
-					return nil
-				}
-			} else {
-				log.Printf("[DEBUG] SOLIDServer - Failed vlan registration, trying another one.\n")
-			}
-		} else {
-			// Reporting a failure
-			return err
-		}
-	}
-
-	// Reporting a failure
-	return fmt.Errorf("SOLIDServer - Unable to create vlan: %s\n", d.Get("name").(string))
+	return nil
 }
 
 func resourcevlanUpdate(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("vlmvlan_id", d.Id())
-	parameters.Add("add_flag", "edit_only")
-	parameters.Add("vlmvlan_vlan_id", d.Get("vlan_id").(string))
-	parameters.Add("vlmvlan_name", d.Get("name").(string))
-	//parameters.Add("hostdev_class_name", d.Get("class").(string))
-	//parameters.Add("hostdev_class_parameters", urlfromclassparams(d.Get("class_parameters")).Encode())
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutUpdate)
+	defer cancel()
 
-	// Sending the update request
-	resp, body, err := s.Request("put", "rest/vlm_vlan_add", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
-			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
-				log.Printf("[DEBUG] SOLIDServer - Updated vlan (oid): %s\n", oid)
-				d.SetId(oid)
-				return nil
-			}
-		}
+	vlan, err := s.UpdateVLAN(ctx, d.Id(), sdsclient.VLANSpec{
+		Domain: d.Get("vlan_domain").(string),
+		VLANID: d.Get("vlan_id").(int),
+		Name:   d.Get("name").(string),
+	})
 
+	if err != nil {
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to update vlan: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	d.SetId(vlan.ID)
+
+	return nil
 }
 
 func resourcevlanDelete(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("vlmvlan_id", d.Id())
-
-	// Sending the deletion request
-	resp, body, err := s.Request("delete", "rest/vlm_vlan_delete", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode != 204 && len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Reporting a failure
-				return fmt.Errorf("SOLIDServer - Unable to delete vlan : %s (%s)", d.Get("name"), errMsg)
-			}
-		}
-
-		// Log deletion
-		log.Printf("[DEBUG] SOLIDServer - Deleted vlan (oid): %s\n", d.Id())
-
-		// Unset local ID
-		d.SetId("")
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutDelete)
+	defer cancel()
 
-		// Reporting a success
-		return nil
+	if err := s.DeleteVLAN(ctx, d.Id()); err != nil {
+		// Reporting a failure
+		return err
 	}
 
-	// Reporting a failure
-	return err
+	// Unset local ID
+	d.SetId("")
+
+	// Reporting a success
+	return nil
 }
 
 func resourcevlanRead(d *schema.ResourceData, meta interface{}) error {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("vlmvlan_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/vlmvlan_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			vnid, _ := strconv.Atoi(buf[0]["vlmvlan_vlan_id"].(string))
-
-			d.Set("name", buf[0]["vlmvlan_name"].(string))
-			d.Set("vlan_id", vnid)
-			//d.Set("class",buf[0]["hostdev_class_name"].(string))
+	s := meta.(*sdsclient.SOLIDserver)
+	ctx, cancel := resourcecontext(d, meta, schema.TimeoutRead)
+	defer cancel()
 
-			// Updating local class_parameters
-			//currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			//retrievedClassParameters, _ := url.ParseQuery(buf[0]["hostdev_class_parameters"].(string))
-			//computedClassParameters := map[string]string{}
-
-			//for ck, _ := range currentClassParameters {
-			//  if rv, rvExist := retrievedClassParameters[ck]; (rvExist) {
-			//    computedClassParameters[ck] = rv[0]
-			//  } else {
-			//    computedClassParameters[ck] = ""
-			//  }
-			//}
-
-			//d.Set("class_parameters", computedClassParameters)
-
-			return nil
-		}
-
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				// Log the error
-				log.Printf("[DEBUG] SOLIDServer - Unable to find vlan: %s (%s)\n", d.Get("name"), errMsg)
-			}
-		} else {
-			// Log the error
-			log.Printf("[DEBUG] SOLIDServer - Unable to find vlan (oid): %s\n", d.Id())
-		}
+	vlan, err := s.GetVLAN(ctx, d.Id())
 
+	if err != nil {
 		// Do not unset the local ID to avoid inconsistency
 
 		// Reporting a failure
-		return fmt.Errorf("SOLIDServer - Unable to find vlan: %s\n", d.Get("name").(string))
+		return err
 	}
 
-	// Reporting a failure
-	return err
-}
-
-func resourcevlanImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	s := meta.(*SOLIDserver)
-
-	// Building parameters
-	parameters := url.Values{}
-	parameters.Add("vlmvlan_id", d.Id())
-
-	// Sending the read request
-	resp, body, err := s.Request("get", "rest/vlmvlan_info", &parameters)
-
-	if err == nil {
-		var buf [](map[string]interface{})
-		json.Unmarshal([]byte(body), &buf)
-
-		// Checking the answer
-		if resp.StatusCode == 200 && len(buf) > 0 {
-			vnid, _ := strconv.Atoi(buf[0]["vlmvlan_vlan_id"].(string))
-
-			d.Set("name", buf[0]["vlmvlan_name"].(string))
-			d.Set("vlan_id", vnid)
-
-			//d.Set("class",buf[0]["hostdev_class_name"].(string))
-
-			// Updating local class_parameters
-			//currentClassParameters := d.Get("class_parameters").(map[string]interface{})
-			//retrievedClassParameters, _ := url.ParseQuery(buf[0]["hostdev_class_parameters"].(string))
-			//computedClassParameters := map[string]string{}
-
-			//for ck, _ := range currentClassParameters {
-			//  if rv, rvExist := retrievedClassParameters[ck]; (rvExist) {
-			//    computedClassParameters[ck] = rv[0]
-			//  } else {
-			//    computedClassParameters[ck] = ""
-			//  }
-			//}
-
-			//d.Set("class_parameters", computedClassParameters)
+	if vlan.Domain != "" {
+		d.Set("vlan_domain", vlan.Domain)
+	}
 
-			return []*schema.ResourceData{d}, nil
-		}
+	d.Set("name", vlan.Name)
+	d.Set("vlan_id", vlan.VLANID)
 
-		if len(buf) > 0 {
-			if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
-				log.Printf("[DEBUG] SOLIDServer - Unable to import vlan(oid): %s (%s)\n", d.Id(), errMsg)
-			}
-		} else {
-			log.Printf("[DEBUG] SOLIDServer - Unable to find and import vlan (oid): %s\n", d.Id())
-		}
+	return nil
+}
 
+func resourcevlanImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := resourcevlanRead(d, meta); err != nil {
 		// Reporting a failure
-		return nil, fmt.Errorf("SOLIDServer - Unable to find and import vlan (oid): %s\n", d.Id())
+		return nil, err
 	}
 
-	// Reporting a failure
-	return nil, err
+	return []*schema.ResourceData{d}, nil
 }
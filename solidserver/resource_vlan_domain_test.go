@@ -0,0 +1,53 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestVlanDomain_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcevlandomain()
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"name":             "campus",
+		"vxlan":            true,
+		"class_parameters": map[string]interface{}{"site": "paris"},
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"name":             "campus",
+		"class_parameters": map[string]interface{}{"site": "paris"},
+	})
+
+	if rows := f.Rows("vlmdomain", "vlmdomain_id", d.Id()); len(rows) != 1 || rows[0]["support_vxlan"] != "1" {
+		t.Errorf("expected the domain to support vxlan, got %v", rows)
+	}
+
+	// Domain names are unique
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"name": "campus"})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate vlan domain, got: %v", err)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"name":  "campus",
+		"vxlan": true,
+		"class": "datacenter",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{"class": "datacenter"})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"name":  "campus",
+		"class": "datacenter",
+	})
+
+	testResourceDelete(t, s, r, d)
+}
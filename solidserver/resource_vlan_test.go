@@ -0,0 +1,77 @@
+package solidserver
+
+import (
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestVlan_Lifecycle(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcevlan()
+
+	testResourceCreate(t, s, resourcevlandomain(), map[string]interface{}{"name": "campus"})
+
+	d := testResourceCreate(t, s, r, map[string]interface{}{
+		"vlan_domain": "campus",
+		"request_id":  100,
+		"name":        "servers",
+	})
+
+	testResourceAttributes(t, d, map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "servers",
+	})
+
+	// A vlan ID can only be used once in a domain
+	duplicate := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"vlan_domain": "campus",
+		"request_id":  100,
+		"name":        "duplicate",
+	})
+
+	if err := r.Create(duplicate, s); !sdsclient.IsConflict(err) {
+		t.Errorf("expected a conflict creating a duplicate vlan, got: %v", err)
+	}
+
+	d = testResourceUpdate(t, s, r, d, map[string]interface{}{
+		"vlan_domain": "campus",
+		"request_id":  100,
+		"name":        "dc-servers",
+	})
+
+	imported := testResourceImport(t, s, r, d.Id())
+
+	testResourceAttributes(t, imported, map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "dc-servers",
+	})
+
+	testResourceDelete(t, s, r, d)
+}
+
+func TestVlan_Allocation(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	r := resourcevlan()
+
+	testResourceCreate(t, s, resourcevlandomain(), map[string]interface{}{"name": "campus"})
+
+	testResourceCreate(t, s, r, map[string]interface{}{
+		"vlan_domain": "campus",
+		"request_id":  2,
+		"name":        "requested",
+	})
+
+	// Free vlan IDs are taken from the free ranges of the domain
+	for _, expected := range []int{1, 3, 4} {
+		d := testResourceCreate(t, s, r, map[string]interface{}{
+			"vlan_domain": "campus",
+			"name":        "vlan",
+		})
+
+		testResourceAttributes(t, d, map[string]interface{}{"vlan_id": expected})
+	}
+}
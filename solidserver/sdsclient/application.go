@@ -0,0 +1,128 @@
+package sdsclient
+
+import (
+	"context"
+	"log"
+	"net/url"
+)
+
+// Application is an application of the Application Manager
+type Application struct {
+	ID              string
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// ApplicationSpec describes an application to create or update
+type ApplicationSpec struct {
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// Build the parameters shared by the creation and the update of an application
+func applicationparameters(spec ApplicationSpec) url.Values {
+	parameters := url.Values{}
+	parameters.Add("appapplication_name", spec.Name)
+	parameters.Add("appapplication_class_name", spec.Class)
+	parameters.Add("appapplication_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	return parameters
+}
+
+// CreateApplication creates an application
+func (s *SOLIDserver) CreateApplication(ctx context.Context, spec ApplicationSpec) (*Application, error) {
+	if err := s.Requires(CapabilityApplications); err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	// Building parameters
+	parameters := applicationparameters(spec)
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/app_application_add", &parameters, nil, "SOLIDServer - Unable to create application: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created application (oid): %s\n", oid)
+
+	return &Application{ID: oid, Name: spec.Name, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// GetApplication reads an application from its oid
+func (s *SOLIDserver) GetApplication(ctx context.Context, id string) (*Application, error) {
+	if err := s.Requires(CapabilityApplications); err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("appapplication_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/app_application_info", &parameters, "SOLIDServer - Unable to find application (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Application{
+		ID:              id,
+		Name:            rowstring(row, "appapplication_name"),
+		Class:           rowstring(row, "appapplication_class_name"),
+		ClassParameters: rowclassparams(row, "appapplication_class_parameters"),
+	}, nil
+}
+
+// UpdateApplication updates the class and class parameters of an application
+func (s *SOLIDserver) UpdateApplication(ctx context.Context, id string, spec ApplicationSpec) (*Application, error) {
+	if err := s.Requires(CapabilityApplications); err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	// Building parameters
+	parameters := applicationparameters(spec)
+	parameters.Add("appapplication_id", id)
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/app_application_add", &parameters, nil, "SOLIDServer - Unable to update application: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated application (oid): %s\n", oid)
+
+	return &Application{ID: oid, Name: spec.Name, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// DeleteApplication deletes an application
+func (s *SOLIDserver) DeleteApplication(ctx context.Context, id string) error {
+	if err := s.Requires(CapabilityApplications); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("appapplication_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/app_application_delete", &parameters, "SOLIDServer - Unable to delete application (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted application (oid): %s\n", id)
+
+	return nil
+}
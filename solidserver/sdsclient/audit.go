@@ -0,0 +1,229 @@
+package sdsclient
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Value replacing redacted parameters in the audit log
+const auditRedacted = "<redacted>"
+
+// Patterns of parameter names always redacted from the audit log
+var auditSecretPatterns = []string{"password", "passwd", "secret", "token", "private_key"}
+
+// AuditLog appends a JSON line describing every API call to a file
+// The file is reopened when it is rotated, writes are serialized
+// It must be closed once the provider is done, see Shutdown
+type AuditLog struct {
+	mutex   sync.Mutex
+	path    string
+	file    *os.File
+	secrets map[string]bool
+}
+
+// An API call, as written to the audit log
+type auditEntry struct {
+	Time       string              `json:"time"`
+	Host       string              `json:"host"`
+	Method     string              `json:"method"`
+	Service    string              `json:"service"`
+	Parameters map[string][]string `json:"parameters"`
+	Status     int                 `json:"status,omitempty"`
+	LatencyMs  int64               `json:"latency_ms"`
+	Attempts   int                 `json:"attempts"`
+	Oid        string              `json:"oid,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// Open an audit log appending to path
+// Parameters named after one of the secrets, including class parameters, are redacted
+func NewAuditLog(path string, secrets []string) (*AuditLog, error) {
+	a := &AuditLog{
+		path:    path,
+		secrets: map[string]bool{},
+	}
+
+	for _, secret := range secrets {
+		if secret = strings.ToLower(strings.TrimSpace(secret)); secret != "" {
+			a.secrets[secret] = true
+		}
+	}
+
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *AuditLog) open() error {
+	file, err := os.OpenFile(a.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	a.file = file
+
+	return nil
+}
+
+// Reopen the file if it was moved or removed by a log rotation
+// The rotated file is only closed once the new one is open
+func (a *AuditLog) reopen() error {
+	current, err := a.file.Stat()
+
+	if err != nil {
+		return err
+	}
+
+	if onDisk, statErr := os.Stat(a.path); statErr == nil && os.SameFile(current, onDisk) {
+		return nil
+	}
+
+	rotated := a.file
+
+	if err := a.open(); err != nil {
+		return err
+	}
+
+	return rotated.Close()
+}
+
+// Close the audit log file, the following API calls are no longer recorded
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+
+	err := a.file.Close()
+	a.file = nil
+
+	return err
+}
+
+// Check if a parameter must be redacted
+func (a *AuditLog) secret(name string) bool {
+	name = strings.ToLower(name)
+
+	if a.secrets[name] {
+		return true
+	}
+
+	for _, pattern := range auditSecretPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy the parameters of an API call, redacting secrets
+// Class parameters are url encoded in a single parameter, they are redacted one by one
+func (a *AuditLog) redact(parameters *url.Values) map[string][]string {
+	redacted := map[string][]string{}
+
+	for name, values := range *parameters {
+		switch {
+		case a.secret(name):
+			redacted[name] = []string{auditRedacted}
+		case strings.HasSuffix(name, "class_parameters"):
+			redacted[name] = make([]string, len(values))
+			for i, value := range values {
+				redacted[name][i] = a.redactclassparameters(value)
+			}
+		default:
+			redacted[name] = append([]string{}, values...)
+		}
+	}
+
+	return redacted
+}
+
+func (a *AuditLog) redactclassparameters(encoded string) string {
+	classParameters, err := url.ParseQuery(encoded)
+
+	if err != nil {
+		return auditRedacted
+	}
+
+	for name := range classParameters {
+		if a.secret(name) {
+			classParameters[name] = []string{auditRedacted}
+		}
+	}
+
+	return classParameters.Encode()
+}
+
+// Record an API call
+func (a *AuditLog) record(host string, method string, service string, parameters *url.Values, resp *http.Response, body string, err error, start time.Time, attempts int) {
+	if a == nil {
+		return
+	}
+
+	entry := auditEntry{
+		Time:       start.UTC().Format(time.RFC3339Nano),
+		Host:       host,
+		Method:     method,
+		Service:    service,
+		Parameters: a.redact(parameters),
+		LatencyMs:  int64(time.Since(start) / time.Millisecond),
+		Attempts:   attempts,
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if resp != nil {
+		entry.Status = resp.StatusCode
+
+		var buf [](map[string]interface{})
+
+		if json.Unmarshal([]byte(body), &buf) == nil && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				entry.Oid = oid
+			}
+		}
+	}
+
+	line, jsonErr := json.Marshal(entry)
+
+	if jsonErr != nil {
+		log.Printf("[WARN] SOLIDServer - Unable to record %s on %s in the audit log (%s)\n", method, service, jsonErr)
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.file == nil {
+		log.Printf("[DEBUG] SOLIDServer - Not recording %s on %s, the audit log %s is closed\n", method, service, a.path)
+		return
+	}
+
+	if rotateErr := a.reopen(); rotateErr != nil {
+		log.Printf("[WARN] SOLIDServer - Unable to reopen the audit log %s (%s)\n", a.path, rotateErr)
+		return
+	}
+
+	// A single write keeps lines whole when several processes share the file
+	if _, writeErr := a.file.Write(append(line, '\n')); writeErr != nil {
+		log.Printf("[WARN] SOLIDServer - Unable to write to the audit log %s (%s)\n", a.path, writeErr)
+	}
+}
@@ -0,0 +1,221 @@
+package sdsclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testAuditLogEntries(t *testing.T, path string) []auditEntry {
+	file, err := os.Open(path)
+
+	if err != nil {
+		t.Fatalf("unable to open the audit log: %s", err)
+	}
+	defer file.Close()
+
+	entries := []auditEntry{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var entry auditEntry
+
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %s", scanner.Text(), err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestAuditLog_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `[{"ret_oid": "42"}]`)
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	audit, err := NewAuditLog(path, []string{"vault_ref"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer audit.Close()
+
+	s := &SOLIDserver{BaseUrl: server.URL, Username: "ipmadmin", Password: "admin", Retry: DefaultRetryPolicy(), limiter: NewLimiter(0, 0), audit: audit}
+	s.initClient()
+
+	classParameters := url.Values{}
+	classParameters.Add("owner", "netops")
+	classParameters.Add("vault_ref", "s3cr3t")
+	classParameters.Add("api_token", "t0k3n")
+
+	parameters := url.Values{}
+	parameters.Add("usr_login", "jdoe")
+	parameters.Add("usr_password", "hunter2")
+	parameters.Add("usr_class_parameters", classParameters.Encode())
+
+	s.Request(context.Background(), "post", "rest/user_add", &parameters)
+
+	raw, _ := ioutil.ReadFile(path)
+
+	for _, secret := range []string{"hunter2", "s3cr3t", "t0k3n", "admin"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("expected %s to be redacted from %s", secret, raw)
+		}
+	}
+
+	entries := testAuditLogEntries(t, path)
+
+	if len(entries) != 1 {
+		t.Fatalf("expected a single entry, got %d", len(entries))
+	}
+
+	entry := entries[0]
+
+	if entry.Method != "post" || entry.Service != "rest/user_add" || entry.Status != 201 || entry.Oid != "42" || entry.Attempts != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	if entry.Parameters["usr_login"][0] != "jdoe" || entry.Parameters["usr_password"][0] != auditRedacted {
+		t.Errorf("unexpected parameters: %v", entry.Parameters)
+	}
+
+	if !strings.Contains(entry.Parameters["usr_class_parameters"][0], "owner=netops") {
+		t.Errorf("expected non secret class parameters to be kept: %v", entry.Parameters)
+	}
+
+	// The parameters of the call are left untouched
+	if parameters.Get("usr_password") != "hunter2" {
+		t.Errorf("expected the parameters of the call not to be redacted")
+	}
+}
+
+func TestAuditLog_Rotation(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	audit, err := NewAuditLog(path, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer audit.Close()
+
+	parameters := url.Values{}
+	audit.record("sds.local", "get", "rest/ip_site_list", &parameters, nil, "", nil, time.Now(), 1)
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("unable to rotate: %s", err)
+	}
+
+	audit.record("sds.local", "get", "rest/ip_site_list", &parameters, nil, "", nil, time.Now(), 1)
+
+	if n := len(testAuditLogEntries(t, path+".1")); n != 1 {
+		t.Errorf("expected 1 entry in the rotated file, got %d", n)
+	}
+
+	if n := len(testAuditLogEntries(t, path)); n != 1 {
+		t.Errorf("expected 1 entry in the new file, got %d", n)
+	}
+}
+
+func TestAuditLog_ConcurrentWriters(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	parameters := url.Values{}
+	parameters.Add("site_name", strings.Repeat("x", 2048))
+
+	var wg sync.WaitGroup
+
+	// Two audit logs on the same file, as two providers would
+	for w := 0; w < 2; w++ {
+		audit, err := NewAuditLog(path, nil)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer audit.Close()
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				audit.record("sds.local", "get", "rest/ip_site_list", &parameters, nil, "", nil, time.Now(), 1)
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	if n := len(testAuditLogEntries(t, path)); n != 40 {
+		t.Errorf("expected 40 entries, got %d", n)
+	}
+}
+
+func TestAuditLog_ClosedOnShutdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	audit, err := NewAuditLog(path, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s, err := NewSOLIDserver("", "ipmadmin", "admin", false, "", SOLIDserverOptions{BaseURL: server.URL, AuditLog: audit})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("unable to rotate: %s", err)
+	}
+
+	// Reopens the audit log after the rotation
+	s.Request(context.Background(), "get", "rest/ip_site_list", &url.Values{})
+
+	Shutdown()
+
+	if audit.file != nil {
+		t.Fatalf("expected the audit log to be closed on shutdown")
+	}
+
+	// The calls made after the shutdown are not recorded
+	s.Request(context.Background(), "get", "rest/ip_site_list", &url.Values{})
+
+	if n := len(testAuditLogEntries(t, path)); n != 1 {
+		t.Errorf("expected 1 entry in the new file, got %d", n)
+	}
+
+	if err := audit.Close(); err != nil {
+		t.Errorf("expected closing the audit log twice to succeed: %s", err)
+	}
+}
@@ -0,0 +1,140 @@
+package sdsclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Kinds of objects resolved through the lookup cache
+const (
+	cacheIPSite     = "ip_site"
+	cacheIPSubnet   = "ip_subnet"
+	cacheIP6Subnet  = "ip6_subnet"
+	cacheHostdev    = "hostdev"
+	cacheVlmdomain  = "vlmdomain"
+	cacheKeySep     = "\x00"
+	cacheKindSuffix = "\x01"
+)
+
+// lookupCache remembers the oid of objects resolved from their natural key
+// (ex: a space from its name) for the lifetime of the provider
+// Concurrent lookups of the same object share a single API call
+type lookupCache struct {
+	mutex      sync.Mutex
+	entries    map[string]string
+	inflight   map[string]*lookupCall
+	generation uint64
+}
+
+// An API call resolving an object, waited for by concurrent lookups
+type lookupCall struct {
+	done chan struct{}
+	id   string
+	err  error
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{
+		entries:  map[string]string{},
+		inflight: map[string]*lookupCall{},
+	}
+}
+
+func cachekey(kind string, key []string) string {
+	return kind + cacheKindSuffix + strings.Join(key, cacheKeySep)
+}
+
+// Return the oid of an object from the cache or from the fetch function
+// Only objects which were found are cached
+func (c *lookupCache) lookup(ctx context.Context, kind string, key []string, fetch func() (string, error)) (string, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	k := cachekey(kind, key)
+
+	c.mutex.Lock()
+
+	if id, cached := c.entries[k]; cached {
+		c.mutex.Unlock()
+		return id, nil
+	}
+
+	if call, running := c.inflight[k]; running {
+		c.mutex.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		// The shared call was abandoned by its caller, not by this one
+		if ctx.Err() == nil && (iserror(call.err, context.Canceled) || iserror(call.err, context.DeadlineExceeded)) {
+			return c.lookup(ctx, kind, key, fetch)
+		}
+
+		return call.id, call.err
+	}
+
+	call := &lookupCall{done: make(chan struct{})}
+	c.inflight[k] = call
+	generation := c.generation
+	c.mutex.Unlock()
+
+	call.id, call.err = fetch()
+
+	c.mutex.Lock()
+	delete(c.inflight, k)
+
+	// Objects created or deleted meanwhile may have made the answer stale
+	if call.err == nil && call.id != "" && generation == c.generation {
+		c.entries[k] = call.id
+	}
+
+	c.mutex.Unlock()
+	close(call.done)
+
+	return call.id, call.err
+}
+
+// Forget the objects of a kind whose natural key starts with the given parts
+func (c *lookupCache) invalidate(kind string, key ...string) {
+	if c == nil {
+		return
+	}
+
+	prefix := cachekey(kind, key)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	for k := range c.entries {
+		if k == prefix || strings.HasPrefix(k, prefix+cacheKeySep) || len(key) == 0 && strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// Forget an object of a kind from its oid, whatever its natural key
+func (c *lookupCache) forget(kind string, id string) {
+	if c == nil {
+		return
+	}
+
+	prefix := kind + cacheKindSuffix
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	for k, v := range c.entries {
+		if v == id && strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
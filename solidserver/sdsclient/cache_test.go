@@ -0,0 +1,133 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupCache_Lookup(t *testing.T) {
+	c := newLookupCache()
+	calls := 0
+
+	fetch := func() (string, error) {
+		calls++
+		return "2", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if id, err := c.lookup(context.Background(), cacheIPSite, []string{"space"}, fetch); id != "2" || err != nil {
+			t.Fatalf("unexpected result: %s %v", id, err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("expected a single fetch, got %d", calls)
+	}
+
+	// Another kind with the same key is resolved separately
+	c.lookup(context.Background(), cacheHostdev, []string{"space"}, fetch)
+
+	if calls != 2 {
+		t.Errorf("expected kinds to be cached separately, got %d fetches", calls)
+	}
+}
+
+func TestLookupCache_Misses(t *testing.T) {
+	c := newLookupCache()
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		c.lookup(context.Background(), cacheIPSite, []string{"space"}, func() (string, error) {
+			calls++
+			return "", nil
+		})
+		c.lookup(context.Background(), cacheIPSite, []string{"other"}, func() (string, error) {
+			calls++
+			return "", fmt.Errorf("unreachable")
+		})
+	}
+
+	if calls != 4 {
+		t.Errorf("expected missing objects and errors not to be cached, got %d fetches", calls)
+	}
+}
+
+func TestLookupCache_Invalidation(t *testing.T) {
+	c := newLookupCache()
+
+	c.lookup(context.Background(), cacheIPSubnet, []string{"2", "lan", "true"}, func() (string, error) { return "10", nil })
+	c.lookup(context.Background(), cacheIPSubnet, []string{"2", "lan", "false"}, func() (string, error) { return "11", nil })
+	c.lookup(context.Background(), cacheIPSubnet, []string{"22", "lan", "true"}, func() (string, error) { return "12", nil })
+	c.lookup(context.Background(), cacheIPSite, []string{"space"}, func() (string, error) { return "2", nil })
+
+	c.invalidate(cacheIPSubnet, "2", "lan")
+
+	if len(c.entries) != 2 {
+		t.Errorf("expected the subnets of space 2 to be forgotten, got %v", c.entries)
+	}
+
+	c.forget(cacheIPSubnet, "12")
+	c.forget(cacheIPSubnet, "2")
+
+	if len(c.entries) != 1 {
+		t.Errorf("expected only the space to remain, got %v", c.entries)
+	}
+
+	c.invalidate(cacheIPSite)
+
+	if len(c.entries) != 0 {
+		t.Errorf("expected the cache to be empty, got %v", c.entries)
+	}
+}
+
+func TestLookupCache_Concurrency(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `[{"site_id": "2"}]`)
+	}))
+	defer server.Close()
+
+	s := &SOLIDserver{BaseUrl: server.URL, Retry: DefaultRetryPolicy(), limiter: NewLimiter(0, 0), cache: newLookupCache()}
+	s.initClient()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if id, err := ipsiteidbyname(context.Background(), "Space", s); id != "2" || err != nil {
+				t.Errorf("unexpected result: %s %v", id, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected a single API call, got %d", n)
+	}
+}
+
+func TestLookupCache_StaleAnswer(t *testing.T) {
+	c := newLookupCache()
+
+	// The space is deleted while it is being resolved
+	c.lookup(context.Background(), cacheIPSite, []string{"space"}, func() (string, error) {
+		c.forget(cacheIPSite, "2")
+		return "2", nil
+	})
+
+	if len(c.entries) != 0 {
+		t.Errorf("expected the stale answer not to be cached, got %v", c.entries)
+	}
+}
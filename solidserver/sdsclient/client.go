@@ -0,0 +1,152 @@
+// Package sdsclient is the SOLIDserver API client of the provider.
+//
+// It sends the rest and rpc calls (authentication, retries, rate limiting,
+// failover between the cluster nodes, audit log) and manages SOLIDserver
+// objects (spaces, subnets, pools, addresses, aliases, zones, records, vlans,
+// devices, applications, users and groups) through plain Go structs.
+// It does not import Terraform, the resources only map their attributes to
+// and from these structs.
+//
+//	subnet, err := s.CreateSubnet(ctx, SubnetSpec{
+//		Space:        "office",
+//		Block:        "lan",
+//		PrefixLength: 24,
+//		Name:         "servers",
+//	})
+package sdsclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Send an API call on behalf of the typed client
+// Return the response, its raw body and its decoded rows
+func (s *SOLIDserver) call(ctx context.Context, method string, service string, parameters *url.Values, applied func() (bool, error)) (*http.Response, string, [](map[string]interface{}), error) {
+	resp, body, err := s.RequestWithLookup(ctx, method, service, parameters, applied)
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var buf [](map[string]interface{})
+	json.Unmarshal([]byte(body), &buf)
+
+	return resp, body, buf, nil
+}
+
+// Create or edit an object through an _add service
+// Return the oid of the object
+func (s *SOLIDserver) add(ctx context.Context, method string, service string, parameters *url.Values, applied func() (bool, error), format string, args ...interface{}) (string, error) {
+	resp, body, buf, err := s.call(ctx, method, service, parameters, applied)
+
+	if err != nil {
+		// Reporting a failure
+		return "", err
+	}
+
+	// Checking the answer
+	if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+		if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+			return oid, nil
+		}
+	}
+
+	// Reporting a failure
+	return "", apierrorf(service, resp, body, format, args...)
+}
+
+// Read an object through an _info service, or the first match of a filtered _list service
+// The error reports a not found object when the SOLIDserver returned nothing
+func (s *SOLIDserver) info(ctx context.Context, service string, parameters *url.Values, format string, args ...interface{}) (map[string]interface{}, error) {
+	resp, body, buf, err := s.call(ctx, "get", service, parameters, nil)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	// Checking the answer
+	if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+		return buf[0], nil
+	}
+
+	// An empty answer means no object matched the request
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil, WrapError(newNoMatchError(service, resp), format, args...)
+	}
+
+	// Reporting a failure
+	return nil, apierrorf(service, resp, body, format, args...)
+}
+
+// Delete an object through a _delete service
+func (s *SOLIDserver) remove(ctx context.Context, service string, parameters *url.Values, format string, args ...interface{}) error {
+	resp, body, _, err := s.call(ctx, "delete", service, parameters, nil)
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	// Checking the answer
+	if apiErr := NewSOLIDserverError(service, resp, body); apiErr != nil {
+		// Reporting a failure
+		return apierrorf(service, resp, body, format, args...)
+	}
+
+	return nil
+}
+
+// Return a column of an API answer as a string, empty if it is missing
+func rowstring(row map[string]interface{}, column string) string {
+	value, _ := row[column].(string)
+	return value
+}
+
+// Return a column of an API answer as an integer, 0 if it is missing
+func rowint(row map[string]interface{}, column string) int {
+	value, _ := strconv.Atoi(rowstring(row, column))
+	return value
+}
+
+// Return a column of an API answer as a boolean, false if it is missing
+func rowbool(row map[string]interface{}, column string) bool {
+	value, _ := strconv.ParseBool(rowstring(row, column))
+	return value
+}
+
+// Return the class parameters stored in a column of an API answer
+func rowclassparams(row map[string]interface{}, column string) map[string]string {
+	classParameters := map[string]string{}
+	values, _ := url.ParseQuery(rowstring(row, column))
+
+	for k, v := range values {
+		classParameters[k] = v[0]
+	}
+
+	return classParameters
+}
+
+// Build the url value object expected by the _add services from class parameters
+func encodeclassparams(classParameters map[string]string) url.Values {
+	values := url.Values{}
+
+	for k, v := range classParameters {
+		values.Add(k, v)
+	}
+
+	return values
+}
+
+// Return the API flag of a boolean
+func boolflag(value bool) string {
+	if value {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,87 @@
+package sdsclient
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_Subnet(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	ctx := context.Background()
+
+	if _, err := s.CreateSpace(ctx, SpaceSpec{Name: "office"}); err != nil {
+		t.Fatalf("unexpected error creating the space: %s", err)
+	}
+
+	if _, err := s.CreateSubnet(ctx, SubnetSpec{Space: "office", RequestIP: "10.0.0.0", PrefixLength: 16, Name: "lan"}); err != nil {
+		t.Fatalf("unexpected error creating the block: %s", err)
+	}
+
+	subnet, err := s.CreateSubnet(ctx, SubnetSpec{
+		Space:           "office",
+		Block:           "lan",
+		PrefixLength:    24,
+		Name:            "servers",
+		Terminal:        true,
+		GatewayOffset:   1,
+		ClassParameters: map[string]string{"vlan": "10"},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error creating the subnet: %s", err)
+	}
+
+	if subnet.Address != "10.0.0.0" || subnet.Gateway != "10.0.0.1" {
+		t.Errorf("unexpected subnet: %+v", subnet)
+	}
+
+	read, err := s.GetSubnet(ctx, subnet.ID)
+
+	if err != nil {
+		t.Fatalf("unexpected error reading the subnet: %s", err)
+	}
+
+	if read.Space != "office" || read.Block != "lan" || read.Address != "10.0.0.0" || read.PrefixLength != 24 ||
+		!read.Terminal || read.Gateway != "10.0.0.1" || read.ClassParameters["vlan"] != "10" {
+		t.Errorf("unexpected subnet: %+v", read)
+	}
+
+	// The gateway is reserved, the first free address follows it
+	address, err := s.CreateAddress(ctx, AddressSpec{Space: "office", Subnet: "servers", Name: "www"})
+
+	if err != nil {
+		t.Fatalf("unexpected error creating the address: %s", err)
+	}
+
+	if address.Address != "10.0.0.2" || address.Subnet != "servers" {
+		t.Errorf("unexpected address: %+v", address)
+	}
+
+	if err := s.DeleteSubnet(ctx, subnet.ID); err != nil {
+		t.Fatalf("unexpected error deleting the subnet: %s", err)
+	}
+
+	if _, err := s.GetSubnet(ctx, subnet.ID); !IsNotFound(err) {
+		t.Errorf("expected the subnet to be gone, got: %v", err)
+	}
+
+	if err := s.DeleteSubnet(ctx, subnet.ID); !IsNotFound(err) {
+		t.Errorf("expected a not found error deleting the subnet twice, got: %v", err)
+	}
+}
+
+func TestClient_Canceled(t *testing.T) {
+	f, s := newFakeSOLIDserver(t)
+	defer f.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.CreateSpace(ctx, SpaceSpec{Name: "office"}); !iserror(err, context.Canceled) {
+		t.Errorf("expected the creation to be canceled, got: %v", err)
+	}
+
+	if n := f.Count("rest/ip_site_add"); n != 0 {
+		t.Errorf("expected no call once canceled, got %d", n)
+	}
+}
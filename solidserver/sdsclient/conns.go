@@ -0,0 +1,13 @@
+//go:build go1.11
+// +build go1.11
+
+package sdsclient
+
+import (
+	"net/http"
+)
+
+// Cap the number of connections opened to the SOLIDserver
+func setmaxconnsperhost(transport *http.Transport, max int) {
+	transport.MaxConnsPerHost = max
+}
@@ -0,0 +1,13 @@
+//go:build !go1.11
+// +build !go1.11
+
+package sdsclient
+
+import (
+	"net/http"
+)
+
+// Go 1.10 transports can't cap their connections
+// The number of API calls sent at the same time is still bounded by the limiter
+func setmaxconnsperhost(transport *http.Transport, max int) {
+}
@@ -0,0 +1,268 @@
+package sdsclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Maximum time given to the credentials command
+const credentialsCommandTimeout = 30 * time.Second
+
+// CredentialSource provides the username and password used to authenticate on the SOLIDserver
+// Sources are queried again when the SOLIDserver rejects the current credentials
+type CredentialSource interface {
+	Credentials() (string, string, error)
+}
+
+// Read the password from a file, the username is given by the provider configuration
+type passwordFileCredentials struct {
+	username string
+	path     string
+}
+
+// NewPasswordFileCredentials reads the password from the first line of a file
+func NewPasswordFileCredentials(username string, path string) CredentialSource {
+	return &passwordFileCredentials{username: username, path: path}
+}
+
+func (c *passwordFileCredentials) Credentials() (string, string, error) {
+	content, err := ioutil.ReadFile(c.path)
+
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to read password_file %s: %s", c.path, err)
+	}
+
+	password := strings.SplitN(string(content), "\n", 2)[0]
+
+	return c.username, strings.TrimSuffix(password, "\r"), nil
+}
+
+// Run an external helper printing {"username": "...", "password": "..."}
+type commandCredentials struct {
+	username string
+	command  string
+}
+
+// NewCommandCredentials runs command through the shell to retrieve the credentials
+// The username printed by the command, if any, replaces the configured one
+func NewCommandCredentials(username string, command string) CredentialSource {
+	return &commandCredentials{username: username, command: command}
+}
+
+func (c *commandCredentials) Credentials() (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialsCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("credentials_command failed: %s (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &credentials); err != nil {
+		return "", "", fmt.Errorf("credentials_command did not print JSON credentials: %s", err)
+	}
+
+	if credentials.Password == "" {
+		return "", "", fmt.Errorf("credentials_command did not print any password")
+	}
+
+	if credentials.Username == "" {
+		credentials.Username = c.username
+	}
+
+	return credentials.Username, credentials.Password, nil
+}
+
+// Look up the credentials of the SOLIDserver hosts in a netrc file
+type netrcCredentials struct {
+	username string
+	path     string
+	hosts    []string
+}
+
+// NewNetrcCredentials looks up the credentials of the first matching host in a netrc file
+// The file defaults to $NETRC or ~/.netrc, the default entry is used when no host matches
+func NewNetrcCredentials(username string, path string, hosts []string) CredentialSource {
+	if path == "" {
+		path = os.Getenv("NETRC")
+	}
+
+	if path == "" {
+		if home := userhomedir(); home != "" {
+			path = filepath.Join(home, ".netrc")
+		}
+	}
+
+	return &netrcCredentials{username: username, path: path, hosts: hosts}
+}
+
+// Return the home directory of the user running terraform, empty if unknown
+func userhomedir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+
+	return os.Getenv("USERPROFILE")
+}
+
+func (c *netrcCredentials) Credentials() (string, string, error) {
+	file, err := os.Open(c.path)
+
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to read netrc file %s: %s", c.path, err)
+	}
+	defer file.Close()
+
+	// machine name -> login, password
+	machines := map[string][2]string{}
+	var current string
+	var entry [2]string
+	var key string
+
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanWords)
+
+	save := func() {
+		if current != "" {
+			if _, exist := machines[current]; !exist {
+				machines[current] = entry
+			}
+		}
+	}
+
+scan:
+	for scanner.Scan() {
+		word := scanner.Text()
+
+		switch {
+		case key != "":
+			switch key {
+			case "machine":
+				save()
+				current, entry = strings.ToLower(word), [2]string{}
+			case "login":
+				entry[0] = word
+			case "password":
+				entry[1] = word
+			}
+			key = ""
+		case word == "machine" || word == "login" || word == "password" || word == "account":
+			key = word
+		case word == "default":
+			save()
+			current, entry = "default", [2]string{}
+		case word == "macdef":
+			// Macros are not supported, stop before their content
+			break scan
+		}
+	}
+
+	save()
+
+	candidates := []string{}
+
+	for _, host := range c.hosts {
+		if hostname, _, splitErr := net.SplitHostPort(host); splitErr == nil {
+			host = hostname
+		}
+		candidates = append(candidates, strings.ToLower(strings.Trim(host, "[]")))
+	}
+
+	for _, candidate := range append(candidates, "default") {
+		if credentials, found := machines[candidate]; found && credentials[1] != "" {
+			username := credentials[0]
+
+			if username == "" {
+				username = c.username
+			}
+
+			return username, credentials[1], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("No credentials found for %s in netrc file %s", strings.Join(candidates, ", "), c.path)
+}
+
+// Return the credentials used for the next API calls
+func (s *SOLIDserver) login() (string, string) {
+	s.credentialsMutex.RLock()
+	defer s.credentialsMutex.RUnlock()
+
+	return s.Username, s.Password
+}
+
+// Retrieve the credentials from the credential source
+func (s *SOLIDserver) loadCredentials() error {
+	if s.credentials == nil {
+		return nil
+	}
+
+	username, password, err := s.credentials.Credentials()
+
+	if err != nil {
+		return err
+	}
+
+	s.credentialsMutex.Lock()
+	defer s.credentialsMutex.Unlock()
+
+	s.Username, s.Password = username, password
+
+	return nil
+}
+
+// Query the credential source again after the SOLIDserver rejected the current credentials
+// Return true if the credentials changed
+func (s *SOLIDserver) refreshCredentials() bool {
+	if s.credentials == nil {
+		return false
+	}
+
+	previousUsername, previousPassword := s.login()
+
+	if err := s.loadCredentials(); err != nil {
+		log.Printf("[WARN] SOLIDServer - Unable to refresh the credentials: %s\n", err)
+		return false
+	}
+
+	username, password := s.login()
+
+	return username != previousUsername || password != previousPassword
+}
+
+// Log in again after the SOLIDserver rejected a call with HTTP 401
+// Return true if the call can be sent again with a new session or new credentials
+func (s *SOLIDserver) reauthenticate() bool {
+	expired := s.expireSession()
+	refreshed := s.refreshCredentials()
+
+	return expired || refreshed
+}
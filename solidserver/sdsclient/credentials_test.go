@@ -0,0 +1,126 @@
+package sdsclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPasswordFileCredentials(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	ioutil.WriteFile(path, []byte("s3cr3t\r\nignored\n"), 0600)
+
+	username, password, err := NewPasswordFileCredentials("ipmadmin", path).Credentials()
+
+	if err != nil || username != "ipmadmin" || password != "s3cr3t" {
+		t.Errorf("unexpected credentials: %s %s %v", username, password, err)
+	}
+
+	if _, _, err := NewPasswordFileCredentials("ipmadmin", path+".missing").Credentials(); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestCommandCredentials(t *testing.T) {
+	username, password, err := NewCommandCredentials("ipmadmin", `echo '{"password": "s3cr3t"}'`).Credentials()
+
+	if err != nil || username != "ipmadmin" || password != "s3cr3t" {
+		t.Errorf("unexpected credentials: %s %s %v", username, password, err)
+	}
+
+	username, _, err = NewCommandCredentials("ipmadmin", `echo '{"username": "robot", "password": "s3cr3t"}'`).Credentials()
+
+	if err != nil || username != "robot" {
+		t.Errorf("expected the username of the command, got %s %v", username, err)
+	}
+
+	for _, command := range []string{"exit 1", "echo not-json", `echo '{"username": "robot"}'`} {
+		if _, _, err := NewCommandCredentials("ipmadmin", command).Credentials(); err == nil {
+			t.Errorf("%s: expected an error", command)
+		}
+	}
+}
+
+func TestNetrcCredentials(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "netrc")
+	ioutil.WriteFile(path, []byte(`
+machine other.local login other password other
+machine sds.local
+  login ipmadmin
+  password s3cr3t
+default login anonymous password guest
+macdef init
+  machine sds2.local login ignored password ignored
+`), 0600)
+
+	username, password, err := NewNetrcCredentials("", path, []string{"sds.local:8443"}).Credentials()
+
+	if err != nil || username != "ipmadmin" || password != "s3cr3t" {
+		t.Errorf("unexpected credentials: %s %s %v", username, password, err)
+	}
+
+	username, password, err = NewNetrcCredentials("", path, []string{"sds2.local"}).Credentials()
+
+	if err != nil || username != "anonymous" || password != "guest" {
+		t.Errorf("expected the default entry, got %s %s %v", username, password, err)
+	}
+
+	ioutil.WriteFile(path, []byte("machine other.local login other password other\n"), 0600)
+
+	if _, _, err := NewNetrcCredentials("", path, []string{"sds.local"}).Credentials(); err == nil {
+		t.Errorf("expected an error when no entry matches")
+	}
+}
+
+func TestRequest_RefreshCredentials(t *testing.T) {
+	var accepted atomic.Value
+	accepted.Store("old")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-IPM-Password") != base64.StdEncoding.EncodeToString([]byte(accepted.Load().(string))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "solidserver")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	ioutil.WriteFile(path, []byte("old"), 0600)
+
+	credentials := NewCommandCredentials("ipmadmin", fmt.Sprintf(`printf '{"password": "%%s"}' "$(cat %s)"`, path))
+	s, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "", false, "", SOLIDserverOptions{Credentials: credentials})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The password is rotated
+	accepted.Store("new")
+	ioutil.WriteFile(path, []byte("new"), 0600)
+
+	parameters := url.Values{}
+	resp, _, err := s.Request(context.Background(), "get", "rest/ip_site_list", &parameters)
+
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the call to succeed with the new password, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package sdsclient
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// Device is a device of the Device Manager
+type Device struct {
+	ID              string
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// DeviceSpec describes a device to create or update
+// Device names are stored in lower case
+type DeviceSpec struct {
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// Build the parameters shared by the creation and the update of a device
+func deviceparameters(spec DeviceSpec) url.Values {
+	parameters := url.Values{}
+	parameters.Add("hostdev_name", strings.ToLower(spec.Name))
+	parameters.Add("hostdev_class_name", spec.Class)
+	parameters.Add("hostdev_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	return parameters
+}
+
+// CreateDevice creates a device
+func (s *SOLIDserver) CreateDevice(ctx context.Context, spec DeviceSpec) (*Device, error) {
+	// Building parameters
+	parameters := deviceparameters(spec)
+	parameters.Add("add_flag", "new_only")
+
+	// Sending creation request
+	oid, err := s.add(ctx, "post", "rest/hostdev_add", &parameters, nil, "SOLIDServer - Unable to create device: %s", strings.ToLower(spec.Name))
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created device (oid): %s\n", oid)
+	s.cache.invalidate(cacheHostdev, strings.ToLower(spec.Name))
+
+	return &Device{ID: oid, Name: strings.ToLower(spec.Name), Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// GetDevice reads a device from its oid
+func (s *SOLIDserver) GetDevice(ctx context.Context, id string) (*Device, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("hostdev_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/hostdev_info", &parameters, "SOLIDServer - Unable to find device (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Device{
+		ID:              id,
+		Name:            rowstring(row, "hostdev_name"),
+		Class:           rowstring(row, "hostdev_class_name"),
+		ClassParameters: rowclassparams(row, "hostdev_class_parameters"),
+	}, nil
+}
+
+// UpdateDevice updates a device
+func (s *SOLIDserver) UpdateDevice(ctx context.Context, id string, spec DeviceSpec) (*Device, error) {
+	// Building parameters
+	parameters := deviceparameters(spec)
+	parameters.Add("hostdev_id", id)
+	parameters.Add("add_flag", "edit_only")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/hostdev_add", &parameters, nil, "SOLIDServer - Unable to update device: %s", strings.ToLower(spec.Name))
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated device (oid): %s\n", oid)
+	s.cache.forget(cacheHostdev, oid)
+
+	return &Device{ID: oid, Name: strings.ToLower(spec.Name), Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// DeleteDevice deletes a device, its IP addresses are kept
+func (s *SOLIDserver) DeleteDevice(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("hostdev_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/hostdev_delete", &parameters, "SOLIDServer - Unable to delete device (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted device (oid): %s\n", id)
+	s.cache.forget(cacheHostdev, id)
+
+	return nil
+}
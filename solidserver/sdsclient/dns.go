@@ -0,0 +1,304 @@
+package sdsclient
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Zone is a DNS zone
+type Zone struct {
+	ID              string
+	Server          string
+	View            string
+	Name            string
+	Type            string
+	Space           string
+	CreatePTR       bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// ZoneSpec describes a DNS zone to create or update
+// The "#" view, like an empty one, stands for no view
+type ZoneSpec struct {
+	Server          string
+	View            string
+	Name            string
+	Type            string
+	Space           string
+	CreatePTR       bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// RR is a DNS resource record
+type RR struct {
+	ID     string
+	Server string
+	View   string
+	Name   string
+	Type   string
+	Value  string
+	TTL    int
+}
+
+// RRSpec describes a DNS resource record to create or update
+type RRSpec struct {
+	Server string
+	View   string
+	Name   string
+	Type   string
+	Value  string
+	TTL    int
+}
+
+// Build the parameters shared by the creation and the update of a DNS zone
+func zoneparameters(spec ZoneSpec, siteID string) url.Values {
+	parameters := url.Values{}
+	parameters.Add("dnszone_site_id", siteID)
+	parameters.Add("dnszone_class_name", spec.Class)
+
+	// Building class_parameters
+	classParameters := encodeclassparams(spec.ClassParameters)
+
+	// Generate class parameter for createptr
+	classParameters.Set("dnsptr", boolflag(spec.CreatePTR))
+
+	parameters.Add("dnszone_class_parameters", classParameters.Encode())
+
+	return parameters
+}
+
+// CreateZone creates a DNS zone
+func (s *SOLIDserver) CreateZone(ctx context.Context, spec ZoneSpec) (*Zone, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	// Building parameters
+	parameters := zoneparameters(spec, siteID)
+	parameters.Add("add_flag", "new_only")
+	parameters.Add("dns_name", spec.Server)
+
+	if spec.View != "" && spec.View != "#" {
+		parameters.Add("dnsview_name", spec.View)
+	}
+
+	parameters.Add("dnszone_name", spec.Name)
+	parameters.Add("dnszone_type", strings.ToLower(spec.Type))
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/dns_zone_add", &parameters, nil, "SOLIDServer - Unable to create DNS zone: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created DNS zone (oid): %s\n", oid)
+
+	return &Zone{
+		ID:              oid,
+		Server:          spec.Server,
+		View:            spec.View,
+		Name:            spec.Name,
+		Type:            spec.Type,
+		Space:           spec.Space,
+		CreatePTR:       spec.CreatePTR,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// GetZone reads a DNS zone from its oid
+func (s *SOLIDserver) GetZone(ctx context.Context, id string) (*Zone, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("dnszone_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/dns_zone_info", &parameters, "SOLIDServer - Unable to find DNS zone (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	zone := &Zone{
+		ID:              id,
+		Server:          rowstring(row, "dns_name"),
+		View:            rowstring(row, "dnsview_name"),
+		Name:            rowstring(row, "dnszone_name"),
+		Type:            rowstring(row, "dnszone_type"),
+		Space:           rowstring(row, "dnszone_site_name"),
+		Class:           rowstring(row, "dnszone_class_name"),
+		ClassParameters: rowclassparams(row, "dnszone_class_parameters"),
+	}
+
+	// Zones out of any space are attached to the "#" space
+	if zone.Space == "#" {
+		zone.Space = ""
+	}
+
+	zone.CreatePTR = zone.ClassParameters["dnsptr"] == "1"
+
+	return zone, nil
+}
+
+// UpdateZone updates the space, the PTR records creation, the class and class parameters of a DNS zone
+func (s *SOLIDserver) UpdateZone(ctx context.Context, id string, spec ZoneSpec) (*Zone, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	// Building parameters
+	parameters := zoneparameters(spec, siteID)
+	parameters.Add("dnszone_id", id)
+	parameters.Add("add_flag", "edit_only")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/dns_zone_add", &parameters, nil, "SOLIDServer - Unable to update Zone: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated DNS Zone (oid): %s\n", oid)
+
+	return &Zone{
+		ID:              oid,
+		Server:          spec.Server,
+		View:            spec.View,
+		Name:            spec.Name,
+		Type:            spec.Type,
+		Space:           spec.Space,
+		CreatePTR:       spec.CreatePTR,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// DeleteZone deletes a DNS zone along with its records
+func (s *SOLIDserver) DeleteZone(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("dnszone_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/dns_zone_delete", &parameters, "SOLIDServer - Unable to delete Zone (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted Zone (oid): %s\n", id)
+
+	return nil
+}
+
+// Build the parameters shared by the creation and the update of a DNS resource record
+func rrparameters(spec RRSpec) url.Values {
+	parameters := url.Values{}
+	parameters.Add("dns_name", spec.Server)
+	parameters.Add("rr_name", spec.Name)
+	parameters.Add("rr_type", strings.ToUpper(spec.Type))
+	parameters.Add("value1", spec.Value)
+	parameters.Add("rr_ttl", strconv.Itoa(spec.TTL))
+
+	// Add dnsview_name parameter if it is supplied
+	if len(spec.View) != 0 {
+		parameters.Add("dnsview_name", spec.View)
+	}
+
+	return parameters
+}
+
+// CreateRR creates a DNS resource record in the zone serving its name
+func (s *SOLIDserver) CreateRR(ctx context.Context, spec RRSpec) (*RR, error) {
+	// Building parameters
+	parameters := rrparameters(spec)
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/dns_rr_add", &parameters, nil, "SOLIDServer - Unable to create RR: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created RR (oid): %s\n", oid)
+
+	return &RR{ID: oid, Server: spec.Server, View: spec.View, Name: spec.Name, Type: strings.ToUpper(spec.Type), Value: spec.Value, TTL: spec.TTL}, nil
+}
+
+// GetRR reads a DNS resource record from its oid
+func (s *SOLIDserver) GetRR(ctx context.Context, id string) (*RR, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("rr_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/dns_rr_info", &parameters, "SOLIDServer - Unable to find RR (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &RR{
+		ID:     id,
+		Server: rowstring(row, "dns_name"),
+		View:   rowstring(row, "dnsview_name"),
+		Name:   rowstring(row, "rr_full_name"),
+		Type:   rowstring(row, "rr_type"),
+		Value:  rowstring(row, "value1"),
+		TTL:    rowint(row, "ttl"),
+	}, nil
+}
+
+// UpdateRR updates a DNS resource record
+func (s *SOLIDserver) UpdateRR(ctx context.Context, id string, spec RRSpec) (*RR, error) {
+	// Building parameters
+	parameters := rrparameters(spec)
+	parameters.Add("rr_id", id)
+	parameters.Add("add_flag", "edit_only")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/dns_rr_add", &parameters, nil, "SOLIDServer - Unable to update RR: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated RR (oid): %s\n", oid)
+
+	return &RR{ID: oid, Server: spec.Server, View: spec.View, Name: spec.Name, Type: strings.ToUpper(spec.Type), Value: spec.Value, TTL: spec.TTL}, nil
+}
+
+// DeleteRR deletes a DNS resource record
+func (s *SOLIDserver) DeleteRR(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("rr_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/dns_rr_delete", &parameters, "SOLIDServer - Unable to delete RR (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted RR (oid): %s\n", id)
+
+	return nil
+}
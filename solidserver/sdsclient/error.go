@@ -0,0 +1,251 @@
+package sdsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Error numbers documented by the SOLIDserver API
+const (
+	// The object does not exist
+	ErrnoNotFound = 2012
+	// An object with the same name already exists
+	ErrnoAlreadyExists = 2004
+	// The object overlaps an existing one
+	ErrnoOverlaps = 2007
+	// The address or VLAN ID is already used
+	ErrnoAlreadyUsed = 2008
+	// The node is not, or no longer, the master of the SOLIDserver cluster
+	ErrnoNotMaster = 2030
+)
+
+// SOLIDserverError describes an API call rejected by the SOLIDserver
+type SOLIDserverError struct {
+	StatusCode int
+	Errno      int
+	Errmsg     string
+	Severity   string
+	Service    string
+	// Set when an _info service returned no object
+	noMatch bool
+}
+
+// Build a SOLIDserverError from the answer of a non-2xx API call
+// Return nil if the API call succeeded
+func NewSOLIDserverError(service string, resp *http.Response, body string) *SOLIDserverError {
+	if resp == nil || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+
+	e := &SOLIDserverError{
+		StatusCode: resp.StatusCode,
+		Service:    service,
+	}
+
+	var buf [](map[string]interface{})
+	json.Unmarshal([]byte(body), &buf)
+
+	if len(buf) > 0 {
+		if errno, errnoExist := buf[0]["errno"].(string); errnoExist {
+			e.Errno, _ = strconv.Atoi(errno)
+		}
+
+		if errMsg, errExist := buf[0]["errmsg"].(string); errExist {
+			e.Errmsg = errMsg
+		}
+
+		if severity, severityExist := buf[0]["severity"].(string); severityExist {
+			e.Severity = severity
+		}
+	}
+
+	if e.Errmsg == "" {
+		e.Errmsg = http.StatusText(resp.StatusCode)
+	}
+
+	return e
+}
+
+func (e *SOLIDserverError) Error() string {
+	if e.Errno != 0 {
+		return fmt.Sprintf("%s (errno: %d, service: %s, HTTP %d)", e.Errmsg, e.Errno, e.Service, e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s (service: %s, HTTP %d)", e.Errmsg, e.Service, e.StatusCode)
+}
+
+// Build the error reported when an _info service returned no object
+func newNoMatchError(service string, resp *http.Response) *SOLIDserverError {
+	return &SOLIDserverError{StatusCode: resp.StatusCode, Errmsg: "object not found", Service: service, noMatch: true}
+}
+
+// IsNotFound reports whether the SOLIDserver could not find the requested object
+// Only the documented errno and the empty answers of _info services are trusted, a
+// 404 may come from a proxy or a wrong path prefix rather than from the SOLIDserver
+func (e *SOLIDserverError) IsNotFound() bool {
+	return e.noMatch || e.Errno == ErrnoNotFound
+}
+
+// IsConflict reports whether the request conflicts with an existing object
+func (e *SOLIDserverError) IsConflict() bool {
+	switch e.Errno {
+	case ErrnoAlreadyExists, ErrnoOverlaps, ErrnoAlreadyUsed:
+		return true
+	}
+
+	return e.StatusCode == http.StatusConflict
+}
+
+// IsPermissionDenied reports whether the API user is not allowed to perform the request
+func (e *SOLIDserverError) IsPermissionDenied() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// IsNotMaster reports whether the request was rejected by a node which is not the master of the cluster
+// The request was not processed and can be sent to the new master
+func (e *SOLIDserverError) IsNotMaster() bool {
+	return e.Errno == ErrnoNotMaster
+}
+
+// IsTransient reports whether the request may succeed if sent again later
+func (e *SOLIDserverError) IsTransient() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// wrappedError adds context to an error, its cause remains available to the Is* helpers
+// fmt.Errorf's %w is not available on every Go version the provider builds with
+type wrappedError struct {
+	msg   string
+	cause error
+}
+
+// WrapError builds an error from a message and the error which caused it
+func WrapError(cause error, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+
+	return &wrappedError{msg: fmt.Sprintf("%s: %s", msg, cause), cause: cause}
+}
+
+func (e *wrappedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the cause of the error
+func (e *wrappedError) Unwrap() error {
+	return e.cause
+}
+
+// Return the chain of an error, starting with the error itself and ending with its root cause
+func causes(err error) []error {
+	chain := []error{}
+
+	for err != nil {
+		chain = append(chain, err)
+
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+		case *net.OpError:
+			err = e.Err
+		case *os.SyscallError:
+			err = e.Err
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			err = nil
+		}
+	}
+
+	return chain
+}
+
+// Check if an error or one of its causes is the target error
+func iserror(err error, target error) bool {
+	for _, cause := range causes(err) {
+		if cause == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return the SOLIDserver error which caused err, nil if it was not returned by the SOLIDserver
+func apierror(err error) *SOLIDserverError {
+	for _, cause := range causes(err) {
+		if e, ok := cause.(*SOLIDserverError); ok {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// IsAPIError reports whether err was returned by the SOLIDserver rather than by the transport
+func IsAPIError(err error) bool {
+	return apierror(err) != nil
+}
+
+// IsNotFound reports whether err is a SOLIDserver not found error
+func IsNotFound(err error) bool {
+	e := apierror(err)
+	return e != nil && e.IsNotFound()
+}
+
+// IsConflict reports whether err is a SOLIDserver conflict error
+func IsConflict(err error) bool {
+	e := apierror(err)
+	return e != nil && e.IsConflict()
+}
+
+// IsPermissionDenied reports whether err is a SOLIDserver permission error
+func IsPermissionDenied(err error) bool {
+	e := apierror(err)
+	return e != nil && e.IsPermissionDenied()
+}
+
+// IsTransient reports whether err is a transient SOLIDserver error
+func IsTransient(err error) bool {
+	e := apierror(err)
+	return e != nil && e.IsTransient()
+}
+
+// Build the error reported to Terraform for a failed API call
+// The reason given by the SOLIDserver is appended to the message when available
+func apierrorf(service string, resp *http.Response, body string, format string, args ...interface{}) error {
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+
+	if apiErr := NewSOLIDserverError(service, resp, body); apiErr != nil {
+		return WrapError(apiErr, "%s", msg)
+	}
+
+	return errors.New(msg)
+}
+
+// Check the answer of an API call which did not return the expected object
+// Return nil if the SOLIDserver confirmed the object does not exist, an error otherwise
+func apiconfirmgone(service string, resp *http.Response, body string, format string, args ...interface{}) error {
+	// An empty answer means no object matched the request
+	if resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	if apiErr := NewSOLIDserverError(service, resp, body); apiErr != nil && apiErr.IsNotFound() {
+		return nil
+	}
+
+	return apierrorf(service, resp, body, format, args...)
+}
@@ -0,0 +1,125 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNewSOLIDserverError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusBadRequest}
+	body := `[{"errno": "2007", "errmsg": "Subnet overlaps with an existing subnet", "severity": "ERROR"}]`
+
+	e := NewSOLIDserverError("rest/ip_subnet_add", resp, body)
+
+	if e == nil {
+		t.Fatal("expected an error for a non-2xx answer")
+	}
+
+	if e.StatusCode != 400 || e.Errno != 2007 || e.Severity != "ERROR" || e.Service != "rest/ip_subnet_add" {
+		t.Errorf("unexpected error content: %+v", e)
+	}
+
+	if !e.IsConflict() || e.IsNotFound() || e.IsTransient() || e.IsPermissionDenied() {
+		t.Errorf("unexpected classification for %q", e.Errmsg)
+	}
+
+	if NewSOLIDserverError("rest/ip_subnet_add", &http.Response{StatusCode: http.StatusCreated}, "") != nil {
+		t.Error("expected no error for a 2xx answer")
+	}
+}
+
+func TestSOLIDserverErrorClassification(t *testing.T) {
+	cases := []struct {
+		status   int
+		body     string
+		check    func(error) bool
+		expected bool
+	}{
+		{http.StatusBadRequest, `[{"errno": "2012", "errmsg": "IP address (oid: 42) does not exist"}]`, IsNotFound, true},
+		{http.StatusNotFound, ``, IsNotFound, false},
+		{http.StatusBadRequest, `[{"errno": "1", "errmsg": "Object does not exist"}]`, IsNotFound, false},
+		{http.StatusBadRequest, `[{"errno": "2004", "errmsg": "Name already used"}]`, IsConflict, true},
+		{http.StatusBadRequest, `[{"errno": "1", "errmsg": "Name already used"}]`, IsConflict, false},
+		{http.StatusForbidden, `[{"errno": "1", "errmsg": "Forbidden"}]`, IsPermissionDenied, true},
+		{http.StatusServiceUnavailable, `<html>Maintenance</html>`, IsTransient, true},
+		{http.StatusTooManyRequests, ``, IsTransient, true},
+	}
+
+	for _, c := range cases {
+		err := apierrorf("rest/ip_add", &http.Response{StatusCode: c.status}, c.body, "SOLIDServer - Unable to create IP address: %s\n", "test")
+
+		if c.check(err) != c.expected {
+			t.Errorf("unexpected classification for HTTP %d %s: %s", c.status, c.body, err)
+		}
+	}
+
+	if !IsNotFound(newNoMatchError("rest/ip_address_info", &http.Response{StatusCode: http.StatusNoContent})) {
+		t.Error("expected an empty answer of an _info service to be a not found error")
+	}
+
+	if IsNotFound(fmt.Errorf("SOLIDServer - Error initiating API call")) {
+		t.Error("unexpected classification for a non SOLIDserver error")
+	}
+
+	if IsAPIError(fmt.Errorf("SOLIDServer - Error initiating API call")) || !IsAPIError(apierrorf("rest/ip_add", &http.Response{StatusCode: http.StatusBadRequest}, ``, "SOLIDServer - Unable to create IP address")) {
+		t.Error("unexpected classification of SOLIDserver and transport errors")
+	}
+}
+
+func TestApierrorf(t *testing.T) {
+	err := apierrorf("rest/ip_add", &http.Response{StatusCode: http.StatusBadRequest}, `[{"errno": "17", "errmsg": "Name already used"}]`, "SOLIDServer - Unable to create IP address: %s\n", "www")
+
+	if !strings.HasPrefix(err.Error(), "SOLIDServer - Unable to create IP address: www: Name already used") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+
+	err = apierrorf("rest/ip_add", &http.Response{StatusCode: http.StatusOK}, `[]`, "SOLIDServer - Unable to create IP address: %s\n", "www")
+
+	if err.Error() != "SOLIDServer - Unable to create IP address: www" {
+		t.Errorf("unexpected error message: %s", err)
+	}
+}
+
+func TestWraperror(t *testing.T) {
+	apiErr := apierrorf("rest/ip_add", &http.Response{StatusCode: http.StatusBadRequest}, `[{"errno": "2012", "errmsg": "IP address (oid: 42) does not exist"}]`, "SOLIDServer - Unable to find IP address: %s", "www")
+	err := WrapError(apiErr, "SOLIDServer - Unable to read IP address %d of block: %s", 1, "42")
+
+	if !IsNotFound(err) || !strings.HasPrefix(err.Error(), "SOLIDServer - Unable to read IP address 1 of block: 42: SOLIDServer - Unable to find IP address: www") {
+		t.Errorf("unexpected wrapped error: %s", err)
+	}
+
+	transportErr := WrapError(&url.Error{Op: "Get", URL: "https://sds.local", Err: context.Canceled}, "SOLIDServer - Error initiating API call")
+
+	if !iserror(transportErr, context.Canceled) || iserror(transportErr, context.DeadlineExceeded) || IsAPIError(transportErr) {
+		t.Errorf("unexpected causes of %s", transportErr)
+	}
+}
+
+func TestApiconfirmgone(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		gone   bool
+	}{
+		{http.StatusNoContent, ``, true},
+		{http.StatusOK, `[]`, true},
+		{http.StatusBadRequest, `[{"errno": "2012", "errmsg": "IP subnet (oid: 42) does not exist"}]`, true},
+		{http.StatusBadRequest, `[{"errno": "2001", "errmsg": "Object not found"}]`, false},
+		{http.StatusNotFound, `<html>Not Found</html>`, false},
+		{http.StatusInternalServerError, `[{"errno": "1", "errmsg": "Internal error"}]`, false},
+		{http.StatusServiceUnavailable, `<html>Upgrade in progress</html>`, false},
+		{http.StatusForbidden, `[{"errno": "1", "errmsg": "License expired"}]`, false},
+	}
+
+	for _, c := range cases {
+		err := apiconfirmgone("rest/ip_block_subnet_info", &http.Response{StatusCode: c.status}, c.body, "SOLIDServer - Unable to check existence of IP subnet (oid): %s", "42")
+
+		if (err == nil) != c.gone {
+			t.Errorf("HTTP %d %s: expected gone=%t, got %v", c.status, c.body, c.gone, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package sdsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SplitHosts splits the host argument into the ordered list of SOLIDserver endpoints
+func SplitHosts(host string) []string {
+	hosts := []string{}
+
+	for _, h := range strings.Split(host, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+
+	return hosts
+}
+
+// Return the endpoint currently used and its base URL
+func (s *SOLIDserver) endpoint() (string, string) {
+	s.endpointMutex.RLock()
+	defer s.endpointMutex.RUnlock()
+
+	if s.current >= len(s.Hosts) {
+		return s.Host, s.BaseUrl
+	}
+
+	return s.Hosts[s.current], s.BaseUrl
+}
+
+// Return the endpoint currently used
+func (s *SOLIDserver) currentHost() string {
+	host, _ := s.endpoint()
+
+	return host
+}
+
+// Return the base URL of the endpoint currently used
+func (s *SOLIDserver) baseURL() string {
+	_, baseurl := s.endpoint()
+
+	return baseurl
+}
+
+// Check if the local member of an endpoint is the master of the SOLIDserver cluster
+// Standalone appliances do not report any role
+func memberismaster(member map[string]interface{}) bool {
+	if role, roleExist := member["member_role"].(string); roleExist && role != "" {
+		return strings.Contains(strings.ToLower(role), "master")
+	}
+
+	return true
+}
+
+// Check that an endpoint is reachable and is the master of the SOLIDserver cluster
+func (s *SOLIDserver) probe(ctx context.Context, baseurl string) error {
+	parameters := url.Values{}
+	where, whereErr := WhereEq("member_is_me", "1").Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	if err := s.limiter.Acquire(ctx); err != nil {
+		return err
+	}
+
+	resp, body, err := s.do(ctx, http.MethodGet, baseurl, "rest/member_list", &parameters)
+	s.limiter.Release()
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var buf [](map[string]interface{})
+	json.Unmarshal([]byte(body), &buf)
+
+	if len(buf) == 0 {
+		return fmt.Errorf("no local member")
+	}
+
+	if !memberismaster(buf[0]) {
+		return fmt.Errorf("not the master")
+	}
+
+	return nil
+}
+
+// Switch to the first healthy master endpoint, in the configured order
+// The endpoint which just failed is skipped, the caller keeps it when no other one is healthy
+// Return true if the client now uses another endpoint
+func (s *SOLIDserver) failover(ctx context.Context, failed string) bool {
+	if len(s.Hosts) < 2 {
+		return false
+	}
+
+	s.failoverMutex.Lock()
+	defer s.failoverMutex.Unlock()
+
+	// Another call already switched to a new endpoint
+	if s.baseURL() != failed {
+		return true
+	}
+
+	from := s.currentHost()
+
+	for i, host := range s.Hosts {
+		if s.hostURL(host) == failed {
+			continue
+		}
+
+		if err := s.probe(ctx, s.hostURL(host)); err != nil {
+			log.Printf("[DEBUG] SOLIDServer - Skipping endpoint %s (%s)\n", host, err)
+			continue
+		}
+
+		log.Printf("[WARN] SOLIDServer - Failing over from %s to %s\n", from, host)
+		s.useEndpoint(i)
+
+		return true
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - No other healthy endpoint, keeping %s\n", from)
+
+	return false
+}
+
+// Select the first healthy master endpoint when the client starts
+// The first endpoint is kept when none is healthy, to report its error
+func (s *SOLIDserver) selectEndpoint(ctx context.Context) {
+	for i, host := range s.Hosts {
+		if err := s.probe(ctx, s.hostURL(host)); err != nil {
+			log.Printf("[DEBUG] SOLIDServer - Skipping endpoint %s (%s)\n", host, err)
+			continue
+		}
+
+		s.useEndpoint(i)
+		return
+	}
+
+	s.useEndpoint(0)
+}
+
+// Stick to the given endpoint for the following calls
+func (s *SOLIDserver) useEndpoint(i int) {
+	s.endpointMutex.Lock()
+	defer s.endpointMutex.Unlock()
+
+	s.current = i
+	s.BaseUrl = s.hostURL(s.Hosts[i])
+}
+
+// Check if a call was rejected by a node which is no longer the master of the cluster
+func endpointnotmaster(service string, resp *http.Response, body string) bool {
+	apiErr := NewSOLIDserverError(service, resp, body)
+
+	return apiErr != nil && apiErr.IsNotMaster()
+}
+
+// Check if a failed call means the endpoint is down or no longer the master
+func endpointfailed(service string, resp *http.Response, body string, err error) bool {
+	if err != nil {
+		_, sent := neterrorkind(err)
+		return !sent
+	}
+
+	if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusBadGateway {
+		return true
+	}
+
+	return endpointnotmaster(service, resp, body)
+}
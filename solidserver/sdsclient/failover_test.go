@@ -0,0 +1,132 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSplitHosts(t *testing.T) {
+	hosts := SplitHosts(" sds1.local, ,sds2.local:8443 ,")
+
+	if len(hosts) != 2 || hosts[0] != "sds1.local" || hosts[1] != "sds2.local:8443" {
+		t.Errorf("unexpected hosts: %v", hosts)
+	}
+}
+
+func TestNewSOLIDserver_SelectMaster(t *testing.T) {
+	slave := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1", "member_role": "slave"}]`)
+	}))
+	defer slave.Close()
+
+	master := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1", "member_role": "master"}]`)
+	}))
+	defer master.Close()
+
+	host := strings.TrimPrefix(slave.URL, "https://") + "," + strings.TrimPrefix(master.URL, "https://")
+	s, err := NewSOLIDserver(host, "ipmadmin", "admin", false, "", SOLIDserverOptions{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.baseURL() != master.URL {
+		t.Errorf("expected the master endpoint %s, got %s", master.URL, s.baseURL())
+	}
+}
+
+func TestRequest_Failover(t *testing.T) {
+	var down int32
+	var secondary int32
+
+	primary := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `[{"member_version": "7.0.1", "member_role": "master"}]`)
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondary, 1)
+		fmt.Fprint(w, `[{"member_version": "7.0.1", "member_role": "master"}]`)
+	}))
+	defer backup.Close()
+
+	host := strings.TrimPrefix(primary.URL, "https://") + "," + strings.TrimPrefix(backup.URL, "https://")
+	s, err := NewSOLIDserver(host, "ipmadmin", "admin", false, "", SOLIDserverOptions{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.baseURL() != primary.URL {
+		t.Fatalf("expected the first endpoint to be selected, got %s", s.baseURL())
+	}
+
+	atomic.StoreInt32(&down, 1)
+	parameters := url.Values{}
+	resp, _, err := s.Request(context.Background(), "get", "rest/ip_site_list", &parameters)
+
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the call to succeed on the backup endpoint, got %v", err)
+	}
+
+	if s.baseURL() != backup.URL {
+		t.Errorf("expected the client to stick to the backup endpoint, got %s", s.baseURL())
+	}
+
+	// The backup endpoint received the probe and the call sent again
+	if n := atomic.LoadInt32(&secondary); n != 2 {
+		t.Errorf("expected the backup endpoint to be probed then called, got %d calls", n)
+	}
+}
+
+func TestRequest_FailoverWhenNotMaster(t *testing.T) {
+	first, s := newFakeSOLIDserver(t)
+	defer first.Close()
+
+	second, _ := newFakeSOLIDserver(t)
+	defer second.Close()
+
+	second.SetRole("slave")
+
+	// Both fake nodes answer over plain HTTP
+	s.Hosts = []string{strings.TrimPrefix(first.URL, "http://"), strings.TrimPrefix(second.URL, "http://")}
+	s.selectEndpoint(context.Background())
+
+	if _, err := s.CreateSpace(context.Background(), SpaceSpec{Name: "office"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The first node stops being the master of the cluster
+	first.SetRole("slave")
+	second.SetRole("master")
+
+	if _, err := s.CreateSpace(context.Background(), SpaceSpec{Name: "lab"}); err != nil {
+		t.Fatalf("expected the call to be sent again to the new master, got %s", err)
+	}
+
+	if s.baseURL() != second.URL {
+		t.Errorf("expected the client to stick to the new master %s, got %s", second.URL, s.baseURL())
+	}
+
+	if len(first.Rows("ip_site", "site_name", "lab")) != 0 || len(second.Rows("ip_site", "site_name", "lab")) != 1 {
+		t.Errorf("expected the space to be created on the new master only")
+	}
+
+	// Without any other master the rejection is reported
+	second.SetRole("slave")
+
+	if _, err := s.CreateSpace(context.Background(), SpaceSpec{Name: "dmz"}); err == nil || !IsAPIError(err) {
+		t.Errorf("expected the not master answer to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package sdsclient
+
+import (
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
+	"testing"
+)
+
+// Start a fake SOLIDserver and a client connected to it, the server must be closed by the test
+func newFakeSOLIDserver(t *testing.T) (*sdsfake.Server, *SOLIDserver) {
+	f := sdsfake.New(t)
+
+	s, err := NewSOLIDserver("", sdsfake.Username, sdsfake.Password, false, "", SOLIDserverOptions{BaseURL: f.URL})
+
+	if err != nil {
+		f.Close()
+		t.Fatalf("unable to connect to the fake SOLIDserver: %s", err)
+	}
+
+	return f, s
+}
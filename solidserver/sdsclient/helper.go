@@ -0,0 +1,748 @@
+package sdsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Integer Absolute value
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// BigIntToHexStr convert a Big Integer into an Hexa String
+func BigIntToHexStr(bigInt *big.Int) string {
+	return fmt.Sprintf("%x", bigInt)
+}
+
+// BigIntToStr convert a Big Integer to Decimal String
+func BigIntToStr(bigInt *big.Int) string {
+	return fmt.Sprintf("%v", bigInt)
+}
+
+// Convert hexa IP v6 address string into standard IP v6 address string
+// Return an empty string in case of failure
+func hexiptoip(hexip string) string {
+	a, b, c, d := 0, 0, 0, 0
+
+	count, _ := fmt.Sscanf(hexip, "%02x%02x%02x%02x", &a, &b, &c, &d)
+
+	if count == 4 {
+		return fmt.Sprintf("%d.%d.%d.%d", a, b, c, d)
+	}
+
+	return ""
+}
+
+// Convert hexa IP v6 address string into standard IP v6 address string
+// Return an empty string in case of failure
+func hexip6toip6(hexip string) string {
+	res := ""
+
+	for i, c := range hexip {
+		if (i == 0) || ((i % 4) != 0) {
+			res += string(c)
+		} else {
+			res += ":"
+			res += string(c)
+		}
+	}
+
+	return res
+}
+
+// Convert standard IP address string into hexa IP address string
+// Return an empty string in case of failure
+func iptohexip(ip string) string {
+	ipDec := strings.Split(ip, ".")
+
+	if len(ipDec) == 4 {
+
+		a, _ := strconv.Atoi(ipDec[0])
+		b, _ := strconv.Atoi(ipDec[1])
+		c, _ := strconv.Atoi(ipDec[2])
+		d, _ := strconv.Atoi(ipDec[3])
+
+		if 0 <= a && a <= 255 && 0 <= b && b <= 255 &&
+			0 <= c && c <= 255 && 0 <= d && d <= 255 {
+			return fmt.Sprintf("%02x%02x%02x%02x", a, b, c, d)
+		}
+
+		return ""
+	}
+
+	return ""
+}
+
+// Convert standard IP v6 address string into hexa IP v6 address string
+// Return an empty string in case of failure
+func ip6tohexip6(ip string) string {
+	ipDec := strings.Split(ip, ":")
+	res := ""
+
+	if len(ipDec) == 8 {
+		for _, b := range ipDec {
+			res += fmt.Sprintf("%04s", b)
+		}
+
+		return res
+	}
+
+	return ""
+}
+
+// Convert standard IP address string into unsigned int32
+// Return 0 in case of failure
+func iptolong(ip string) uint32 {
+	ipDec := strings.Split(ip, ".")
+
+	if len(ipDec) == 4 {
+		a, _ := strconv.Atoi(ipDec[0])
+		b, _ := strconv.Atoi(ipDec[1])
+		c, _ := strconv.Atoi(ipDec[2])
+		d, _ := strconv.Atoi(ipDec[3])
+
+		var iplong uint32 = uint32(a) * 0x1000000
+		iplong += uint32(b) * 0x10000
+		iplong += uint32(c) * 0x100
+		iplong += uint32(d) * 0x1
+
+		return iplong
+	}
+
+	return 0
+}
+
+// Convert unsigned int32 into standard IP address string
+// Return an IP formated string
+func longtoip(iplong uint32) string {
+	a := (iplong & 0xFF000000) >> 24
+	b := (iplong & 0xFF0000) >> 16
+	c := (iplong & 0xFF00) >> 8
+	d := (iplong & 0xFF)
+
+	if a < 0 {
+		a = a + 0x100
+	}
+
+	return fmt.Sprintf("%d.%d.%d.%d", a, b, c, d)
+}
+
+// Compute the actual size of a CIDR prefix from its length
+// Return -1 in case of failure
+func prefixlengthtosize(length int) int {
+	if length >= 0 && length <= 32 {
+		return (1 << (32 - uint32(length)))
+	}
+
+	return -1
+}
+
+// Netmask computes the netmask of a CIDR prefix from its length
+// Return an empty string in case of failure
+func Netmask(length int) string {
+	if length >= 0 && length <= 32 {
+		return longtoip((^((1 << (32 - uint32(length))) - 1)) & 0xffffffff)
+	}
+
+	return ""
+}
+
+// Compute the actual size of an IPv6 CIDR prefix from its length
+// Return -1 in case of failure
+func prefix6lengthtosize(length int64) *big.Int {
+	sufix := big.NewInt(32 - (length / 4))
+	size := big.NewInt(16)
+
+	size = size.Exp(size, sufix, nil)
+
+	//size = size.Sub(size, big.NewInt(1))
+
+	return size
+}
+
+// Return the oid of a device from hostdev_name
+// Or an empty string in case of failure
+func hostdevidbyname(ctx context.Context, hostdevName string, s *SOLIDserver) (string, error) {
+	// Resolving through the lookup cache
+	return s.cache.lookup(ctx, cacheHostdev, []string{strings.ToLower(hostdevName)}, func() (string, error) {
+		// Building parameters
+		parameters := url.Values{}
+		where, whereErr := WhereEq("hostdev_name", strings.ToLower(hostdevName)).Clause()
+
+		if whereErr != nil {
+			// Reporting a failure
+			return "", whereErr
+		}
+
+		parameters.Add("WHERE", where)
+
+		// Sending the read request
+		it := s.List(ctx, "rest/hostdev_list", &parameters)
+
+		// Checking the answer
+		if it.Next() {
+			if hostdevID, hostdevIDExist := it.Row()["hostdev_id"].(string); hostdevIDExist {
+				return hostdevID, nil
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to find device: %s\n", hostdevName)
+
+		return "", it.Err()
+	})
+}
+
+// Return an available IP addresses from site_id, block_id and expected subnet_size
+// Or an empty table of string in case of failure
+func ipaddressfindfree(ctx context.Context, subnetID string, poolID string, s *SOLIDserver) ([]string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet_id", subnetID)
+	parameters.Add("max_find", "4")
+
+	// Only looking within the pool if any
+	if poolID != "" {
+		parameters.Add("pool_id", poolID)
+	}
+
+	// Sending the creation request
+	resp, body, err := s.Request(ctx, "get", "rpc/ip_find_free_address", &parameters)
+
+	if err == nil {
+		var buf [](map[string]interface{})
+		json.Unmarshal([]byte(body), &buf)
+
+		// Checking the answer
+		if resp.StatusCode == 200 && len(buf) > 0 {
+			addresses := []string{}
+
+			for i := 0; i < len(buf); i++ {
+				if addr, addrExist := buf[i]["hostaddr"].(string); addrExist {
+					log.Printf("[DEBUG] SOLIDServer - Suggested IP address: %s\n", addr)
+					addresses = append(addresses, addr)
+				}
+			}
+			return addresses, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find a free IP address in subnet (oid): %s\n", subnetID)
+
+	return []string{}, err
+}
+
+// Return an available IP addresses from site_id, block_id and expected subnet_size
+// Or an empty table of string in case of failure
+func ip6addressfindfree(ctx context.Context, subnetID string, s *SOLIDserver) ([]string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet6_id", subnetID)
+	parameters.Add("max_find", "4")
+
+	// Sending the creation request
+	resp, body, err := s.Request(ctx, "get", "rpc/ip6_find_free_address6", &parameters)
+
+	if err == nil {
+		var buf [](map[string]interface{})
+		json.Unmarshal([]byte(body), &buf)
+
+		// Checking the answer
+		if resp.StatusCode == 200 && len(buf) > 0 {
+			addresses := []string{}
+
+			for i := 0; i < len(buf); i++ {
+				if addr, addrExist := buf[i]["hostaddr6"].(string); addrExist {
+					log.Printf("[DEBUG] SOLIDServer - Suggested IP address: %s\n", addr)
+					addresses = append(addresses, addr)
+				}
+			}
+			return addresses, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find a free IP v6 address in subnet (oid): %s\n", subnetID)
+
+	return []string{}, err
+}
+
+// Return an available vlan from specified vlmdomain_name
+// Or an empty table strings in case of failure
+func vlanidfindfree(ctx context.Context, vlmdomainName string, s *SOLIDserver) ([]string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("limit", "4")
+
+	free := WhereEq("type", "free")
+
+	if !s.Supports(CapabilityFreeVlanRanges) {
+		free = WhereEq("row_enabled", "2")
+	}
+
+	where, whereErr := WhereAnd(WhereEq("vlmdomain_name", strings.ToLower(vlmdomainName)), free).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the creation request
+	resp, body, err := s.Request(ctx, "get", "rest/vlmvlan_list", &parameters)
+
+	if err == nil {
+		var buf [](map[string]interface{})
+		json.Unmarshal([]byte(body), &buf)
+
+		// Checking the answer
+		if resp.StatusCode == 200 && len(buf) > 0 {
+			vnIDs := []string{}
+
+			for i := range buf {
+				if !s.Supports(CapabilityFreeVlanRanges) {
+					if vnID, vnIDExist := buf[i]["vlmvlan_vlan_id"].(string); vnIDExist {
+						log.Printf("[DEBUG] SOLIDServer - Suggested vlan ID: %s\n", vnID)
+						vnIDs = append(vnIDs, vnID)
+					}
+				} else {
+					if startVlanID, startVlanIDExist := buf[i]["free_start_vlan_id"].(string); startVlanIDExist {
+						if endVlanID, endVlanIDExist := buf[i]["free_end_vlan_id"].(string); endVlanIDExist {
+							vnID, _ := strconv.Atoi(startVlanID)
+							maxVnID, _ := strconv.Atoi(endVlanID)
+
+							j := 0
+							for vnID <= maxVnID && j < 8 {
+								log.Printf("[DEBUG] SOLIDServer - Suggested vlan ID: %d\n", vnID)
+								vnIDs = append(vnIDs, strconv.Itoa(vnID))
+								vnID++
+								j++
+							}
+						}
+					}
+				}
+			}
+			return vnIDs, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find a free vlan ID in vlan domain: %s\n", vlmdomainName)
+
+	return []string{}, err
+}
+
+// Return the oid of a space from site_name
+// Or an empty string in case of failure
+func ipsiteidbyname(ctx context.Context, siteName string, s *SOLIDserver) (string, error) {
+	// Resolving through the lookup cache
+	return s.cache.lookup(ctx, cacheIPSite, []string{strings.ToLower(siteName)}, func() (string, error) {
+		// Building parameters
+		parameters := url.Values{}
+		where, whereErr := WhereEq("site_name", strings.ToLower(siteName)).Clause()
+
+		if whereErr != nil {
+			// Reporting a failure
+			return "", whereErr
+		}
+
+		parameters.Add("WHERE", where)
+
+		// Sending the read request
+		it := s.List(ctx, "rest/ip_site_list", &parameters)
+
+		// Checking the answer
+		if it.Next() {
+			if siteID, siteIDExist := it.Row()["site_id"].(string); siteIDExist {
+				return siteID, nil
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to find IP space: %s\n", siteName)
+
+		return "", it.Err()
+	})
+}
+
+// Return the oid of a vlan domain from vlmdomain_name
+// Or an empty string in case of failure
+func vlandomainidbyname(ctx context.Context, vlmdomainName string, s *SOLIDserver) (string, error) {
+	// Resolving through the lookup cache
+	return s.cache.lookup(ctx, cacheVlmdomain, []string{strings.ToLower(vlmdomainName)}, func() (string, error) {
+		// Building parameters
+		parameters := url.Values{}
+		where, whereErr := WhereEq("vlmdomain_name", strings.ToLower(vlmdomainName)).Clause()
+
+		if whereErr != nil {
+			// Reporting a failure
+			return "", whereErr
+		}
+
+		parameters.Add("WHERE", where)
+
+		// Sending the read request
+		it := s.List(ctx, "rest/vlmdomain_name", &parameters)
+
+		// Checking the answer
+		if it.Next() {
+			if vlmdomainID, vlmdomainIDExist := it.Row()["vlmdomain_id"].(string); vlmdomainIDExist {
+				return vlmdomainID, nil
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to find vlan domain: %s\n", vlmdomainName)
+
+		return "", it.Err()
+	})
+}
+
+// Return the oid of a subnet from site_id, subnet_name and is_terminal property
+// Or an empty string in case of failure
+func ipsubnetidbyname(ctx context.Context, siteID string, subnetName string, terminal bool, s *SOLIDserver) (string, error) {
+	// Resolving through the lookup cache
+	return s.cache.lookup(ctx, cacheIPSubnet, []string{siteID, strings.ToLower(subnetName), strconv.FormatBool(terminal)}, func() (string, error) {
+		// Building parameters
+		parameters := url.Values{}
+		where, whereErr := WhereAnd(WhereEq("site_id", siteID), WhereEq("subnet_name", strings.ToLower(subnetName))).Clause()
+
+		if whereErr != nil {
+			// Reporting a failure
+			return "", whereErr
+		}
+
+		parameters.Add("WHERE", where)
+		if terminal {
+			parameters.Add("is_terminal", "1")
+		} else {
+			parameters.Add("is_terminal", "0")
+		}
+
+		// Sending the read request
+		it := s.List(ctx, "rest/ip_block_subnet_list", &parameters)
+
+		// Checking the answer
+		if it.Next() {
+			if subnetID, subnetIDExist := it.Row()["subnet_id"].(string); subnetIDExist {
+				return subnetID, nil
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to find IP subnet: %s\n", subnetName)
+
+		return "", it.Err()
+	})
+}
+
+// Return the oid of a subnet from site_id, subnet_name and is_terminal property
+// Or an empty string in case of failure
+func ip6subnetidbyname(ctx context.Context, siteID string, subnetName string, terminal bool, s *SOLIDserver) (string, error) {
+	// Resolving through the lookup cache
+	return s.cache.lookup(ctx, cacheIP6Subnet, []string{siteID, strings.ToLower(subnetName), strconv.FormatBool(terminal)}, func() (string, error) {
+		// Building parameters
+		parameters := url.Values{}
+		where, whereErr := WhereAnd(WhereEq("site_id", siteID), WhereEq("subnet6_name", strings.ToLower(subnetName))).Clause()
+
+		if whereErr != nil {
+			// Reporting a failure
+			return "", whereErr
+		}
+
+		parameters.Add("WHERE", where)
+		if terminal {
+			parameters.Add("is_terminal", "1")
+		} else {
+			parameters.Add("is_terminal", "0")
+		}
+
+		// Sending the read request
+		it := s.List(ctx, "rest/ip6_block6_subnet6_list", &parameters)
+
+		// Checking the answer
+		if it.Next() {
+			if subnetID, subnetIDExist := it.Row()["subnet6_id"].(string); subnetIDExist {
+				return subnetID, nil
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 subnet: %s\n", subnetName)
+
+		return "", it.Err()
+	})
+}
+
+// Return the oid of a pool from subnet_id and pool_name
+// Or an empty string in case of failure
+func ippoolidbyname(ctx context.Context, subnetID string, poolName string, s *SOLIDserver) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereAnd(WhereEq("subnet_id", subnetID), WhereEq("pool_name", poolName)).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return "", whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	it := s.List(ctx, "rest/ip_pool_list", &parameters)
+
+	// Checking the answer
+	if it.Next() {
+		if poolID, poolIDExist := it.Row()["pool_id"].(string); poolIDExist {
+			return poolID, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find IP pool: %s\n", poolName)
+
+	return "", it.Err()
+}
+
+// Return the oid of a pool from subnet6_id and pool6_name
+// Or an empty string in case of failure
+func ip6poolidbyname(ctx context.Context, subnetID string, poolName string, s *SOLIDserver) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereAnd(WhereEq("subnet6_id", subnetID), WhereEq("pool6_name", poolName)).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return "", whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	it := s.List(ctx, "rest/ip6_pool6_list", &parameters)
+
+	// Checking the answer
+	if it.Next() {
+		if poolID, poolIDExist := it.Row()["pool6_id"].(string); poolIDExist {
+			return poolID, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 pool: %s\n", poolName)
+
+	return "", it.Err()
+}
+
+// Return the oid of an address from site_id, ip_address
+// Or an empty string in case of failure
+func ipaddressidbyip(ctx context.Context, siteID string, ipAddress string, s *SOLIDserver) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereAnd(WhereEq("site_id", siteID), WhereEq("ip_addr", iptohexip(ipAddress))).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return "", whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	it := s.List(ctx, "rest/ip_address_list", &parameters)
+
+	// Checking the answer
+	if it.Next() {
+		if ipID, ipIDExist := it.Row()["ip_id"].(string); ipIDExist {
+			return ipID, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find IP address: %s\n", ipAddress)
+
+	return "", it.Err()
+}
+
+// Return the oid of an address from site_id, ip_address
+// Or an empty string in case of failure
+func ip6addressidbyip6(ctx context.Context, siteID string, ipAddress string, s *SOLIDserver) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereAnd(WhereEq("site_id", siteID), WhereEq("ip6_addr", ip6tohexip6(ipAddress))).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return "", whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	it := s.List(ctx, "rest/ip6_address6_list", &parameters)
+
+	// Checking the answer
+	if it.Next() {
+		if ipID, ipIDExist := it.Row()["ip6_id"].(string); ipIDExist {
+			return ipID, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find IP v6 address: %s\n", ipAddress)
+
+	return "", it.Err()
+}
+
+// Return the oid of an address from ip_id, ip_name_type, alias_name
+// Or an empty string in case of failure
+func ipaliasidbyinfo(ctx context.Context, addressID string, aliasName string, ipNameType string, s *SOLIDserver) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", addressID)
+	// Bug - Ticket 18653
+	// parameters.Add("WHERE", WhereAnd(WhereEq("ip_name_type", ipNameType), WhereEq("alias_name", aliasName)))
+
+	// Sending the read request
+	it := s.List(ctx, "rest/ip_alias_list", &parameters)
+
+	// Shall be removed once Ticket 18653 is closed
+	// Checking the answer
+	for it.Next() {
+		r_ip_name_id, r_ip_name_id_exist := it.Row()["ip_name_id"].(string)
+		r_ip_name_type, r_ip_name_type_exist := it.Row()["ip_name_type"].(string)
+		r_alias_name, r_alias_name_exist := it.Row()["alias_name"].(string)
+
+		log.Printf("[DEBUG] SOLIDServer - Comparing '%s' with '%s' looking for IP alias associated with IP address ID %s\n", aliasName, r_alias_name, addressID)
+		log.Printf("[DEBUG] SOLIDServer - Comparing '%s' with '%s' looking for IP alias associated with IP address ID %s\n", ipNameType, r_ip_name_type, addressID)
+
+		if r_ip_name_type_exist && strings.Compare(ipNameType, r_ip_name_type) == 0 &&
+			r_alias_name_exist && strings.Compare(aliasName, r_alias_name) == 0 &&
+			r_ip_name_id_exist {
+			return r_ip_name_id, nil
+		}
+	}
+
+	// Shall be restored once Ticket 18653 is closed
+	// Checking the answer
+	//if (resp.StatusCode == 200 && len(buf) > 0) {
+	//  if ip_name_id, ip_name_id_exist := buf[0]["ip_name_id"].(string); (ip_name_id_exist) {
+	//    return ip_name_id
+	//  }
+	//}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find IP alias: %s - %s associated with IP address ID %s\n", aliasName, ipNameType, addressID)
+
+	return "", it.Err()
+}
+
+// Return an available subnet address from site_id, block_id and expected subnet_size
+// Or an empty string in case of failure
+func ipsubnetfindbysize(ctx context.Context, siteID string, blockID string, requestedIP string, prefixSize int, s *SOLIDserver) ([]string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("prefix", strconv.Itoa(prefixSize))
+	parameters.Add("max_find", "4")
+
+	// Trying to create a block
+	if len(blockID) == 0 {
+		subnetAddresses := []string{}
+
+		if len(requestedIP) > 0 {
+			subnetAddresses = append(subnetAddresses, iptohexip(requestedIP))
+			return subnetAddresses, nil
+		}
+
+		return subnetAddresses, nil
+	}
+
+	// Trying to create a subnet under an existing block
+	parameters.Add("block_id", blockID)
+
+	// Specifying a suggested subnet IP address
+	if len(requestedIP) > 0 {
+		parameters.Add("begin_addr", requestedIP)
+	}
+
+	// Sending the creation request
+	resp, body, err := s.Request(ctx, "get", "rpc/ip_find_free_subnet", &parameters)
+
+	if err == nil {
+		var buf [](map[string]interface{})
+		json.Unmarshal([]byte(body), &buf)
+
+		// Checking the answer
+		if resp.StatusCode == 200 && len(buf) > 0 {
+			subnetAddresses := []string{}
+
+			for i := 0; i < len(buf); i++ {
+				if hexaddr, hexaddr_exist := buf[i]["start_ip_addr"].(string); hexaddr_exist {
+					log.Printf("[DEBUG] SOLIDServer - Suggested IP subnet address: %s\n", hexiptoip(hexaddr))
+					subnetAddresses = append(subnetAddresses, hexaddr)
+				}
+			}
+			return subnetAddresses, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find a free IP subnet in space (oid): %s, block (oid): %s, size: %s\n", siteID, blockID, strconv.Itoa(prefixSize))
+
+	return []string{}, err
+}
+
+// Return an available subnet address from site_id, block_id and expected subnet_size
+// Or an empty string in case of failure
+func ip6subnetfindbysize(ctx context.Context, siteID string, blockID string, requestedIP string, prefixSize int, s *SOLIDserver) ([]string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("prefix", strconv.Itoa(prefixSize))
+	parameters.Add("max_find", "4")
+
+	// Trying to create a block
+	if len(blockID) == 0 {
+		subnetAddresses := []string{}
+
+		if len(requestedIP) > 0 {
+			subnetAddresses = append(subnetAddresses, ip6tohexip6(requestedIP))
+			return subnetAddresses, nil
+		}
+
+		return subnetAddresses, nil
+	}
+
+	// Trying to create a subnet under an existing block
+	parameters.Add("block6_id", blockID)
+
+	// Specifying a suggested subnet IP address
+	if len(requestedIP) > 0 {
+		parameters.Add("begin_addr", requestedIP)
+	}
+
+	// Sending the creation request
+	resp, body, err := s.Request(ctx, "get", "rpc/ip6_find_free_subnet6", &parameters)
+
+	if err == nil {
+		var buf [](map[string]interface{})
+		json.Unmarshal([]byte(body), &buf)
+
+		// Checking the answer
+		if resp.StatusCode == 200 && len(buf) > 0 {
+			subnetAddresses := []string{}
+
+			for i := 0; i < len(buf); i++ {
+				if hexaddr, hexaddr_exist := buf[i]["start_ip6_addr"].(string); hexaddr_exist {
+					log.Printf("[DEBUG] SOLIDServer - Suggested IP v6 subnet address: %s\n", hexip6toip6(hexaddr))
+					subnetAddresses = append(subnetAddresses, hexaddr)
+				}
+			}
+			return subnetAddresses, nil
+		}
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find a free IP v6 subnet in space (oid): %s, block (oid): %s, size: %s\n", siteID, blockID, strconv.Itoa(prefixSize))
+
+	return []string{}, err
+}
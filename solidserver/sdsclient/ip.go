@@ -0,0 +1,1484 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/bits"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time given to delete the addresses of an IP address block which could not be fully created
+// The rollback does not depend on the creation's context, which may have expired or been canceled
+const addressBlockRollbackTimeout = 2 * time.Minute
+
+// Space is an IP space
+type Space struct {
+	ID              string
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// SpaceSpec describes an IP space to create or update
+type SpaceSpec struct {
+	Name            string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// Subnet is an IP block or subnet
+type Subnet struct {
+	ID              string
+	Space           string
+	Block           string
+	Name            string
+	Address         string
+	PrefixLength    int
+	Terminal        bool
+	Gateway         string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// SubnetSpec describes an IP block or subnet to create or update
+// The subnet is created in the first candidate block with enough room: Block,
+// else each of Blocks in turn, else each block whose class parameters include
+// BlockClassParameters. Without any, an IP block is created at the root of the space
+type SubnetSpec struct {
+	Space                string
+	Block                string
+	Blocks               []string
+	BlockClassParameters map[string]string
+	RequestIP            string
+	PrefixLength         int
+	Name                 string
+	Terminal             bool
+	GatewayOffset        int
+	Gateway              string
+	Class                string
+	ClassParameters      map[string]string
+}
+
+// AddressRange is a range of Size addresses of a subnet reserved under Name, starting at
+// Offset, counted back from the end of the subnet when negative as a gateway offset
+type AddressRange struct {
+	Name   string
+	Offset int
+	Size   int
+}
+
+// Address is an IP address
+type Address struct {
+	ID              string
+	Space           string
+	Subnet          string
+	Address         string
+	Name            string
+	MAC             string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// AddressSpec describes an IP address to create or update
+// Without RequestIP, a free address of the subnet is used, only within Pool if any
+type AddressSpec struct {
+	Space           string
+	Subnet          string
+	Pool            string
+	RequestIP       string
+	Name            string
+	MAC             string
+	Device          string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// AddressBlockSpec describes contiguous IP addresses to create in a terminal subnet, within Pool if any
+// Each address is named after NameTemplate formatted with its index in the block, starting at 1 (ex: node-%02d)
+type AddressBlockSpec struct {
+	Space           string
+	Subnet          string
+	Pool            string
+	Size            int
+	NameTemplate    string
+	Class           string
+	ClassParameters map[string]string
+}
+
+// Pool is a range of IP addresses of a subnet
+type Pool struct {
+	ID              string
+	Space           string
+	Subnet          string
+	Name            string
+	Start           string
+	End             string
+	Size            int
+	ReadOnly        bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// PoolSpec describes an IP pool to create or update
+// The pool spans from Start to End, or over Size addresses when End is empty
+type PoolSpec struct {
+	Space           string
+	Subnet          string
+	Name            string
+	Start           string
+	End             string
+	Size            int
+	ReadOnly        bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// Alias is an alias (A or CNAME) of an IP address
+type Alias struct {
+	ID      string
+	Space   string
+	Address string
+	Name    string
+	Type    string
+}
+
+// AliasSpec describes an alias of an IP address to create
+type AliasSpec struct {
+	Space   string
+	Address string
+	Name    string
+	Type    string
+}
+
+// CreateSpace creates an IP space
+func (s *SOLIDserver) CreateSpace(ctx context.Context, spec SpaceSpec) (*Space, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("add_flag", "new_only")
+	parameters.Add("site_name", spec.Name)
+	parameters.Add("site_class_name", spec.Class)
+	parameters.Add("site_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/ip_site_add", &parameters, nil, "SOLIDServer - Unable to create space: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created space (oid): %s\n", oid)
+	s.cache.invalidate(cacheIPSite, strings.ToLower(spec.Name))
+
+	return &Space{ID: oid, Name: spec.Name, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// GetSpace reads an IP space from its oid
+func (s *SOLIDserver) GetSpace(ctx context.Context, id string) (*Space, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_site_info", &parameters, "SOLIDServer - Unable to find space (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Space{
+		ID:              id,
+		Name:            rowstring(row, "site_name"),
+		Class:           rowstring(row, "site_class_name"),
+		ClassParameters: rowclassparams(row, "site_class_parameters"),
+	}, nil
+}
+
+// GetSpaceByName reads an IP space from its name
+func (s *SOLIDserver) GetSpaceByName(ctx context.Context, name string) (*Space, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereEq("site_name", name).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_site_list", &parameters, "SOLIDServer - Unable to find space: %s", name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Space{
+		ID:              rowstring(row, "site_id"),
+		Name:            rowstring(row, "site_name"),
+		Class:           rowstring(row, "site_class_name"),
+		ClassParameters: rowclassparams(row, "site_class_parameters"),
+	}, nil
+}
+
+// UpdateSpace updates an IP space
+func (s *SOLIDserver) UpdateSpace(ctx context.Context, id string, spec SpaceSpec) (*Space, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("site_name", spec.Name)
+	parameters.Add("site_class_name", spec.Class)
+	parameters.Add("site_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip_site_add", &parameters, nil, "SOLIDServer - Unable to update space: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated space (oid): %s\n", oid)
+	s.cache.forget(cacheIPSite, oid)
+
+	return &Space{ID: oid, Name: spec.Name, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// DeleteSpace deletes an IP space along with its subnets and addresses
+func (s *SOLIDserver) DeleteSpace(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_site_delete", &parameters, "SOLIDServer - Unable to delete space (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted space (oid): %s\n", id)
+	s.cache.forget(cacheIPSite, id)
+	s.cache.invalidate(cacheIPSubnet, id)
+	s.cache.invalidate(cacheIP6Subnet, id)
+
+	return nil
+}
+
+// SubnetGateway returns the gateway of a subnet from its address, prefix length and gateway offset
+// A negative offset is counted back from the end of the subnet
+func SubnetGateway(address string, prefixLength int, offset int) string {
+	if offset > 0 {
+		return longtoip(iptolong(address) + uint32(offset))
+	}
+
+	return longtoip(iptolong(address) + uint32(prefixlengthtosize(prefixLength)) - uint32(abs(offset)) - 1)
+}
+
+// Return the names of the blocks into which a subnet may be created, in order
+// An empty name stands for the root of the space
+func (s *SOLIDserver) subnetblocks(ctx context.Context, siteID string, spec SubnetSpec) ([]string, error) {
+	if len(spec.Block) > 0 {
+		return []string{spec.Block}, nil
+	}
+
+	if len(spec.Blocks) > 0 {
+		return spec.Blocks, nil
+	}
+
+	if len(spec.BlockClassParameters) == 0 {
+		return []string{""}, nil
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereEq("site_id", siteID).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	parameters.Add("WHERE", where)
+	parameters.Add("is_terminal", "0")
+	parameters.Add("ORDERBY", "subnet_name")
+
+	// Sending the read request
+	rows, err := s.ListAll(ctx, "rest/ip_block_subnet_list", &parameters)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	blocks := []string{}
+
+	// Checking the class parameters of each block
+	for _, row := range rows {
+		classParameters := rowclassparams(row, "subnet_class_parameters")
+		match := true
+
+		for k, v := range spec.BlockClassParameters {
+			if classParameters[k] != v {
+				match = false
+			}
+		}
+
+		if match {
+			blocks = append(blocks, rowstring(row, "subnet_name"))
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("SOLIDServer - Unable to find any IP block matching the class parameters: %s", encodeclassparams(spec.BlockClassParameters).Encode())
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - IP blocks matching the class parameters: %s\n", strings.Join(blocks, ", "))
+
+	return blocks, nil
+}
+
+// CreateSubnet creates an IP block or subnet
+// The first free subnet of the requested size is used within the first candidate block having one
+func (s *SOLIDserver) CreateSubnet(ctx context.Context, spec SubnetSpec) (*Subnet, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	blocks, blocksErr := s.subnetblocks(ctx, siteID, spec)
+
+	if blocksErr != nil {
+		// Reporting a failure
+		return nil, blocksErr
+	}
+
+	var failure error = nil
+
+	for _, block := range blocks {
+		subnet, blockFailure, err := s.createsubnetin(ctx, siteID, block, spec)
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		if subnet != nil {
+			return subnet, nil
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Unable to create IP subnet %s in block '%s', trying the next one (%s)\n", spec.Name, block, blockFailure)
+		failure = blockFailure
+	}
+
+	if len(blocks) > 1 {
+		return nil, WrapError(failure, "SOLIDServer - Unable to create IP subnet %s in any of the blocks %s", spec.Name, strings.Join(blocks, ", "))
+	}
+
+	// Reporting a failure
+	return nil, failure
+}
+
+// Create an IP subnet within the given block, or an IP block if none is given
+// Return the subnet, or why it could not be created within this block, or an error
+// preventing any further attempt
+func (s *SOLIDserver) createsubnetin(ctx context.Context, siteID string, block string, spec SubnetSpec) (*Subnet, error, error) {
+	var blockID string = ""
+
+	// If a block is specified, look for free IP subnet within this block
+	if len(block) > 0 {
+		var blockErr error = nil
+
+		blockID, blockErr = ipsubnetidbyname(ctx, siteID, block, false, s)
+
+		if blockErr != nil {
+			// Reporting a failure
+			return nil, nil, blockErr
+		}
+
+		if blockID == "" {
+			return nil, fmt.Errorf("SOLIDServer - Unable to find IP block: %s", block), nil
+		}
+	} else {
+		// However, we can't create a block as a terminal subnet
+		if spec.Terminal {
+			return nil, nil, fmt.Errorf("SOLIDServer - Can't create a terminal IP block subnet: %s", spec.Name)
+		}
+	}
+
+	subnetAddresses, subnetErr := ipsubnetfindbysize(ctx, siteID, blockID, spec.RequestIP, spec.PrefixLength, s)
+
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, nil, subnetErr
+	}
+
+	failure := fmt.Errorf("SOLIDServer - Unable to create IP subnet: %s", spec.Name)
+
+	for i := 0; i < len(subnetAddresses); i++ {
+		address := hexiptoip(subnetAddresses[i])
+		gateway := spec.Gateway
+
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("site_id", siteID)
+		parameters.Add("subnet_name", spec.Name)
+		parameters.Add("subnet_addr", address)
+		parameters.Add("subnet_prefix", strconv.Itoa(spec.PrefixLength))
+		parameters.Add("subnet_class_name", spec.Class)
+
+		// New only
+		parameters.Add("add_flag", "new_only")
+
+		// If no block specified, create an IP block
+		if len(block) == 0 {
+			parameters.Add("subnet_level", "0")
+		}
+
+		// Specify if subnet is terminal
+		parameters.Add("is_terminal", boolflag(spec.Terminal))
+
+		// Building class_parameters
+		classParameters := encodeclassparams(spec.ClassParameters)
+
+		// Generate class parameter for the gateway if required
+		if gateway == "" && spec.GatewayOffset != 0 {
+			gateway = SubnetGateway(address, spec.PrefixLength, spec.GatewayOffset)
+		}
+
+		if gateway != "" {
+			classParameters.Set("gateway", gateway)
+			log.Printf("[DEBUG] SOLIDServer - Subnet computed gateway: %s\n", gateway)
+		}
+
+		parameters.Add("subnet_class_parameters", classParameters.Encode())
+
+		// Sending the creation request, only retried once the subnet is known not to be created
+		resp, body, buf, err := s.call(ctx, "post", "rest/ip_subnet_add", &parameters, func() (bool, error) {
+			subnetID, lookupErr := ipsubnetidbyname(ctx, siteID, spec.Name, spec.Terminal, s)
+			return subnetID != "", lookupErr
+		})
+
+		if err != nil {
+			// Reporting a failure
+			return nil, nil, err
+		}
+
+		// Checking the answer
+		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				log.Printf("[DEBUG] SOLIDServer - Created IP subnet (oid): %s\n", oid)
+				s.cache.invalidate(cacheIPSubnet, siteID, strings.ToLower(spec.Name))
+
+				// Reserving the gateway address, as MoveSubnetGateway does
+				if gateway != "" {
+					gatewayID, gatewayErr := s.reserveaddress(ctx, siteID, gateway, "gateway")
+
+					if gatewayErr != nil {
+						// Deleting the subnet, which can't be left without its gateway
+						if deleteErr := s.DeleteSubnet(ctx, oid); deleteErr != nil {
+							log.Printf("[DEBUG] SOLIDServer - Unable to delete IP subnet (oid): %s (%s)\n", oid, deleteErr)
+						}
+
+						// Reporting a failure
+						return nil, nil, gatewayErr
+					}
+
+					log.Printf("[DEBUG] SOLIDServer - Reserved IP subnet gateway %s (oid): %s\n", gateway, gatewayID)
+				}
+
+				return &Subnet{
+					ID:              oid,
+					Space:           spec.Space,
+					Block:           block,
+					Name:            spec.Name,
+					Address:         address,
+					PrefixLength:    spec.PrefixLength,
+					Terminal:        spec.Terminal,
+					Gateway:         gateway,
+					Class:           spec.Class,
+					ClassParameters: spec.ClassParameters,
+				}, nil, nil
+			}
+		} else {
+			failure = apierrorf("rest/ip_subnet_add", resp, body, "SOLIDServer - Unable to create IP subnet: %s", spec.Name)
+			log.Printf("[DEBUG] SOLIDServer - Failed IP subnet registration, trying another one.\n")
+		}
+	}
+
+	// Reporting a failure
+	return nil, failure, nil
+}
+
+// GetSubnet reads an IP block or subnet from its oid
+func (s *SOLIDserver) GetSubnet(ctx context.Context, id string) (*Subnet, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_block_subnet_info", &parameters, "SOLIDServer - Unable to find IP subnet (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	subnet := &Subnet{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Block:           rowstring(row, "parent_subnet_name"),
+		Name:            rowstring(row, "subnet_name"),
+		Terminal:        rowstring(row, "is_terminal") == "1",
+		Class:           rowstring(row, "subnet_class_name"),
+		ClassParameters: rowclassparams(row, "subnet_class_parameters"),
+	}
+
+	if start := rowstring(row, "start_ip_addr"); start != "" {
+		subnet.Address = hexiptoip(start)
+	}
+
+	// The subnet size is a number of addresses
+	if size := rowint(row, "subnet_size"); size > 0 {
+		subnet.PrefixLength = 33 - bits.Len(uint(size))
+	}
+
+	subnet.Gateway = subnet.ClassParameters["gateway"]
+
+	return subnet, nil
+}
+
+// UpdateSubnet updates the name, class and class parameters of an IP block or subnet
+func (s *SOLIDserver) UpdateSubnet(ctx context.Context, id string, spec SubnetSpec) (*Subnet, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet_id", id)
+	parameters.Add("subnet_name", spec.Name)
+	parameters.Add("subnet_class_name", spec.Class)
+
+	// Edit only
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("is_terminal", boolflag(spec.Terminal))
+
+	// Building class_parameters
+	classParameters := encodeclassparams(spec.ClassParameters)
+
+	// Keeping the gateway class parameter if required
+	if spec.Gateway != "" {
+		classParameters.Set("gateway", spec.Gateway)
+		log.Printf("[DEBUG] SOLIDServer - Subnet updated gateway: %s\n", spec.Gateway)
+	}
+
+	parameters.Add("subnet_class_parameters", classParameters.Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip_subnet_add", &parameters, nil, "SOLIDServer - Unable to update IP subnet: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP subnet (oid): %s\n", oid)
+	s.cache.forget(cacheIPSubnet, oid)
+
+	return &Subnet{
+		ID:              oid,
+		Space:           spec.Space,
+		Block:           spec.Block,
+		Name:            spec.Name,
+		Terminal:        spec.Terminal,
+		Gateway:         spec.Gateway,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// MoveSubnetGateway updates an IP subnet whose gateway moves from the previous address
+// to spec.Gateway, either of which can be empty. The new gateway address is reserved
+// before the subnet is updated, the previous one is released afterwards, it must be a
+// gateway reserved by CreateSubnet or MoveSubnetGateway
+// The updated subnet is returned along with the error when the previous gateway could not be released
+func (s *SOLIDserver) MoveSubnetGateway(ctx context.Context, id string, spec SubnetSpec, previous string) (*Subnet, error) {
+	if spec.Gateway == previous {
+		return s.UpdateSubnet(ctx, id, spec)
+	}
+
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	if spec.Gateway != "" {
+		oid, err := s.reserveaddress(ctx, siteID, spec.Gateway, "gateway")
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Reserved IP subnet gateway %s (oid): %s\n", spec.Gateway, oid)
+	}
+
+	subnet, err := s.UpdateSubnet(ctx, id, spec)
+
+	if err != nil {
+		// Releasing the new gateway, the subnet still uses the previous one
+		if spec.Gateway != "" {
+			if releaseErr := s.DeleteAddressByIP(ctx, spec.Space, spec.Gateway); releaseErr != nil {
+				log.Printf("[DEBUG] SOLIDServer - Unable to release IP subnet's gateway : %s (%s)\n", spec.Gateway, releaseErr)
+			}
+		}
+
+		// Reporting a failure
+		return nil, err
+	}
+
+	if previous != "" {
+		// The previous gateway may already have been released
+		if err := s.DeleteAddressByIP(ctx, spec.Space, previous); err != nil && !IsNotFound(err) {
+			// Reporting a failure
+			return subnet, WrapError(err, "SOLIDServer - Unable to release IP subnet's previous gateway: %s", previous)
+		}
+	}
+
+	return subnet, nil
+}
+
+// Reserve an IP address under a name
+func (s *SOLIDserver) reserveaddress(ctx context.Context, siteID string, address string, name string) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("hostaddr", address)
+	parameters.Add("name", name)
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request, only retried once the address is known not to be reserved
+	return s.add(ctx, "post", "rest/ip_add", &parameters, func() (bool, error) {
+		ipID, lookupErr := ipaddressidbyip(ctx, siteID, address, s)
+		return ipID != "", lookupErr
+	}, "SOLIDServer - Unable to reserve IP address %s as: %s", address, name)
+}
+
+// Return the addresses of a range of an IP subnet, which must fit within
+// the addresses of the subnet, network and broadcast addresses excluded
+func subnetrange(address string, prefixLength int, r AddressRange) ([]string, error) {
+	if r.Offset == 0 || r.Size < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Invalid reserved range %s, its offset must not be 0 and its size at least 1", r.Name)
+	}
+
+	network := int64(iptolong(address))
+	broadcast := network + int64(prefixlengthtosize(prefixLength)) - 1
+	first := network + int64(r.Offset)
+
+	if r.Offset < 0 {
+		first = broadcast + int64(r.Offset)
+	}
+
+	if first <= network || first+int64(r.Size) > broadcast {
+		return nil, fmt.Errorf("SOLIDServer - Reserved range %s does not fit in IP subnet %s/%d", r.Name, address, prefixLength)
+	}
+
+	addresses := make([]string, 0, r.Size)
+
+	for i := int64(0); i < int64(r.Size); i++ {
+		addresses = append(addresses, longtoip(uint32(first+i)))
+	}
+
+	return addresses, nil
+}
+
+// ReserveSubnetRanges reserves every address of the ranges of an IP subnet under the name of
+// its range, so they are never found free. On failure, the addresses reserved so far are released
+func (s *SOLIDserver) ReserveSubnetRanges(ctx context.Context, subnet *Subnet, ranges []AddressRange) error {
+	addresses := make([][]string, len(ranges))
+
+	for i, r := range ranges {
+		var rangeErr error = nil
+
+		addresses[i], rangeErr = subnetrange(subnet.Address, subnet.PrefixLength, r)
+
+		if rangeErr != nil {
+			// Reporting a failure
+			return rangeErr
+		}
+	}
+
+	siteID, siteErr := ipsiteidbyname(ctx, subnet.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return siteErr
+	}
+
+	reserved := []string{}
+
+	for i, r := range ranges {
+		for _, address := range addresses[i] {
+			if _, err := s.reserveaddress(ctx, siteID, address, r.Name); err != nil {
+				// Releasing the addresses reserved so far
+				for _, release := range reserved {
+					if releaseErr := s.DeleteAddressByIP(ctx, subnet.Space, release); releaseErr != nil {
+						log.Printf("[DEBUG] SOLIDServer - Unable to release reserved IP address : %s (%s)\n", release, releaseErr)
+					}
+				}
+
+				// Reporting a failure
+				return WrapError(err, "SOLIDServer - Unable to reserve range %s of IP subnet %s", r.Name, subnet.Name)
+			}
+
+			reserved = append(reserved, address)
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Reserved range %s of IP subnet %s: %d address(es) from %s\n", r.Name, subnet.Name, r.Size, addresses[i][0])
+	}
+
+	return nil
+}
+
+// ReleaseSubnetRanges releases the addresses of ranges reserved in an IP subnet,
+// the ones which are not reserved anymore are skipped
+func (s *SOLIDserver) ReleaseSubnetRanges(ctx context.Context, subnet *Subnet, ranges []AddressRange) error {
+	for _, r := range ranges {
+		addresses, rangeErr := subnetrange(subnet.Address, subnet.PrefixLength, r)
+
+		if rangeErr != nil {
+			log.Printf("[DEBUG] SOLIDServer - Unable to release range %s of IP subnet %s (%s)\n", r.Name, subnet.Name, rangeErr)
+			continue
+		}
+
+		for _, address := range addresses {
+			if err := s.DeleteAddressByIP(ctx, subnet.Space, address); err != nil {
+				if !IsAPIError(err) {
+					// Reporting a failure
+					return err
+				}
+
+				log.Printf("[DEBUG] SOLIDServer - Unable to release reserved IP address : %s (%s)\n", address, err)
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Released range %s of IP subnet: %s\n", r.Name, subnet.Name)
+	}
+
+	return nil
+}
+
+// DeleteSubnet deletes an IP block or subnet
+func (s *SOLIDserver) DeleteSubnet(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_subnet_delete", &parameters, "SOLIDServer - Unable to delete IP subnet (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP subnet (oid): %s\n", id)
+	s.cache.forget(cacheIPSubnet, id)
+
+	return nil
+}
+
+// CreateAddress registers an IP address in a terminal subnet
+func (s *SOLIDserver) CreateAddress(ctx context.Context, spec AddressSpec) (*Address, error) {
+	var ipAddresses []string = nil
+	var deviceID string = ""
+	var poolID string = ""
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	subnetID, subnetErr := ipsubnetidbyname(ctx, siteID, spec.Subnet, true, s)
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	// Retrieving device ID
+	if len(spec.Device) > 0 {
+		var deviceErr error = nil
+
+		deviceID, deviceErr = hostdevidbyname(ctx, spec.Device, s)
+
+		if deviceErr != nil {
+			// Reporting a failure
+			return nil, deviceErr
+		}
+	}
+
+	// Retrieving pool ID
+	if len(spec.Pool) > 0 {
+		var poolErr error = nil
+
+		poolID, poolErr = ippoolidbyname(ctx, subnetID, spec.Pool, s)
+
+		if poolErr != nil {
+			// Reporting a failure
+			return nil, poolErr
+		}
+
+		if poolID == "" {
+			return nil, fmt.Errorf("SOLIDServer - Unable to find IP pool %s in subnet: %s", spec.Pool, spec.Subnet)
+		}
+	}
+
+	// Determining if an IP address was submitted in or if we should get one from the IPAM
+	if len(spec.RequestIP) > 0 {
+		ipAddresses = []string{spec.RequestIP}
+	} else {
+		var ipErr error = nil
+
+		ipAddresses, ipErr = ipaddressfindfree(ctx, subnetID, poolID, s)
+
+		if ipErr != nil {
+			// Reporting a failure
+			return nil, ipErr
+		}
+	}
+
+	failure := fmt.Errorf("SOLIDServer - Unable to create IP address: %s", spec.Name)
+
+	for i := 0; i < len(ipAddresses); i++ {
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("site_id", siteID)
+		parameters.Add("add_flag", "new_only")
+		parameters.Add("name", spec.Name)
+		parameters.Add("hostaddr", ipAddresses[i])
+		parameters.Add("hostdev_id", deviceID)
+		parameters.Add("ip_class_name", spec.Class)
+
+		if spec.MAC != "" {
+			parameters.Add("mac_addr", spec.MAC)
+		}
+
+		// Building class_parameters
+		parameters.Add("ip_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+		// Sending the creation request, only retried once the address is known not to be registered
+		resp, body, buf, err := s.call(ctx, "post", "rest/ip_add", &parameters, func() (bool, error) {
+			ipID, ipErr := ipaddressidbyip(ctx, siteID, ipAddresses[i], s)
+			return ipID != "", ipErr
+		})
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		// Checking the answer
+		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				log.Printf("[DEBUG] SOLIDServer - Created IP address (oid): %s\n", oid)
+
+				return &Address{
+					ID:              oid,
+					Space:           spec.Space,
+					Subnet:          spec.Subnet,
+					Address:         ipAddresses[i],
+					Name:            spec.Name,
+					MAC:             spec.MAC,
+					Class:           spec.Class,
+					ClassParameters: spec.ClassParameters,
+				}, nil
+			}
+		} else {
+			failure = apierrorf("rest/ip_add", resp, body, "SOLIDServer - Unable to create IP address: %s", spec.Name)
+			log.Printf("[DEBUG] SOLIDServer - Failed IP address registration (%s), trying another one.\n", ipAddresses[i])
+		}
+	}
+
+	// Reporting a failure
+	return nil, failure
+}
+
+// Number of free addresses suggested by each call looking for contiguous free addresses
+const freeAddressBatchSize = 64
+
+// Return the first contiguous free addresses of a subnet, only within a pool if any
+func (s *SOLIDserver) findfreeaddressrange(ctx context.Context, subnetID string, poolID string, size int) ([]string, error) {
+	addresses := []string{}
+	begin := ""
+
+	for {
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("subnet_id", subnetID)
+		parameters.Add("max_find", strconv.Itoa(freeAddressBatchSize))
+
+		if poolID != "" {
+			parameters.Add("pool_id", poolID)
+		}
+
+		if begin != "" {
+			parameters.Add("begin_addr", begin)
+		}
+
+		// Sending the read request
+		resp, _, buf, err := s.call(ctx, "get", "rpc/ip_find_free_address", &parameters, nil)
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		// Checking the answer
+		if resp.StatusCode != 200 || len(buf) == 0 {
+			break
+		}
+
+		for _, row := range buf {
+			address := rowstring(row, "hostaddr")
+
+			// Starting over when the suggested address does not follow the previous one
+			if len(addresses) > 0 && iptolong(address) != iptolong(addresses[len(addresses)-1])+1 {
+				addresses = []string{}
+			}
+
+			addresses = append(addresses, address)
+
+			if len(addresses) == size {
+				log.Printf("[DEBUG] SOLIDServer - Suggested IP addresses: %s to %s\n", addresses[0], address)
+				return addresses, nil
+			}
+		}
+
+		last := iptolong(rowstring(buf[len(buf)-1], "hostaddr"))
+
+		if len(buf) < freeAddressBatchSize || last == 0xFFFFFFFF {
+			break
+		}
+
+		begin = longtoip(last + 1)
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Unable to find %d contiguous free IP addresses in subnet (oid): %s\n", size, subnetID)
+
+	return nil, fmt.Errorf("SOLIDServer - Unable to find %d contiguous free IP addresses in subnet (oid): %s", size, subnetID)
+}
+
+// CreateAddressBlock registers contiguous IP addresses in a terminal subnet
+// Either every address is created or, on failure, the ones created so far are deleted
+// The addresses which could not be deleted are listed in the error
+func (s *SOLIDserver) CreateAddressBlock(ctx context.Context, spec AddressBlockSpec) ([]*Address, error) {
+	var poolID string = ""
+
+	if spec.Size < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Unable to create IP address block %s, its size must be at least 1", spec.NameTemplate)
+	}
+
+	names, namesErr := AddressBlockNames(spec.NameTemplate, spec.Size)
+	if namesErr != nil {
+		// Reporting a failure
+		return nil, namesErr
+	}
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	subnetID, subnetErr := ipsubnetidbyname(ctx, siteID, spec.Subnet, true, s)
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	// Retrieving pool ID
+	if len(spec.Pool) > 0 {
+		var poolErr error = nil
+
+		poolID, poolErr = ippoolidbyname(ctx, subnetID, spec.Pool, s)
+
+		if poolErr != nil {
+			// Reporting a failure
+			return nil, poolErr
+		}
+
+		if poolID == "" {
+			return nil, fmt.Errorf("SOLIDServer - Unable to find IP pool %s in subnet: %s", spec.Pool, spec.Subnet)
+		}
+	}
+
+	ipAddresses, ipErr := s.findfreeaddressrange(ctx, subnetID, poolID, spec.Size)
+	if ipErr != nil {
+		// Reporting a failure
+		return nil, ipErr
+	}
+
+	addresses := []*Address{}
+
+	for i, ipAddress := range ipAddresses {
+		address, err := s.CreateAddress(ctx, AddressSpec{
+			Space:           spec.Space,
+			Subnet:          spec.Subnet,
+			RequestIP:       ipAddress,
+			Name:            names[i],
+			Class:           spec.Class,
+			ClassParameters: spec.ClassParameters,
+		})
+
+		if err != nil {
+			// Deleting the addresses created so far
+			if unreleased := s.rollbackaddresses(addresses); len(unreleased) > 0 {
+				// Reporting a failure
+				return nil, WrapError(err, "SOLIDServer - Unable to create IP address block %s, unable to release IP addresses %s", spec.NameTemplate, strings.Join(unreleased, ", "))
+			}
+
+			// Reporting a failure
+			return nil, WrapError(err, "SOLIDServer - Unable to create IP address block %s", spec.NameTemplate)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created IP address block %s: %d address(es) from %s\n", spec.NameTemplate, spec.Size, ipAddresses[0])
+
+	return addresses, nil
+}
+
+// Delete the addresses of an IP address block which could not be fully created
+// Return the addresses which could not be deleted
+func (s *SOLIDserver) rollbackaddresses(addresses []*Address) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), addressBlockRollbackTimeout)
+	defer cancel()
+
+	unreleased := []string{}
+
+	for _, address := range addresses {
+		if err := s.DeleteAddress(ctx, address.ID); err != nil && !IsNotFound(err) {
+			log.Printf("[DEBUG] SOLIDServer - Unable to delete IP address : %s (%s)\n", address.Address, err)
+			unreleased = append(unreleased, address.Address)
+		}
+	}
+
+	return unreleased
+}
+
+// AddressBlockNames returns the names of the addresses of a block, which must all differ
+func AddressBlockNames(template string, size int) ([]string, error) {
+	names := make([]string, 0, size)
+	known := map[string]bool{}
+
+	for i := 1; i <= size; i++ {
+		name := fmt.Sprintf(template, i)
+
+		if known[name] || strings.Contains(name, "%!") {
+			return nil, fmt.Errorf("SOLIDServer - Invalid IP address block name template %s, it must format the index of each address once (ex: node-%%02d)", template)
+		}
+
+		known[name] = true
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// GetAddress reads an IP address from its oid
+func (s *SOLIDserver) GetAddress(ctx context.Context, id string) (*Address, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_address_info", &parameters, "SOLIDServer - Unable to find IP address (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	address := &Address{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Subnet:          rowstring(row, "subnet_name"),
+		Address:         hexiptoip(rowstring(row, "ip_addr")),
+		Name:            rowstring(row, "name"),
+		MAC:             rowstring(row, "mac_addr"),
+		Class:           rowstring(row, "ip_class_name"),
+		ClassParameters: rowclassparams(row, "ip_class_parameters"),
+	}
+
+	// Ignoring the MAC addresses generated by the SOLIDserver
+	if macIgnore, _ := regexp.MatchString("^EIP:", address.MAC); macIgnore {
+		address.MAC = ""
+	}
+
+	return address, nil
+}
+
+// UpdateAddress updates the name, device, MAC address, class and class parameters of an IP address
+func (s *SOLIDserver) UpdateAddress(ctx context.Context, id string, spec AddressSpec) (*Address, error) {
+	var deviceID string = ""
+
+	// Retrieving device ID
+	if len(spec.Device) > 0 {
+		var err error = nil
+
+		deviceID, err = hostdevidbyname(ctx, spec.Device, s)
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("name", spec.Name)
+	parameters.Add("hostdev_id", deviceID)
+	parameters.Add("ip_class_name", spec.Class)
+
+	if spec.MAC != "" {
+		parameters.Add("mac_addr", spec.MAC)
+	}
+
+	// Building class_parameters
+	parameters.Add("ip_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip_add", &parameters, nil, "SOLIDServer - Unable to update IP address: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP address (oid): %s\n", oid)
+
+	return &Address{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		MAC:             spec.MAC,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// DeleteAddress deletes an IP address
+func (s *SOLIDserver) DeleteAddress(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_delete", &parameters, "SOLIDServer - Unable to delete IP address (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP address (oid): %s\n", id)
+
+	return nil
+}
+
+// DeleteAddressByIP deletes an IP address from its space name and address
+func (s *SOLIDserver) DeleteAddressByIP(ctx context.Context, space string, address string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_name", space)
+	parameters.Add("hostaddr", address)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_delete", &parameters, "SOLIDServer - Unable to delete IP address: %s", address); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP address: %s\n", address)
+
+	return nil
+}
+
+// CreatePool creates an IP pool within a terminal subnet
+func (s *SOLIDserver) CreatePool(ctx context.Context, spec PoolSpec) (*Pool, error) {
+	size := spec.Size
+
+	if spec.End != "" {
+		size = int(iptolong(spec.End)) - int(iptolong(spec.Start)) + 1
+	}
+
+	if size < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Unable to create IP pool %s, its end or size must be given after its start", spec.Name)
+	}
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	subnetID, subnetErr := ipsubnetidbyname(ctx, siteID, spec.Subnet, true, s)
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("subnet_id", subnetID)
+	parameters.Add("pool_name", spec.Name)
+	parameters.Add("start_addr", spec.Start)
+	parameters.Add("pool_size", strconv.Itoa(size))
+	parameters.Add("pool_read_only", boolflag(spec.ReadOnly))
+	parameters.Add("pool_class_name", spec.Class)
+	parameters.Add("pool_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request, only retried once the pool is known not to be created
+	oid, err := s.add(ctx, "post", "rest/ip_pool_add", &parameters, func() (bool, error) {
+		poolID, lookupErr := ippoolidbyname(ctx, subnetID, spec.Name, s)
+		return poolID != "", lookupErr
+	}, "SOLIDServer - Unable to create IP pool: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created IP pool (oid): %s\n", oid)
+
+	return &Pool{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		Start:           spec.Start,
+		End:             longtoip(iptolong(spec.Start) + uint32(size) - 1),
+		Size:            size,
+		ReadOnly:        spec.ReadOnly,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// GetPool reads an IP pool from its oid
+func (s *SOLIDserver) GetPool(ctx context.Context, id string) (*Pool, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_pool_info", &parameters, "SOLIDServer - Unable to find IP pool (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Pool{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Subnet:          rowstring(row, "subnet_name"),
+		Name:            rowstring(row, "pool_name"),
+		Start:           hexiptoip(rowstring(row, "start_ip_addr")),
+		End:             hexiptoip(rowstring(row, "end_ip_addr")),
+		Size:            rowint(row, "pool_size"),
+		ReadOnly:        rowbool(row, "pool_read_only"),
+		Class:           rowstring(row, "pool_class_name"),
+		ClassParameters: rowclassparams(row, "pool_class_parameters"),
+	}, nil
+}
+
+// UpdatePool updates the name, read-only flag, class and class parameters of an IP pool
+func (s *SOLIDserver) UpdatePool(ctx context.Context, id string, spec PoolSpec) (*Pool, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("pool_name", spec.Name)
+	parameters.Add("pool_read_only", boolflag(spec.ReadOnly))
+	parameters.Add("pool_class_name", spec.Class)
+	parameters.Add("pool_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip_pool_add", &parameters, nil, "SOLIDServer - Unable to update IP pool: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP pool (oid): %s\n", oid)
+
+	return &Pool{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		Start:           spec.Start,
+		End:             spec.End,
+		Size:            spec.Size,
+		ReadOnly:        spec.ReadOnly,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// DeletePool deletes an IP pool, its addresses are kept
+func (s *SOLIDserver) DeletePool(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_pool_delete", &parameters, "SOLIDServer - Unable to delete IP pool (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP pool (oid): %s\n", id)
+
+	return nil
+}
+
+// CreateAlias creates an alias of an IP address
+func (s *SOLIDserver) CreateAlias(ctx context.Context, spec AliasSpec) (*Alias, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	addressID, addressErr := ipaddressidbyip(ctx, siteID, spec.Address, s)
+	if addressErr != nil {
+		// Reporting a failure
+		return nil, addressErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", addressID)
+	parameters.Add("ip_name", spec.Name)
+	parameters.Add("ip_name_type", spec.Type)
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/ip_alias_add", &parameters, nil, "SOLIDServer - Unable to create IP alias: %s - %s (associated to IP address with ID: %s)", spec.Name, spec.Type, addressID)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created IP alias (oid): %s\n", oid)
+
+	return &Alias{
+		ID:      oid,
+		Space:   spec.Space,
+		Address: spec.Address,
+		Name:    spec.Name,
+		Type:    spec.Type,
+	}, nil
+}
+
+// GetAlias reads an alias from its oid and the IP address it belongs to
+func (s *SOLIDserver) GetAlias(ctx context.Context, space string, address string, id string) (*Alias, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	addressID, addressErr := ipaddressidbyip(ctx, siteID, address, s)
+	if addressErr != nil {
+		// Reporting a failure
+		return nil, addressErr
+	}
+
+	where, whereErr := WhereEq("ip_name_id", id).Clause()
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_id", addressID)
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip_alias_list", &parameters, "SOLIDServer - Unable to find IP alias (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Alias{
+		ID:      id,
+		Space:   space,
+		Address: address,
+		Name:    rowstring(row, "alias_name"),
+		Type:    rowstring(row, "ip_name_type"),
+	}, nil
+}
+
+// DeleteAlias deletes an alias of an IP address
+func (s *SOLIDserver) DeleteAlias(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip_name_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip_alias_delete", &parameters, "SOLIDServer - Unable to delete IP alias (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP alias (oid): %s\n", id)
+
+	return nil
+}
+
+// SetAddressMAC maps an IP address to a MAC address, an empty MAC address removes the mapping
+// The other properties of the address are kept, its oid is returned
+func (s *SOLIDserver) SetAddressMAC(ctx context.Context, space string, address string, mac string) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_name", space)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("hostaddr", address)
+	parameters.Add("mac_addr", strings.ToLower(mac))
+	parameters.Add("keep_class_parameters", "1")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip_add", &parameters, nil, "SOLIDServer - Unable to map IP address %s to MAC address: %s", address, mac)
+
+	if err != nil {
+		// Reporting a failure
+		return "", err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Mapped IP address (oid) %s to MAC address: %s\n", oid, mac)
+
+	return oid, nil
+}
@@ -0,0 +1,881 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IP v6 objects are described by the same types as the IP v4 ones, addresses being in their usual
+// IP v6 notation. Blocks and BlockClassParameters of a SubnetSpec and Pool of an AddressSpec are
+// not supported by IP v6
+
+// Return the gateway of an IP v6 subnet at an offset from its first address,
+// or counted back from its end when negative
+func subnet6gateway(hexaddress string, prefixLength int, offset int) string {
+	bigStartAddr, _ := new(big.Int).SetString(hexaddress, 16)
+
+	if offset > 0 {
+		return hexip6toip6(BigIntToHexStr(new(big.Int).Add(bigStartAddr, big.NewInt(int64(offset)))))
+	}
+
+	bigEndAddr := new(big.Int).Add(bigStartAddr, prefix6lengthtosize(int64(prefixLength)))
+
+	return hexip6toip6(BigIntToHexStr(bigEndAddr.Sub(bigEndAddr, big.NewInt(int64(abs(offset))))))
+}
+
+// CreateSubnet6 creates an IP v6 subnet within spec.Block, or an IP v6 block if none is given
+// The first free subnet of the requested size is used
+func (s *SOLIDserver) CreateSubnet6(ctx context.Context, spec SubnetSpec) (*Subnet, error) {
+	var blockID string = ""
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	// If a block is specified, look for free IP subnet within this block
+	if len(spec.Block) > 0 {
+		var blockErr error = nil
+
+		blockID, blockErr = ip6subnetidbyname(ctx, siteID, spec.Block, false, s)
+
+		if blockErr != nil {
+			// Reporting a failure
+			return nil, blockErr
+		}
+	} else {
+		// However, we can't create a block as a terminal subnet
+		if spec.Terminal {
+			return nil, fmt.Errorf("SOLIDServer - Can't create a terminal IP v6 block subnet: %s", spec.Name)
+		}
+	}
+
+	subnetAddresses, subnetErr := ip6subnetfindbysize(ctx, siteID, blockID, spec.RequestIP, spec.PrefixLength, s)
+
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	failure := fmt.Errorf("SOLIDServer - Unable to create IP v6 subnet: %s", spec.Name)
+
+	for i := 0; i < len(subnetAddresses); i++ {
+		address := hexip6toip6(subnetAddresses[i])
+		gateway := ""
+
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("site_id", siteID)
+		parameters.Add("subnet6_name", spec.Name)
+		parameters.Add("subnet6_addr", address)
+		parameters.Add("subnet6_prefix", strconv.Itoa(spec.PrefixLength))
+		parameters.Add("subnet6_class_name", spec.Class)
+
+		// New only
+		parameters.Add("add_flag", "new_only")
+
+		// If no block specified, create an IP block
+		if len(spec.Block) == 0 {
+			parameters.Add("subnet_level", "0")
+		}
+
+		// Specify if subnet is terminal
+		parameters.Add("is_terminal", boolflag(spec.Terminal))
+
+		// Building class_parameters
+		classParameters := encodeclassparams(spec.ClassParameters)
+
+		// Generate class parameter for the gateway if required
+		if spec.GatewayOffset != 0 {
+			gateway = subnet6gateway(subnetAddresses[i], spec.PrefixLength, spec.GatewayOffset)
+			classParameters.Set("gateway", gateway)
+			log.Printf("[DEBUG] SOLIDServer - Subnet computed gateway: %s\n", gateway)
+		}
+
+		parameters.Add("subnet6_class_parameters", classParameters.Encode())
+
+		// Sending the creation request, only retried once the subnet is known not to be created
+		resp, body, buf, err := s.call(ctx, "post", "rest/ip6_subnet6_add", &parameters, func() (bool, error) {
+			subnetID, lookupErr := ip6subnetidbyname(ctx, siteID, spec.Name, spec.Terminal, s)
+			return subnetID != "", lookupErr
+		})
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		// Checking the answer
+		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				log.Printf("[DEBUG] SOLIDServer - Created IP v6 subnet (oid): %s\n", oid)
+				s.cache.invalidate(cacheIP6Subnet, siteID, strings.ToLower(spec.Name))
+
+				return &Subnet{
+					ID:              oid,
+					Space:           spec.Space,
+					Block:           spec.Block,
+					Name:            spec.Name,
+					Address:         address,
+					PrefixLength:    spec.PrefixLength,
+					Terminal:        spec.Terminal,
+					Gateway:         gateway,
+					Class:           spec.Class,
+					ClassParameters: spec.ClassParameters,
+				}, nil
+			}
+		} else {
+			failure = apierrorf("rest/ip6_subnet6_add", resp, body, "SOLIDServer - Unable to create IP v6 subnet: %s", spec.Name)
+			log.Printf("[DEBUG] SOLIDServer - Failed IP v6 subnet registration, trying another one.\n")
+		}
+	}
+
+	// Reporting a failure
+	return nil, failure
+}
+
+// GetSubnet6 reads an IP v6 block or subnet from its oid
+func (s *SOLIDserver) GetSubnet6(ctx context.Context, id string) (*Subnet, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet6_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip6_block6_subnet6_info", &parameters, "SOLIDServer - Unable to find IP v6 subnet (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	subnet := &Subnet{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Block:           rowstring(row, "parent_subnet6_name"),
+		Name:            rowstring(row, "subnet6_name"),
+		PrefixLength:    rowint(row, "subnet6_prefix"),
+		Terminal:        rowstring(row, "is_terminal") == "1",
+		Class:           rowstring(row, "subnet6_class_name"),
+		ClassParameters: rowclassparams(row, "subnet6_class_parameters"),
+	}
+
+	if start := rowstring(row, "start_ip6_addr"); start != "" {
+		subnet.Address = hexip6toip6(start)
+	}
+
+	subnet.Gateway = subnet.ClassParameters["gateway"]
+
+	return subnet, nil
+}
+
+// UpdateSubnet6 updates the name, class and class parameters of an IP v6 block or subnet
+func (s *SOLIDserver) UpdateSubnet6(ctx context.Context, id string, spec SubnetSpec) (*Subnet, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet6_id", id)
+	parameters.Add("subnet6_name", spec.Name)
+	parameters.Add("subnet6_class_name", spec.Class)
+
+	// Edit only
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("is_terminal", boolflag(spec.Terminal))
+
+	// Building class_parameters
+	classParameters := encodeclassparams(spec.ClassParameters)
+
+	// Keeping the gateway class parameter if required
+	if spec.Gateway != "" {
+		classParameters.Set("gateway", spec.Gateway)
+		log.Printf("[DEBUG] SOLIDServer - Subnet updated gateway: %s\n", spec.Gateway)
+	}
+
+	parameters.Add("subnet6_class_parameters", classParameters.Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip6_subnet6_add", &parameters, nil, "SOLIDServer - Unable to update IP v6 subnet: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP v6 subnet (oid): %s\n", oid)
+	s.cache.forget(cacheIP6Subnet, oid)
+
+	return &Subnet{
+		ID:              oid,
+		Space:           spec.Space,
+		Block:           spec.Block,
+		Name:            spec.Name,
+		Terminal:        spec.Terminal,
+		Gateway:         spec.Gateway,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// Return the addresses of a range of an IP v6 subnet, which must fit within the addresses of the subnet
+func subnet6range(address string, prefixLength int, r AddressRange) ([]string, error) {
+	if r.Offset == 0 || r.Size < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Invalid reserved range %s, its offset must not be 0 and its size at least 1", r.Name)
+	}
+
+	bigStartAddr, startOk := new(big.Int).SetString(ip6tohexip6(address), 16)
+
+	if !startOk || prefixLength < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Unable to reserve range %s of IP v6 subnet: %s/%d", r.Name, address, prefixLength)
+	}
+
+	bigEndAddr := new(big.Int).Add(bigStartAddr, prefix6lengthtosize(int64(prefixLength)))
+	bigFirstAddr := new(big.Int).Add(bigStartAddr, big.NewInt(int64(r.Offset)))
+
+	if r.Offset < 0 {
+		bigFirstAddr = new(big.Int).Add(bigEndAddr, big.NewInt(int64(r.Offset)))
+	}
+
+	if bigFirstAddr.Cmp(bigStartAddr) <= 0 || new(big.Int).Add(bigFirstAddr, big.NewInt(int64(r.Size))).Cmp(bigEndAddr) > 0 {
+		return nil, fmt.Errorf("SOLIDServer - Reserved range %s does not fit in IP v6 subnet %s/%d", r.Name, address, prefixLength)
+	}
+
+	addresses := make([]string, 0, r.Size)
+
+	for i := 0; i < r.Size; i++ {
+		bigAddr := new(big.Int).Add(bigFirstAddr, big.NewInt(int64(i)))
+		addresses = append(addresses, hexip6toip6(fmt.Sprintf("%032x", bigAddr)))
+	}
+
+	return addresses, nil
+}
+
+// Reserve an IP v6 address under a name
+func (s *SOLIDserver) reserveaddress6(ctx context.Context, siteID string, address string, name string) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("hostaddr", address)
+	parameters.Add("ip6_name", name)
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request, only retried once the address is known not to be reserved
+	return s.add(ctx, "post", "rest/ip6_address6_add", &parameters, func() (bool, error) {
+		ipID, lookupErr := ip6addressidbyip6(ctx, siteID, address, s)
+		return ipID != "", lookupErr
+	}, "SOLIDServer - Unable to reserve IP v6 address %s as: %s", address, name)
+}
+
+// ReserveSubnet6Ranges reserves every address of the ranges of an IP v6 subnet under the name of
+// its range, so they are never found free. On failure, the addresses reserved so far are released
+func (s *SOLIDserver) ReserveSubnet6Ranges(ctx context.Context, subnet *Subnet, ranges []AddressRange) error {
+	addresses := make([][]string, len(ranges))
+
+	for i, r := range ranges {
+		var rangeErr error = nil
+
+		addresses[i], rangeErr = subnet6range(subnet.Address, subnet.PrefixLength, r)
+
+		if rangeErr != nil {
+			// Reporting a failure
+			return rangeErr
+		}
+	}
+
+	siteID, siteErr := ipsiteidbyname(ctx, subnet.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return siteErr
+	}
+
+	reserved := []string{}
+
+	for i, r := range ranges {
+		for _, address := range addresses[i] {
+			if _, err := s.reserveaddress6(ctx, siteID, address, r.Name); err != nil {
+				// Releasing the addresses reserved so far
+				for _, release := range reserved {
+					if releaseErr := s.DeleteAddress6ByIP(ctx, subnet.Space, release); releaseErr != nil {
+						log.Printf("[DEBUG] SOLIDServer - Unable to release reserved IP v6 address : %s (%s)\n", release, releaseErr)
+					}
+				}
+
+				// Reporting a failure
+				return WrapError(err, "SOLIDServer - Unable to reserve range %s of IP v6 subnet %s", r.Name, subnet.Name)
+			}
+
+			reserved = append(reserved, address)
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Reserved range %s of IP v6 subnet %s: %d address(es) from %s\n", r.Name, subnet.Name, r.Size, addresses[i][0])
+	}
+
+	return nil
+}
+
+// ReleaseSubnet6Ranges releases the addresses of ranges reserved in an IP v6 subnet,
+// the ones which are not reserved anymore are skipped
+func (s *SOLIDserver) ReleaseSubnet6Ranges(ctx context.Context, subnet *Subnet, ranges []AddressRange) error {
+	for _, r := range ranges {
+		addresses, rangeErr := subnet6range(subnet.Address, subnet.PrefixLength, r)
+
+		if rangeErr != nil {
+			log.Printf("[DEBUG] SOLIDServer - Unable to release range %s of IP v6 subnet %s (%s)\n", r.Name, subnet.Name, rangeErr)
+			continue
+		}
+
+		for _, address := range addresses {
+			if err := s.DeleteAddress6ByIP(ctx, subnet.Space, address); err != nil {
+				if !IsAPIError(err) {
+					// Reporting a failure
+					return err
+				}
+
+				log.Printf("[DEBUG] SOLIDServer - Unable to release reserved IP v6 address : %s (%s)\n", address, err)
+			}
+		}
+
+		log.Printf("[DEBUG] SOLIDServer - Released range %s of IP v6 subnet: %s\n", r.Name, subnet.Name)
+	}
+
+	return nil
+}
+
+// DeleteSubnet6 deletes an IP v6 block or subnet
+func (s *SOLIDserver) DeleteSubnet6(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("subnet6_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip6_subnet6_delete", &parameters, "SOLIDServer - Unable to delete IP v6 subnet (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 subnet (oid): %s\n", id)
+	s.cache.forget(cacheIP6Subnet, id)
+
+	return nil
+}
+
+// CreateAddress6 registers an IP v6 address in a terminal subnet
+func (s *SOLIDserver) CreateAddress6(ctx context.Context, spec AddressSpec) (*Address, error) {
+	var ipAddresses []string = nil
+	var deviceID string = ""
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	subnetID, subnetErr := ip6subnetidbyname(ctx, siteID, spec.Subnet, true, s)
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	// Retrieving device ID
+	if len(spec.Device) > 0 {
+		var deviceErr error = nil
+
+		deviceID, deviceErr = hostdevidbyname(ctx, spec.Device, s)
+
+		if deviceErr != nil {
+			// Reporting a failure
+			return nil, deviceErr
+		}
+	}
+
+	// Determining if an IP address was submitted in or if we should get one from the IPAM
+	if len(spec.RequestIP) > 0 {
+		ipAddresses = []string{spec.RequestIP}
+	} else {
+		var ipErr error = nil
+
+		ipAddresses, ipErr = ip6addressfindfree(ctx, subnetID, s)
+
+		if ipErr != nil {
+			// Reporting a failure
+			return nil, ipErr
+		}
+	}
+
+	failure := fmt.Errorf("SOLIDServer - Unable to create IP v6 address: %s", spec.Name)
+
+	for i := 0; i < len(ipAddresses); i++ {
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("site_id", siteID)
+		parameters.Add("add_flag", "new_only")
+		parameters.Add("ip6_name", spec.Name)
+		parameters.Add("hostaddr", ipAddresses[i])
+		parameters.Add("hostdev_id", deviceID)
+		parameters.Add("ip6_class_name", spec.Class)
+
+		if spec.MAC != "" {
+			parameters.Add("mac_addr", spec.MAC)
+		}
+
+		// Building class_parameters
+		parameters.Add("ip6_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+		// Sending the creation request, only retried once the address is known not to be registered
+		resp, body, buf, err := s.call(ctx, "post", "rest/ip6_address6_add", &parameters, func() (bool, error) {
+			ipID, ipErr := ip6addressidbyip6(ctx, siteID, ipAddresses[i], s)
+			return ipID != "", ipErr
+		})
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		// Checking the answer
+		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				log.Printf("[DEBUG] SOLIDServer - Created IP v6 address (oid): %s\n", oid)
+
+				return &Address{
+					ID:              oid,
+					Space:           spec.Space,
+					Subnet:          spec.Subnet,
+					Address:         ipAddresses[i],
+					Name:            spec.Name,
+					MAC:             spec.MAC,
+					Class:           spec.Class,
+					ClassParameters: spec.ClassParameters,
+				}, nil
+			}
+		} else {
+			failure = apierrorf("rest/ip6_address6_add", resp, body, "SOLIDServer - Unable to create IP v6 address: %s", spec.Name)
+			log.Printf("[DEBUG] SOLIDServer - Failed IP v6 address registration (%s), trying another one.\n", ipAddresses[i])
+		}
+	}
+
+	// Reporting a failure
+	return nil, failure
+}
+
+// GetAddress6 reads an IP v6 address from its oid
+func (s *SOLIDserver) GetAddress6(ctx context.Context, id string) (*Address, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip6_address6_info", &parameters, "SOLIDServer - Unable to find IP v6 address (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	address := &Address{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Subnet:          rowstring(row, "subnet6_name"),
+		Address:         hexip6toip6(rowstring(row, "ip6_addr")),
+		Name:            rowstring(row, "ip6_name"),
+		MAC:             rowstring(row, "ip6_mac_addr"),
+		Class:           rowstring(row, "ip6_class_name"),
+		ClassParameters: rowclassparams(row, "ip6_class_parameters"),
+	}
+
+	// Ignoring the MAC addresses generated by the SOLIDserver
+	if macIgnore, _ := regexp.MatchString("^EIP:", address.MAC); macIgnore {
+		address.MAC = ""
+	}
+
+	return address, nil
+}
+
+// UpdateAddress6 updates the name, device, MAC address, class and class parameters of an IP v6 address
+func (s *SOLIDserver) UpdateAddress6(ctx context.Context, id string, spec AddressSpec) (*Address, error) {
+	var deviceID string = ""
+
+	// Retrieving device ID
+	if len(spec.Device) > 0 {
+		var err error = nil
+
+		deviceID, err = hostdevidbyname(ctx, spec.Device, s)
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("ip6_name", spec.Name)
+	parameters.Add("hostdev_id", deviceID)
+	parameters.Add("ip6_class_name", spec.Class)
+
+	if spec.MAC != "" {
+		parameters.Add("mac_addr", spec.MAC)
+	}
+
+	// Building class_parameters
+	parameters.Add("ip6_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip6_address6_add", &parameters, nil, "SOLIDServer - Unable to update IP v6 address: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP v6 address (oid): %s\n", oid)
+
+	return &Address{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		MAC:             spec.MAC,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// DeleteAddress6 deletes an IP v6 address
+func (s *SOLIDserver) DeleteAddress6(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip6_address6_delete", &parameters, "SOLIDServer - Unable to delete IP v6 address (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 address (oid): %s\n", id)
+
+	return nil
+}
+
+// DeleteAddress6ByIP deletes an IP v6 address from its space name and address
+func (s *SOLIDserver) DeleteAddress6ByIP(ctx context.Context, space string, address string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_name", space)
+	parameters.Add("hostaddr", address)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip6_address6_delete", &parameters, "SOLIDServer - Unable to delete IP v6 address: %s", address); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 address: %s\n", address)
+
+	return nil
+}
+
+// SetAddress6MAC maps an IP v6 address to a MAC address, an empty MAC address removes the mapping
+// The other properties of the address are kept, its oid is returned
+func (s *SOLIDserver) SetAddress6MAC(ctx context.Context, space string, address string, mac string) (string, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_name", space)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("hostaddr", address)
+	parameters.Add("ip6_mac_addr", strings.ToLower(mac))
+	parameters.Add("keep_class_parameters", "1")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip6_address6_add", &parameters, nil, "SOLIDServer - Unable to map IP v6 address %s to MAC address: %s", address, mac)
+
+	if err != nil {
+		// Reporting a failure
+		return "", err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Mapped IP v6 address (oid) %s to MAC address: %s\n", oid, mac)
+
+	return oid, nil
+}
+
+// CreateAlias6 creates an alias of an IP v6 address
+func (s *SOLIDserver) CreateAlias6(ctx context.Context, spec AliasSpec) (*Alias, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	addressID, addressErr := ip6addressidbyip6(ctx, siteID, spec.Address, s)
+	if addressErr != nil {
+		// Reporting a failure
+		return nil, addressErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_id", addressID)
+	parameters.Add("ip6_name", spec.Name)
+	parameters.Add("ip6_name_type", spec.Type)
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/ip6_alias_add", &parameters, nil, "SOLIDServer - Unable to create IP v6 alias: %s - %s (associated to IP v6 address with ID: %s)", spec.Name, spec.Type, addressID)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created IP v6 alias (oid): %s\n", oid)
+
+	return &Alias{
+		ID:      oid,
+		Space:   spec.Space,
+		Address: spec.Address,
+		Name:    spec.Name,
+		Type:    spec.Type,
+	}, nil
+}
+
+// GetAlias6 reads an alias from its oid and the IP v6 address it belongs to
+func (s *SOLIDserver) GetAlias6(ctx context.Context, space string, address string, id string) (*Alias, error) {
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	addressID, addressErr := ip6addressidbyip6(ctx, siteID, address, s)
+	if addressErr != nil {
+		// Reporting a failure
+		return nil, addressErr
+	}
+
+	where, whereErr := WhereEq("ip6_name_id", id).Clause()
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_id", addressID)
+	parameters.Add("WHERE", where)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip6_alias_list", &parameters, "SOLIDServer - Unable to find IP v6 alias (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &Alias{
+		ID:      id,
+		Space:   space,
+		Address: address,
+		Name:    rowstring(row, "alias_name"),
+		Type:    rowstring(row, "ip6_name_type"),
+	}, nil
+}
+
+// DeleteAlias6 deletes an alias of an IP v6 address
+func (s *SOLIDserver) DeleteAlias6(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("ip6_name_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip6_alias_delete", &parameters, "SOLIDServer - Unable to delete IP v6 alias (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 alias (oid): %s\n", id)
+
+	return nil
+}
+
+// CreatePool6 creates an IP v6 pool within a terminal subnet
+// The size of the pool is left to 0 when it does not fit in an int
+func (s *SOLIDserver) CreatePool6(ctx context.Context, spec PoolSpec) (*Pool, error) {
+	bigStartAddr, startOk := new(big.Int).SetString(ip6tohexip6(spec.Start), 16)
+
+	if !startOk {
+		return nil, fmt.Errorf("SOLIDServer - Unable to create IP v6 pool %s, invalid start address: %s", spec.Name, spec.Start)
+	}
+
+	bigSize := big.NewInt(int64(spec.Size))
+
+	if spec.End != "" {
+		bigEndAddr, endOk := new(big.Int).SetString(ip6tohexip6(spec.End), 16)
+
+		if !endOk {
+			return nil, fmt.Errorf("SOLIDServer - Unable to create IP v6 pool %s, invalid end address: %s", spec.Name, spec.End)
+		}
+
+		bigSize = new(big.Int).Add(new(big.Int).Sub(bigEndAddr, bigStartAddr), big.NewInt(1))
+	}
+
+	if bigSize.Sign() < 1 {
+		return nil, fmt.Errorf("SOLIDServer - Unable to create IP v6 pool %s, its end or size must be given after its start", spec.Name)
+	}
+
+	// Gather required ID(s) from provided information
+	siteID, siteErr := ipsiteidbyname(ctx, spec.Space, s)
+	if siteErr != nil {
+		// Reporting a failure
+		return nil, siteErr
+	}
+
+	subnetID, subnetErr := ip6subnetidbyname(ctx, siteID, spec.Subnet, true, s)
+	if subnetErr != nil {
+		// Reporting a failure
+		return nil, subnetErr
+	}
+
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("site_id", siteID)
+	parameters.Add("subnet6_id", subnetID)
+	parameters.Add("pool6_name", spec.Name)
+	parameters.Add("start_addr", spec.Start)
+	parameters.Add("pool6_size", BigIntToStr(bigSize))
+	parameters.Add("pool6_read_only", boolflag(spec.ReadOnly))
+	parameters.Add("pool6_class_name", spec.Class)
+	parameters.Add("pool6_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request, only retried once the pool is known not to be created
+	oid, err := s.add(ctx, "post", "rest/ip6_pool6_add", &parameters, func() (bool, error) {
+		poolID, lookupErr := ip6poolidbyname(ctx, subnetID, spec.Name, s)
+		return poolID != "", lookupErr
+	}, "SOLIDServer - Unable to create IP v6 pool: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created IP v6 pool (oid): %s\n", oid)
+
+	bigLastAddr := new(big.Int).Sub(new(big.Int).Add(bigStartAddr, bigSize), big.NewInt(1))
+	size := 0
+
+	// The size of the largest pools is only known from their end
+	if bigSize.IsInt64() {
+		size = int(bigSize.Int64())
+	}
+
+	return &Pool{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		Start:           spec.Start,
+		End:             hexip6toip6(fmt.Sprintf("%032x", bigLastAddr)),
+		Size:            size,
+		ReadOnly:        spec.ReadOnly,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// GetPool6 reads an IP v6 pool from its oid
+func (s *SOLIDserver) GetPool6(ctx context.Context, id string) (*Pool, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool6_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/ip6_pool6_info", &parameters, "SOLIDServer - Unable to find IP v6 pool (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	// The size of the largest pools is only known from their end
+	size, sizeErr := strconv.Atoi(rowstring(row, "pool6_size"))
+
+	if sizeErr != nil {
+		size = 0
+	}
+
+	return &Pool{
+		ID:              id,
+		Space:           rowstring(row, "site_name"),
+		Subnet:          rowstring(row, "subnet6_name"),
+		Name:            rowstring(row, "pool6_name"),
+		Start:           hexip6toip6(rowstring(row, "start_ip6_addr")),
+		End:             hexip6toip6(rowstring(row, "end_ip6_addr")),
+		Size:            size,
+		ReadOnly:        rowbool(row, "pool6_read_only"),
+		Class:           rowstring(row, "pool6_class_name"),
+		ClassParameters: rowclassparams(row, "pool6_class_parameters"),
+	}, nil
+}
+
+// UpdatePool6 updates the name, read-only flag, class and class parameters of an IP v6 pool
+func (s *SOLIDserver) UpdatePool6(ctx context.Context, id string, spec PoolSpec) (*Pool, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool6_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("pool6_name", spec.Name)
+	parameters.Add("pool6_read_only", boolflag(spec.ReadOnly))
+	parameters.Add("pool6_class_name", spec.Class)
+	parameters.Add("pool6_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/ip6_pool6_add", &parameters, nil, "SOLIDServer - Unable to update IP v6 pool: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated IP v6 pool (oid): %s\n", oid)
+
+	return &Pool{
+		ID:              oid,
+		Space:           spec.Space,
+		Subnet:          spec.Subnet,
+		Name:            spec.Name,
+		Start:           spec.Start,
+		End:             spec.End,
+		Size:            spec.Size,
+		ReadOnly:        spec.ReadOnly,
+		Class:           spec.Class,
+		ClassParameters: spec.ClassParameters,
+	}, nil
+}
+
+// DeletePool6 deletes an IP v6 pool, its addresses are kept
+func (s *SOLIDserver) DeletePool6(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("pool6_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/ip6_pool6_delete", &parameters, "SOLIDServer - Unable to delete IP v6 pool (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted IP v6 pool (oid): %s\n", id)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package sdsclient
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Default maximum number of API calls sent at the same time
+	DefaultMaxConcurrentRequests = 8
+	// Default maximum number of API calls per second (0 means unlimited)
+	DefaultRequestsPerSecond = 0
+)
+
+// Limiter bounds the number of concurrent API calls and their rate
+// It is shared by every resource using the same provider
+type Limiter struct {
+	slots  chan struct{}
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Build a Limiter allowing maxConcurrent calls at the same time and
+// perSecond calls per second, a null or negative value disables the limit
+func NewLimiter(maxConcurrent int, perSecond float64) *Limiter {
+	l := &Limiter{}
+
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	if perSecond > 0 {
+		l.rate = perSecond
+		l.burst = math.Max(1, math.Ceil(perSecond))
+		l.tokens = l.burst
+		l.last = time.Now()
+	}
+
+	return l
+}
+
+// Block until a call can be sent or the context is done
+// Every successful Acquire must be followed by a Release once the call is complete
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if l.rate > 0 {
+		if err := sleep(ctx, l.reserve()); err != nil {
+			l.Release()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Release the slot taken by Acquire
+func (l *Limiter) Release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// Take a token from the bucket
+// Return how long to wait until the token is actually available
+func (l *Limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Wait for the given delay unless the context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,77 @@
+package sdsclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l := NewLimiter(2, 0)
+
+	var running int32
+	var peak int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			l.Acquire(context.Background())
+			defer l.Release()
+
+			current := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&peak)
+				if current <= max || atomic.CompareAndSwapInt32(&peak, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent calls, got %d", peak)
+	}
+}
+
+func TestLimiter_RequestsPerSecond(t *testing.T) {
+	l := NewLimiter(0, 50)
+	start := time.Now()
+
+	// The first 50 calls use the initial burst, the next 25 wait for new tokens
+	for i := 0; i < 75; i++ {
+		l.Acquire(context.Background())
+		l.Release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected the rate to be limited, 75 calls took %s", elapsed)
+	}
+}
+
+func TestLimiter_Canceled(t *testing.T) {
+	l := NewLimiter(1, 0)
+	l.Acquire(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The only slot is taken, the call gives up once the context is done
+	if err := l.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the wait to be interrupted, got: %v", err)
+	}
+
+	l.Release()
+
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("expected the released slot to be available, got: %v", err)
+	}
+}
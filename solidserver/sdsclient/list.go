@@ -0,0 +1,182 @@
+package sdsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// Default number of objects retrieved by each call of a list service
+	DefaultListPageSize = 500
+	// Default maximum number of objects retrieved from a list service (0 means unlimited)
+	DefaultListMaxResults = 0
+)
+
+// ListIterator walks through the objects returned by a SOLIDserver list service
+// Pages are retrieved with limit/offset calls, as the iteration goes on
+//
+//	it := s.List(ctx, "rest/ip_site_list", &parameters)
+//	for it.Next() {
+//		site := it.Row()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ListIterator struct {
+	s          *SOLIDserver
+	ctx        context.Context
+	service    string
+	parameters url.Values
+	pageSize   int
+	maxResults int
+	page       [](map[string]interface{})
+	index      int
+	offset     int
+	count      int
+	done       bool
+	err        error
+}
+
+// List returns an iterator over every object matching the parameters of a list service
+// A limit parameter caps the number of objects, like the list_max_results provider argument
+func (s *SOLIDserver) List(ctx context.Context, service string, parameters *url.Values) *ListIterator {
+	it := &ListIterator{
+		s:          s,
+		ctx:        ctx,
+		service:    service,
+		parameters: url.Values{},
+		pageSize:   s.ListPageSize,
+		maxResults: s.ListMaxResults,
+		index:      -1,
+	}
+
+	for k, v := range *parameters {
+		it.parameters[k] = v
+	}
+
+	if limit, err := strconv.Atoi(it.parameters.Get("limit")); err == nil && limit > 0 {
+		if it.maxResults <= 0 || limit < it.maxResults {
+			it.maxResults = limit
+		}
+	}
+
+	if it.pageSize <= 0 {
+		it.pageSize = DefaultListPageSize
+	}
+
+	if it.maxResults > 0 && it.maxResults < it.pageSize {
+		it.pageSize = it.maxResults
+	}
+
+	if offset, err := strconv.Atoi(it.parameters.Get("offset")); err == nil && offset > 0 {
+		it.offset = offset
+	}
+
+	return it
+}
+
+// Next moves to the next object, retrieving the next page if needed
+// Return false once every object was returned or an error occurred
+func (it *ListIterator) Next() bool {
+	if it.err != nil || (it.maxResults > 0 && it.count >= it.maxResults) {
+		return false
+	}
+
+	if it.index+1 >= len(it.page) {
+		if it.done || !it.fetch() {
+			return false
+		}
+	}
+
+	it.index++
+	it.count++
+
+	return true
+}
+
+// Row returns the current object
+func (it *ListIterator) Row() map[string]interface{} {
+	if it.index < 0 || it.index >= len(it.page) {
+		return nil
+	}
+
+	return it.page[it.index]
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// Retrieve the next page of objects
+// Return false if there is no more object
+func (it *ListIterator) fetch() bool {
+	// Building parameters
+	it.parameters.Set("limit", strconv.Itoa(it.pageSize))
+	it.parameters.Set("offset", strconv.Itoa(it.offset))
+
+	// Sending the read request
+	resp, body, err := it.s.Request(it.ctx, "get", it.service, &it.parameters)
+
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	// No object matched the request
+	if resp.StatusCode == http.StatusNoContent {
+		it.done = true
+		return false
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if apiErr := NewSOLIDserverError(it.service, resp, body); apiErr != nil && apiErr.IsNotFound() {
+			it.done = true
+			return false
+		}
+
+		it.err = apierrorf(it.service, resp, body, "SOLIDServer - Unable to list objects (offset: %d)", it.offset)
+		return false
+	}
+
+	var buf [](map[string]interface{})
+
+	if jsonErr := json.Unmarshal([]byte(body), &buf); jsonErr != nil {
+		it.err = fmt.Errorf("SOLIDServer - Unexpected answer from %s (offset: %d): %s", it.service, it.offset, jsonErr)
+		return false
+	}
+
+	// Some services ignore the offset, stop instead of looping on the same page
+	if len(it.page) > 0 && len(buf) > 0 && reflect.DeepEqual(it.page[0], buf[0]) {
+		it.done = true
+		return false
+	}
+
+	// A short page is the last one
+	if len(buf) < it.pageSize {
+		it.done = true
+	}
+
+	it.page = buf
+	it.index = -1
+	it.offset += len(buf)
+
+	return len(buf) > 0
+}
+
+// ListAll returns every object matching the parameters of a list service
+func (s *SOLIDserver) ListAll(ctx context.Context, service string, parameters *url.Values) ([](map[string]interface{}), error) {
+	rows := [](map[string]interface{}){}
+	it := s.List(ctx, service, parameters)
+
+	for it.Next() {
+		rows = append(rows, it.Row())
+	}
+
+	return rows, it.Err()
+}
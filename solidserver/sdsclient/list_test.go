@@ -0,0 +1,138 @@
+package sdsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// Serve count sites, page by page
+func testSOLIDserverList(t *testing.T, count int, pageSize int, maxResults int) (*SOLIDserver, *int, func()) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		if offset >= count {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		rows := []map[string]string{}
+
+		for i := offset; i < count && i < offset+limit; i++ {
+			rows = append(rows, map[string]string{"site_id": strconv.Itoa(i)})
+		}
+
+		json.NewEncoder(w).Encode(rows)
+	}))
+
+	s := &SOLIDserver{BaseUrl: server.URL, Retry: DefaultRetryPolicy(), ListPageSize: pageSize, ListMaxResults: maxResults, limiter: NewLimiter(0, 0)}
+
+	if err := s.initClient(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return s, &calls, server.Close
+}
+
+func TestList_Pages(t *testing.T) {
+	cases := []struct {
+		count      int
+		pageSize   int
+		maxResults int
+		expected   int
+		calls      int
+	}{
+		{0, 10, 0, 0, 1},
+		{9, 10, 0, 9, 1},
+		{10, 10, 0, 10, 2},
+		{25, 10, 0, 25, 3},
+		{25, 10, 15, 15, 2},
+		{25, 10, 5, 5, 1},
+	}
+
+	for _, c := range cases {
+		s, calls, close := testSOLIDserverList(t, c.count, c.pageSize, c.maxResults)
+		rows, err := s.ListAll(context.Background(), "rest/ip_site_list", &url.Values{})
+		close()
+
+		if err != nil {
+			t.Errorf("%d/%d: unexpected error: %s", c.count, c.pageSize, err)
+			continue
+		}
+
+		if len(rows) != c.expected {
+			t.Errorf("%d/%d: expected %d rows, got %d", c.count, c.pageSize, c.expected, len(rows))
+		}
+
+		for i, row := range rows {
+			if row["site_id"] != strconv.Itoa(i) {
+				t.Errorf("%d/%d: unexpected row %d: %v", c.count, c.pageSize, i, row)
+				break
+			}
+		}
+
+		if *calls != c.calls {
+			t.Errorf("%d/%d: expected %d calls, got %d", c.count, c.pageSize, c.calls, *calls)
+		}
+	}
+}
+
+func TestList_Limit(t *testing.T) {
+	s, _, close := testSOLIDserverList(t, 25, 10, 0)
+	defer close()
+
+	parameters := url.Values{}
+	parameters.Add("limit", "4")
+
+	rows, err := s.ListAll(context.Background(), "rest/ip_site_list", &parameters)
+
+	if err != nil || len(rows) != 4 {
+		t.Errorf("expected 4 rows, got %d (%v)", len(rows), err)
+	}
+
+	if parameters.Get("offset") != "" {
+		t.Errorf("expected the parameters of the caller to be left untouched")
+	}
+}
+
+func TestList_IgnoredOffset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"site_id": "1"}, {"site_id": "2"}]`)
+	}))
+	defer server.Close()
+
+	s := &SOLIDserver{BaseUrl: server.URL, Retry: DefaultRetryPolicy(), ListPageSize: 2, limiter: NewLimiter(0, 0)}
+	s.initClient()
+
+	rows, err := s.ListAll(context.Background(), "rest/ip_site_list", &url.Values{})
+
+	if err != nil || len(rows) != 2 {
+		t.Errorf("expected 2 rows, got %d (%v)", len(rows), err)
+	}
+}
+
+func TestList_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `[{"errno": "2000", "errmsg": "Invalid WHERE clause"}]`)
+	}))
+	defer server.Close()
+
+	s := &SOLIDserver{BaseUrl: server.URL, Retry: DefaultRetryPolicy(), limiter: NewLimiter(0, 0)}
+	s.initClient()
+
+	_, err := s.ListAll(context.Background(), "rest/ip_site_list", &url.Values{})
+
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,179 @@
+package sdsclient
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// Default timeout of a single API call, in seconds
+	DefaultTimeout = 16
+	// Default number of retries of a failed API call
+	DefaultMaxRetries = 3
+	// Default delay before the first retry, in milliseconds
+	DefaultRetryBackoffBase = 128
+	// Default maximum delay between two retries, in milliseconds
+	DefaultRetryBackoffCap = 8000
+	// Default HTTP status codes on which API calls are retried
+	DefaultRetryableStatuses = "429,502,503,504"
+	// Default network errors on which API calls are retried
+	DefaultRetryableNetworkErrors = "dns,refused,reset,eof,timeout"
+)
+
+// Network error kinds which can be retried
+var retryableNetworkErrorKinds = map[string]bool{
+	"dns":     true,
+	"refused": true,
+	"reset":   true,
+	"eof":     true,
+	"timeout": true,
+}
+
+// RetryPolicy describes when and how often failed API calls are sent again
+type RetryPolicy struct {
+	Timeout                time.Duration
+	MaxRetries             int
+	BackoffBase            time.Duration
+	BackoffCap             time.Duration
+	RetryableStatuses      map[int]bool
+	RetryableNetworkErrors map[string]bool
+}
+
+// Build a RetryPolicy from the provider settings
+// Statuses and network errors are comma separated lists
+func NewRetryPolicy(timeout int, maxRetries int, backoffBase int, backoffCap int, statuses string, networkErrors string) (RetryPolicy, error) {
+	p := RetryPolicy{
+		Timeout:                time.Duration(timeout) * time.Second,
+		MaxRetries:             maxRetries,
+		BackoffBase:            time.Duration(backoffBase) * time.Millisecond,
+		BackoffCap:             time.Duration(backoffCap) * time.Millisecond,
+		RetryableStatuses:      map[int]bool{},
+		RetryableNetworkErrors: map[string]bool{},
+	}
+
+	if timeout <= 0 {
+		return p, fmt.Errorf("SOLIDServer - Invalid timeout: %d, must be a positive number of seconds", timeout)
+	}
+
+	if maxRetries < 0 {
+		return p, fmt.Errorf("SOLIDServer - Invalid max_retries: %d, must be a positive number", maxRetries)
+	}
+
+	if backoffBase < 0 || backoffCap < backoffBase {
+		return p, fmt.Errorf("SOLIDServer - Invalid retry backoff: base (%d ms) must be positive and lower than the cap (%d ms)", backoffBase, backoffCap)
+	}
+
+	for _, status := range strings.Split(statuses, ",") {
+		if status = strings.TrimSpace(status); status == "" {
+			continue
+		}
+
+		code, err := strconv.Atoi(status)
+
+		if err != nil || code < 100 || code > 599 {
+			return p, fmt.Errorf("SOLIDServer - Invalid retryable status: %s", status)
+		}
+
+		p.RetryableStatuses[code] = true
+	}
+
+	for _, kind := range strings.Split(networkErrors, ",") {
+		if kind = strings.ToLower(strings.TrimSpace(kind)); kind == "" {
+			continue
+		}
+
+		if !retryableNetworkErrorKinds[kind] {
+			return p, fmt.Errorf("SOLIDServer - Invalid retryable network error: %s (supported: dns, refused, reset, eof, timeout)", kind)
+		}
+
+		p.RetryableNetworkErrors[kind] = true
+	}
+
+	return p, nil
+}
+
+// Return the policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	p, _ := NewRetryPolicy(DefaultTimeout, DefaultMaxRetries, DefaultRetryBackoffBase, DefaultRetryBackoffCap, DefaultRetryableStatuses, DefaultRetryableNetworkErrors)
+
+	return p
+}
+
+// Compute the delay before the given retry (starting at 0)
+// The delay grows exponentially up to the cap, with some jitter
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BackoffCap
+
+	if retry < 32 && p.BackoffBase<<uint(retry) < p.BackoffCap {
+		delay = p.BackoffBase << uint(retry)
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Classify a network error
+// Return its kind and whether the request may have reached the SOLIDserver
+func neterrorkind(err error) (string, bool) {
+	chain := causes(err)
+
+	for _, e := range chain {
+		if _, ok := e.(*net.DNSError); ok {
+			return "dns", false
+		}
+	}
+
+	if iserror(err, syscall.ECONNREFUSED) {
+		return "refused", false
+	}
+
+	for _, e := range chain {
+		if opErr, ok := e.(*net.OpError); ok && opErr.Op == "dial" {
+			if opErr.Timeout() {
+				return "timeout", false
+			}
+			return "", false
+		}
+	}
+
+	switch {
+	case iserror(err, syscall.ECONNRESET):
+		return "reset", true
+	case iserror(err, io.EOF), iserror(err, io.ErrUnexpectedEOF):
+		return "eof", true
+	}
+
+	for _, e := range chain {
+		if netErr, ok := e.(net.Error); ok && netErr.Timeout() {
+			return "timeout", true
+		}
+	}
+
+	return "", true
+}
+
+// Decide whether a failed API call can be sent again
+// Return whether it is retryable and whether the SOLIDserver may have processed it
+func (p RetryPolicy) retryable(resp *http.Response, err error) (bool, bool) {
+	if err != nil {
+		kind, sent := neterrorkind(err)
+		return p.RetryableNetworkErrors[kind], sent
+	}
+
+	// A throttled request was refused before being processed
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return p.RetryableStatuses[resp.StatusCode], false
+	}
+
+	return p.RetryableStatuses[resp.StatusCode], true
+}
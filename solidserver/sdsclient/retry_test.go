@@ -0,0 +1,168 @@
+package sdsclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetrySOLIDserver(t *testing.T, handler http.HandlerFunc) (*SOLIDserver, func()) {
+	server := httptest.NewTLSServer(handler)
+
+	retry, err := NewRetryPolicy(DefaultTimeout, 2, 1, 2, "503", "refused")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s := &SOLIDserver{BaseUrl: server.URL, Retry: retry, limiter: NewLimiter(0, 0)}
+
+	if err := s.initClient(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return s, server.Close
+}
+
+func TestRequest_RetryRead(t *testing.T) {
+	calls := 0
+
+	s, closer := testRetrySOLIDserver(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer closer()
+
+	resp, _, err := s.Request(context.Background(), "get", "rest/ip_site_list", &url.Values{})
+
+	if err != nil || resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("expected 3 calls ending with HTTP 200, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestRequest_NoRetryWrite(t *testing.T) {
+	calls := 0
+
+	s, closer := testRetrySOLIDserver(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer closer()
+
+	resp, _, err := s.Request(context.Background(), "post", "rest/ip_add", &url.Values{})
+
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("expected a single call ending with HTTP 503, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestRequest_RetryWriteAfterLookup(t *testing.T) {
+	calls := 0
+	lookups := 0
+
+	s, closer := testRetrySOLIDserver(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer closer()
+
+	resp, _, err := s.RequestWithLookup(context.Background(), "post", "rest/ip_add", &url.Values{}, func() (bool, error) {
+		lookups++
+		return false, nil
+	})
+
+	if err != nil || resp.StatusCode != http.StatusOK || calls != 2 || lookups != 1 {
+		t.Errorf("expected 2 calls and 1 lookup, got %d calls and %d lookups (%v)", calls, lookups, err)
+	}
+
+	calls = 0
+
+	resp, _, err = s.RequestWithLookup(context.Background(), "post", "rest/ip_add", &url.Values{}, func() (bool, error) {
+		return true, nil
+	})
+
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("expected a single call once the lookup found the object, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestNewRetryPolicy_Validation(t *testing.T) {
+	if _, err := NewRetryPolicy(0, 3, 128, 8000, DefaultRetryableStatuses, DefaultRetryableNetworkErrors); err == nil {
+		t.Error("expected an error for a null timeout")
+	}
+
+	if _, err := NewRetryPolicy(16, 3, 128, 8000, "503,abc", DefaultRetryableNetworkErrors); err == nil {
+		t.Error("expected an error for an invalid status")
+	}
+
+	if _, err := NewRetryPolicy(16, 3, 128, 8000, DefaultRetryableStatuses, "dns,flood"); err == nil {
+		t.Error("expected an error for an invalid network error")
+	}
+
+	p := DefaultRetryPolicy()
+
+	if !p.RetryableStatuses[429] || !p.RetryableNetworkErrors["refused"] {
+		t.Errorf("unexpected default policy: %+v", p)
+	}
+
+	for retry := 0; retry < 64; retry++ {
+		if delay := p.backoff(retry); delay > p.BackoffCap {
+			t.Errorf("backoff %d exceeds the cap: %s", retry, delay)
+		}
+	}
+}
+
+func TestRequest_CanceledBackoff(t *testing.T) {
+	calls := 0
+
+	s, closer := testRetrySOLIDserver(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer closer()
+
+	s.Retry.BackoffBase = 10 * time.Second
+	s.Retry.BackoffCap = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := s.Request(ctx, "get", "rest/ip_site_list", &url.Values{})
+
+	if !iserror(err, context.DeadlineExceeded) || calls != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("expected the backoff to be interrupted after a single call, got %d calls in %s (%v)", calls, time.Since(start), err)
+	}
+}
+
+func TestRequest_CanceledCall(t *testing.T) {
+	var calls int32
+
+	s, closer := testRetrySOLIDserver(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	defer closer()
+
+	// Timeouts of the SOLIDserver are retried, not the ones of the caller
+	s.Retry.RetryableNetworkErrors["timeout"] = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err := s.Request(ctx, "get", "rest/ip_site_list", &url.Values{})
+
+	if !iserror(err, context.DeadlineExceeded) || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("expected the call to be abandoned without retry, got %d calls (%v)", atomic.LoadInt32(&calls), err)
+	}
+}
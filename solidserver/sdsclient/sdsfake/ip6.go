@@ -0,0 +1,437 @@
+package sdsfake
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Register the IP v6 services of the fake SOLIDserver
+// IP v6 addresses are stored as 32 hexadecimal digits and returned in their non-compressed notation
+func (f *Server) register6() {
+	subnet := &fakeObject{
+		name:  "IP v6 subnet",
+		table: "ip6_subnet6",
+		id:    "subnet6_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet6_name": "", "start_ip6_addr": "", "end_ip6_addr": "", "subnet6_prefix": "",
+			"subnet_level": "", "is_terminal": "1", "parent_subnet6_id": "0", "parent_subnet6_name": "", "subnet6_class_name": "", "subnet6_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "subnet6_name": "subnet6_name", "subnet6_addr": "subnet6_addr", "subnet6_prefix": "subnet6_prefix",
+			"subnet_level": "subnet_level", "is_terminal": "is_terminal", "subnet6_class_name": "subnet6_class_name", "subnet6_class_parameters": "subnet6_class_parameters"},
+		check: (*Server).checksubnet6,
+	}
+
+	pool := &fakeObject{
+		name:  "IP v6 pool",
+		table: "ip6_pool6",
+		id:    "pool6_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet6_id": "", "subnet6_name": "", "pool6_name": "", "start_ip6_addr": "", "end_ip6_addr": "",
+			"pool6_size": "", "pool6_read_only": "0", "pool6_class_name": "", "pool6_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "subnet6_id": "subnet6_id", "pool6_name": "pool6_name", "start_addr": "start_addr", "pool6_size": "pool6_size",
+			"pool6_read_only": "pool6_read_only", "pool6_class_name": "pool6_class_name", "pool6_class_parameters": "pool6_class_parameters"},
+		check: (*Server).checkpool6,
+	}
+
+	address := &fakeObject{
+		name:  "IP v6 address",
+		table: "ip6_address6",
+		id:    "ip6_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet6_id": "", "subnet6_name": "", "ip6_addr": "", "hostaddr": "", "ip6_name": "", "ip6_mac_addr": "",
+			"hostdev_id": "0", "hostdev_name": "", "ip6_class_name": "", "ip6_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "site_name": "site_name", "hostaddr": "hostaddr", "ip6_name": "ip6_name", "mac_addr": "ip6_mac_addr",
+			"ip6_mac_addr": "ip6_mac_addr", "hostdev_id": "hostdev_id", "ip6_class_name": "ip6_class_name", "ip6_class_parameters": "ip6_class_parameters"},
+		check: (*Server).checkaddress6,
+	}
+
+	f.services["rest/ip6_subnet6_add"] = f.adder(subnet)
+	f.services["rest/ip6_block6_subnet6_info"] = f.infoer(subnet)
+	f.services["rest/ip6_block6_subnet6_list"] = f.lister("ip6_subnet6", "is_terminal")
+	f.services["rest/ip6_subnet6_delete"] = f.remover(subnet, f.cascadesubnet6)
+	f.services["rpc/ip6_find_free_subnet6"] = f.findfreesubnet6
+	f.services["rest/ip6_pool6_add"] = f.adder(pool)
+	f.services["rest/ip6_pool6_info"] = f.infoer(pool)
+	f.services["rest/ip6_pool6_list"] = f.lister("ip6_pool6")
+	f.services["rest/ip6_pool6_delete"] = f.remover(pool, nil)
+	f.services["rest/ip6_address6_add"] = f.adder(address)
+	f.services["rest/ip6_address6_info"] = f.infoer(address)
+	f.services["rest/ip6_address6_list"] = f.lister("ip6_address6")
+	f.services["rest/ip6_address6_delete"] = f.deleteaddress6
+	f.services["rpc/ip6_find_free_address6"] = f.findfreeaddress6
+}
+
+// First and last address of an IP v6 subnet or pool row
+func fakeRange6(row Row) (*big.Int, *big.Int) {
+	start, _ := new(big.Int).SetString(row["start_ip6_addr"], 16)
+	end, _ := new(big.Int).SetString(row["end_ip6_addr"], 16)
+
+	if start == nil || end == nil {
+		return new(big.Int), new(big.Int)
+	}
+
+	return start, end
+}
+
+func fakeContains6(row Row, start *big.Int, end *big.Int) bool {
+	rowStart, rowEnd := fakeRange6(row)
+
+	return rowStart.Cmp(start) <= 0 && end.Cmp(rowEnd) <= 0
+}
+
+func fakeOverlaps6(row Row, start *big.Int, end *big.Int) bool {
+	rowStart, rowEnd := fakeRange6(row)
+
+	return rowStart.Cmp(end) <= 0 && start.Cmp(rowEnd) <= 0
+}
+
+// Parse an IP v6 address given in its usual or hexadecimal notation
+func fakeParseIP6(ip string) (*big.Int, bool) {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return new(big.Int).SetBytes(parsed.To16()), true
+	}
+
+	if len(ip) == 32 {
+		if hex, valid := new(big.Int).SetString(ip, 16); valid {
+			return hex, true
+		}
+	}
+
+	return nil, false
+}
+
+// Format an IP v6 address in its hexadecimal notation
+func fakeHexIP6(ip *big.Int) string {
+	return fmt.Sprintf("%032x", ip)
+}
+
+// Format an IP v6 address in its non-compressed notation
+func fakeFormatIP6(ip *big.Int) string {
+	hex := fakeHexIP6(ip)
+	groups := []string{}
+
+	for i := 0; i < len(hex); i += 4 {
+		groups = append(groups, hex[i:i+4])
+	}
+
+	return strings.Join(groups, ":")
+}
+
+// Number of addresses of an IP v6 prefix
+func fakeSize6(prefix int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(128-prefix))
+}
+
+// Validate an IP v6 subnet and find its parent block
+func (f *Server) checksubnet6(row Row, create bool) *Answer {
+	if row["subnet6_name"] == "" {
+		return fakeFailure(http.StatusBadRequest, 2003, "IP v6 subnet name is required")
+	}
+
+	if !create {
+		return nil
+	}
+
+	site := f.Get("ip_site", "site_id", row["site_id"])
+
+	if site == nil {
+		return fakeFailure(http.StatusBadRequest, 2012, "Space (oid: %s) does not exist", row["site_id"])
+	}
+
+	start, valid := fakeParseIP6(row["subnet6_addr"])
+	prefix, prefixErr := strconv.Atoi(row["subnet6_prefix"])
+
+	if !valid || prefixErr != nil || prefix < 1 || prefix > 128 {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP v6 subnet %s/%s", row["subnet6_addr"], row["subnet6_prefix"])
+	}
+
+	size := fakeSize6(prefix)
+
+	if new(big.Int).Mod(start, size).Sign() != 0 {
+		return fakeFailure(http.StatusBadRequest, 2005, "IP v6 subnet %s/%d is not aligned on its size", fakeFormatIP6(start), prefix)
+	}
+
+	end := new(big.Int).Sub(new(big.Int).Add(start, size), big.NewInt(1))
+	row["site_name"] = site["site_name"]
+	row["start_ip6_addr"] = fakeHexIP6(start)
+	row["end_ip6_addr"] = fakeHexIP6(end)
+	row["subnet6_prefix"] = strconv.Itoa(prefix)
+	row["parent_subnet6_id"], row["parent_subnet6_name"] = "0", ""
+	delete(row, "subnet6_addr")
+
+	if row["subnet_level"] != "0" {
+		var parent Row
+
+		// The parent is the smallest block or non terminal subnet containing the new one
+		for _, candidate := range f.tables["ip6_subnet6"] {
+			if candidate["site_id"] == row["site_id"] && candidate["is_terminal"] == "0" && fakeContains6(candidate, start, end) {
+				if parent == nil || fakeInt(candidate["subnet6_prefix"]) > fakeInt(parent["subnet6_prefix"]) {
+					parent = candidate
+				}
+			}
+		}
+
+		if parent == nil {
+			return fakeFailure(http.StatusBadRequest, 2006, "No IP v6 block found for %s/%d in space %s", fakeFormatIP6(start), prefix, site["site_name"])
+		}
+
+		level, _ := strconv.Atoi(parent["subnet_level"])
+		row["parent_subnet6_id"], row["parent_subnet6_name"] = parent["subnet6_id"], parent["subnet6_name"]
+		row["subnet_level"] = strconv.Itoa(level + 1)
+	}
+
+	for _, other := range f.tables["ip6_subnet6"] {
+		if other["site_id"] == row["site_id"] && other["parent_subnet6_id"] == row["parent_subnet6_id"] && fakeOverlaps6(other, start, end) {
+			return fakeFailure(http.StatusBadRequest, 2007, "IP v6 subnet %s/%d overlaps %s", fakeFormatIP6(start), prefix, other["subnet6_name"])
+		}
+	}
+
+	return nil
+}
+
+// Remove the subnets, pools and addresses of a deleted IP v6 subnet
+func (f *Server) cascadesubnet6(row Row) {
+	children := f.delete("ip6_subnet6", func(r Row) bool {
+		childStart, childEnd := fakeRange6(r)
+		return r["site_id"] == row["site_id"] && fakeContains6(row, childStart, childEnd) && fakeInt(r["subnet_level"]) > fakeInt(row["subnet_level"])
+	})
+
+	for _, child := range append(children, row) {
+		f.delete("ip6_address6", func(r Row) bool { return r["subnet6_id"] == child["subnet6_id"] })
+		f.delete("ip6_pool6", func(r Row) bool { return r["subnet6_id"] == child["subnet6_id"] })
+	}
+}
+
+// Validate an IP v6 pool, it must fit in its terminal subnet without overlapping another pool
+func (f *Server) checkpool6(row Row, create bool) *Answer {
+	if row["pool6_name"] == "" {
+		return fakeFailure(http.StatusBadRequest, 2003, "IP v6 pool name is required")
+	}
+
+	if !create {
+		return nil
+	}
+
+	subnet := f.Get("ip6_subnet6", "subnet6_id", row["subnet6_id"])
+
+	if subnet == nil || subnet["site_id"] != row["site_id"] || subnet["is_terminal"] != "1" {
+		return fakeFailure(http.StatusBadRequest, 2012, "IP v6 subnet (oid: %s) does not exist", row["subnet6_id"])
+	}
+
+	start, valid := fakeParseIP6(row["start_addr"])
+	size, sizeValid := new(big.Int).SetString(row["pool6_size"], 10)
+
+	if !valid || !sizeValid || size.Sign() < 1 {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP v6 pool range: %s (%s addresses)", row["start_addr"], row["pool6_size"])
+	}
+
+	end := new(big.Int).Sub(new(big.Int).Add(start, size), big.NewInt(1))
+
+	if !fakeContains6(subnet, start, end) {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP v6 pool range: %s (%s addresses)", row["start_addr"], row["pool6_size"])
+	}
+
+	for _, other := range f.tables["ip6_pool6"] {
+		if other["subnet6_id"] == row["subnet6_id"] && fakeOverlaps6(other, start, end) {
+			return fakeFailure(http.StatusBadRequest, 2004, "IP v6 pool %s overlaps %s", row["pool6_name"], other["pool6_name"])
+		}
+	}
+
+	if f.exist("ip6_pool6", row, "pool6_id", "subnet6_id", "pool6_name") {
+		return fakeFailure(http.StatusBadRequest, 2004, "IP v6 pool %s already exists", row["pool6_name"])
+	}
+
+	delete(row, "start_addr")
+	row["site_name"], row["subnet6_name"] = subnet["site_name"], subnet["subnet6_name"]
+	row["start_ip6_addr"], row["end_ip6_addr"] = fakeHexIP6(start), fakeHexIP6(end)
+
+	return nil
+}
+
+// Suggest the first free IP v6 subnets of a block, starting at begin_addr if given
+func (f *Server) findfreesubnet6(method string, p url.Values) Answer {
+	block := f.Get("ip6_subnet6", "subnet6_id", p.Get("block6_id"))
+
+	if block == nil || block["site_id"] != p.Get("site_id") {
+		return NotFound("IP v6 block", p.Get("block6_id"))
+	}
+
+	prefix, prefixErr := strconv.Atoi(p.Get("prefix"))
+
+	if prefixErr != nil || prefix < 1 || prefix > 128 {
+		return Error(http.StatusBadRequest, 2005, "Invalid prefix: %s", p.Get("prefix"))
+	}
+
+	max, maxErr := strconv.Atoi(p.Get("max_find"))
+
+	if maxErr != nil || max <= 0 {
+		max = 1
+	}
+
+	size := fakeSize6(prefix)
+	last := new(big.Int).Sub(size, big.NewInt(1))
+	start, end := fakeRange6(block)
+	candidate := new(big.Int).Set(start)
+
+	if begin, valid := fakeParseIP6(p.Get("begin_addr")); valid && begin.Cmp(start) > 0 {
+		candidate = new(big.Int).Mul(new(big.Int).Div(new(big.Int).Add(begin, last), size), size)
+	}
+
+	rows := []Row{}
+
+	for ; new(big.Int).Add(candidate, last).Cmp(end) <= 0 && len(rows) < max; candidate = new(big.Int).Add(candidate, size) {
+		candidateEnd := new(big.Int).Add(candidate, last)
+		free := true
+
+		for _, other := range f.tables["ip6_subnet6"] {
+			if other["parent_subnet6_id"] == block["subnet6_id"] && fakeOverlaps6(other, candidate, candidateEnd) {
+				free = false
+			}
+		}
+
+		if free {
+			rows = append(rows, Row{"start_ip6_addr": fakeHexIP6(candidate), "end_ip6_addr": fakeHexIP6(candidateEnd),
+				"site_id": block["site_id"], "block6_id": block["subnet6_id"]})
+		}
+	}
+
+	if len(rows) == 0 {
+		return Answer{status: http.StatusNoContent}
+	}
+
+	return Answer{http.StatusOK, rows}
+}
+
+// Validate an IP v6 address and find its subnet
+func (f *Server) checkaddress6(row Row, create bool) *Answer {
+	if create {
+		site := f.Get("ip_site", "site_id", row["site_id"])
+
+		if site == nil {
+			site = f.Get("ip_site", "site_name", row["site_name"])
+		}
+
+		if site == nil {
+			return fakeFailure(http.StatusBadRequest, 2012, "Space (oid: %s) does not exist", row["site_id"])
+		}
+
+		ip, valid := fakeParseIP6(row["hostaddr"])
+
+		if !valid {
+			return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP v6 address: %s", row["hostaddr"])
+		}
+
+		var subnet Row
+
+		for _, candidate := range f.tables["ip6_subnet6"] {
+			if candidate["site_id"] == site["site_id"] && candidate["is_terminal"] == "1" && fakeContains6(candidate, ip, ip) {
+				subnet = candidate
+			}
+		}
+
+		if subnet == nil {
+			return fakeFailure(http.StatusBadRequest, 2006, "No IP v6 subnet found for %s in space %s", fakeFormatIP6(ip), site["site_name"])
+		}
+
+		row["site_id"], row["site_name"] = site["site_id"], site["site_name"]
+		row["subnet6_id"], row["subnet6_name"] = subnet["subnet6_id"], subnet["subnet6_name"]
+		row["ip6_addr"], row["hostaddr"] = fakeHexIP6(ip), fakeFormatIP6(ip)
+
+		if f.exist("ip6_address6", row, "ip6_id", "site_id", "ip6_addr") {
+			return fakeFailure(http.StatusBadRequest, 2008, "IP v6 address %s is already used", fakeFormatIP6(ip))
+		}
+	}
+
+	row["hostdev_name"] = ""
+
+	if row["hostdev_id"] == "" {
+		row["hostdev_id"] = "0"
+	}
+
+	if row["hostdev_id"] != "0" {
+		device := f.Get("hostdev", "hostdev_id", row["hostdev_id"])
+
+		if device == nil {
+			return fakeFailure(http.StatusBadRequest, 2012, "Device (oid: %s) does not exist", row["hostdev_id"])
+		}
+
+		row["hostdev_name"] = device["hostdev_name"]
+	}
+
+	return nil
+}
+
+// Delete an IP v6 address from its oid, or from its space and address
+func (f *Server) deleteaddress6(method string, p url.Values) Answer {
+	var match func(row Row) bool
+
+	if oid := p.Get("ip6_id"); oid != "" {
+		match = func(row Row) bool { return row["ip6_id"] == oid }
+	} else {
+		ip, valid := fakeParseIP6(p.Get("hostaddr"))
+
+		match = func(row Row) bool {
+			return valid && (row["site_id"] == p.Get("site_id") || strings.EqualFold(row["site_name"], p.Get("site_name"))) && row["ip6_addr"] == fakeHexIP6(ip)
+		}
+	}
+
+	deleted := f.delete("ip6_address6", match)
+
+	if len(deleted) == 0 {
+		return NotFound("IP v6 address", p.Get("ip6_id")+p.Get("hostaddr"))
+	}
+
+	return Answer{http.StatusOK, []Row{{"ret_oid": deleted[0]["ip6_id"], "ret_msg": "IP v6 address deleted"}}}
+}
+
+// Suggest the first free addresses of an IP v6 subnet, the first address of the subnet is excluded
+// Addresses of read-only pools are never suggested
+func (f *Server) findfreeaddress6(method string, p url.Values) Answer {
+	subnet := f.Get("ip6_subnet6", "subnet6_id", p.Get("subnet6_id"))
+
+	if subnet == nil || subnet["is_terminal"] != "1" {
+		return NotFound("IP v6 subnet", p.Get("subnet6_id"))
+	}
+
+	max, maxErr := strconv.Atoi(p.Get("max_find"))
+
+	if maxErr != nil || max <= 0 {
+		max = 1
+	}
+
+	used := map[string]bool{}
+
+	for _, row := range f.tables["ip6_address6"] {
+		if row["subnet6_id"] == subnet["subnet6_id"] {
+			used[row["ip6_addr"]] = true
+		}
+	}
+
+	start, end := fakeRange6(subnet)
+	rows := []Row{}
+
+	for ip := new(big.Int).Add(start, big.NewInt(1)); ip.Cmp(end) <= 0 && len(rows) < max; ip = new(big.Int).Add(ip, big.NewInt(1)) {
+		if used[fakeHexIP6(ip)] {
+			continue
+		}
+
+		readOnly := false
+
+		for _, pool := range f.tables["ip6_pool6"] {
+			if pool["subnet6_id"] == subnet["subnet6_id"] && pool["pool6_read_only"] == "1" && fakeContains6(pool, ip, ip) {
+				readOnly = true
+			}
+		}
+
+		if !readOnly {
+			rows = append(rows, Row{"hostaddr6": fakeFormatIP6(ip), "ip6_addr": fakeHexIP6(ip), "site_id": subnet["site_id"], "subnet6_id": subnet["subnet6_id"]})
+		}
+	}
+
+	if len(rows) == 0 {
+		return Answer{status: http.StatusNoContent}
+	}
+
+	return Answer{http.StatusOK, rows}
+}
@@ -0,0 +1,1363 @@
+// Package sdsfake provides an in-memory SOLIDserver for the tests of the client and of the provider
+package sdsfake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Credentials accepted by the fake SOLIDserver
+const (
+	Username = "ipmadmin"
+	Password = "admin"
+	Version  = "7.1.0.12345"
+)
+
+// Row of the fake SOLIDserver, every value is a string as in the real API
+type Row map[string]string
+
+func (r Row) copy() Row {
+	c := Row{}
+
+	for k, v := range r {
+		c[k] = v
+	}
+
+	return c
+}
+
+// Answer of a fake service
+type Answer struct {
+	status int
+	rows   []Row
+}
+
+// Error answers a SOLIDserver error with its errno
+func Error(status int, errno int, format string, args ...interface{}) Answer {
+	return Answer{status, []Row{{
+		"errno":    strconv.Itoa(errno),
+		"errmsg":   fmt.Sprintf(format, args...),
+		"severity": "ERROR",
+	}}}
+}
+
+// NotFound answers the error of a service given an unknown oid
+func NotFound(object string, oid string) Answer {
+	return Error(http.StatusBadRequest, 2012, "%s (oid: %s) does not exist", object, oid)
+}
+
+// An object type managed through a rest/*_add service
+type fakeObject struct {
+	name     string
+	table    string
+	id       string
+	defaults Row
+	// Parameters copied to the row on creation and edition, parameter -> column
+	fields map[string]string
+	// Validate the row and fill the computed columns
+	check func(f *Server, row Row, create bool) *Answer
+}
+
+// Server is an in-memory SOLIDserver answering the rest and rpc services used by the provider
+// Objects get their oid from a single sequence, addresses, subnets and vlans are allocated as on an appliance
+type Server struct {
+	*httptest.Server
+	t        *testing.T
+	mutex    sync.Mutex
+	oid      int
+	tables   map[string][]Row
+	services map[string]Service
+	calls    map[string]int
+	// Role of the node in its cluster, only the master answers the API calls
+	role string
+}
+
+// Service answers a call to a rest or rpc service
+type Service func(method string, p url.Values) Answer
+
+// New starts a fake SOLIDserver, it must be closed by the test
+func New(t *testing.T) *Server {
+	f := &Server{
+		t:      t,
+		tables: map[string][]Row{},
+		calls:  map[string]int{},
+		role:   "master",
+	}
+
+	f.register()
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+
+	return f
+}
+
+func (f *Server) serve(w http.ResponseWriter, r *http.Request) {
+	service := strings.TrimPrefix(r.URL.Path, "/")
+	answer := Error(http.StatusUnauthorized, 401, "Authentication failed")
+
+	username, _ := base64.StdEncoding.DecodeString(r.Header.Get("X-IPM-Username"))
+	password, _ := base64.StdEncoding.DecodeString(r.Header.Get("X-IPM-Password"))
+
+	if string(username) == Username && string(password) == Password {
+		f.mutex.Lock()
+		answer = f.call(r.Method, service, r.URL.Query())
+		f.mutex.Unlock()
+	}
+
+	if len(answer.rows) == 0 {
+		w.WriteHeader(answer.status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(answer.status)
+	json.NewEncoder(w).Encode(answer.rows)
+}
+
+func (f *Server) call(method string, service string, p url.Values) Answer {
+	handler, known := f.services[service]
+
+	if !known {
+		f.t.Errorf("fake SOLIDserver: unsupported service %s", service)
+		return Error(http.StatusBadRequest, 1, "Unsupported service: %s", service)
+	}
+
+	expected := http.MethodGet
+
+	switch {
+	case strings.HasSuffix(service, "_add"):
+		expected = http.MethodPost
+		if method == http.MethodPut {
+			expected = http.MethodPut
+		}
+	case strings.HasSuffix(service, "_delete"):
+		expected = http.MethodDelete
+	}
+
+	if method != expected {
+		f.t.Errorf("fake SOLIDserver: unexpected method %s on %s", method, service)
+		return Error(http.StatusBadRequest, 1, "Invalid method %s for %s", method, service)
+	}
+
+	f.calls[service]++
+
+	if f.role != "master" && service != "rest/member_list" {
+		return Error(http.StatusBadRequest, 2030, "This node is not the master of the cluster (role: %s)", f.role)
+	}
+
+	return handler(method, p)
+}
+
+// Handle replaces the handler of a service and returns the previous one
+// Handlers run with the server locked, they may use Get but none of the other exported methods
+func (f *Server) Handle(service string, handler Service) Service {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	previous := f.services[service]
+	f.services[service] = handler
+
+	return previous
+}
+
+// SetRole changes the role of the node in its cluster
+func (f *Server) SetRole(role string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.role = role
+}
+
+// Count returns the number of calls received by a service
+func (f *Server) Count(service string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.calls[service]
+}
+
+// Rows returns a copy of the rows of a table matching a column
+func (f *Server) Rows(table string, column string, value string) []Row {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	rows := []Row{}
+
+	for _, row := range f.tables[table] {
+		if strings.EqualFold(row[column], value) {
+			rows = append(rows, row.copy())
+		}
+	}
+
+	return rows
+}
+
+// Remove deletes an object behind the provider's back, as another client would
+func (f *Server) Remove(table string, column string, value string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.delete(table, func(row Row) bool { return row[column] == value })
+}
+
+// Get returns the row of a table matching a column, it must only be called by service handlers
+func (f *Server) Get(table string, column string, value string) Row {
+	for _, row := range f.tables[table] {
+		if value != "" && strings.EqualFold(row[column], value) {
+			return row
+		}
+	}
+
+	return nil
+}
+
+func (f *Server) exist(table string, row Row, id string, columns ...string) bool {
+	for _, other := range f.tables[table] {
+		if other[id] == row[id] {
+			continue
+		}
+
+		same := true
+
+		for _, column := range columns {
+			same = same && strings.EqualFold(other[column], row[column])
+		}
+
+		if same {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Server) delete(table string, match func(row Row) bool) []Row {
+	kept := []Row{}
+	deleted := []Row{}
+
+	for _, row := range f.tables[table] {
+		if match(row) {
+			deleted = append(deleted, row)
+		} else {
+			kept = append(kept, row)
+		}
+	}
+
+	f.tables[table] = kept
+
+	return deleted
+}
+
+// Create or edit an object, as the rest/*_add services do
+func (f *Server) add(o *fakeObject, method string, p url.Values) Answer {
+	oid := p.Get(o.id)
+	create := method == http.MethodPost && oid == ""
+	row := o.defaults.copy()
+
+	if create {
+		if p.Get("add_flag") == "edit_only" {
+			return Error(http.StatusBadRequest, 2001, "%s: missing %s in edit_only mode", o.name, o.id)
+		}
+	} else {
+		existing := f.Get(o.table, o.id, oid)
+
+		if existing == nil {
+			return NotFound(o.name, oid)
+		}
+
+		if p.Get("add_flag") == "new_only" {
+			return Error(http.StatusBadRequest, 2004, "%s (oid: %s) already exists", o.name, oid)
+		}
+
+		row = existing.copy()
+	}
+
+	for parameter, column := range o.fields {
+		if values, given := p[parameter]; given {
+			row[column] = values[0]
+		}
+	}
+
+	if create {
+		f.oid++
+		row[o.id] = strconv.Itoa(f.oid)
+	}
+
+	if o.check != nil {
+		if failure := o.check(f, row, create); failure != nil {
+			if create {
+				f.oid--
+			}
+			return *failure
+		}
+	}
+
+	if create {
+		f.tables[o.table] = append(f.tables[o.table], row)
+
+		return Answer{http.StatusCreated, []Row{{"ret_oid": row[o.id], "ret_msg": o.name + " created"}}}
+	}
+
+	for i, existing := range f.tables[o.table] {
+		if existing[o.id] == row[o.id] {
+			f.tables[o.table][i] = row
+		}
+	}
+
+	return Answer{http.StatusOK, []Row{{"ret_oid": row[o.id], "ret_msg": o.name + " updated"}}}
+}
+
+// Answer a rest/*_info service
+func (f *Server) info(o *fakeObject, p url.Values) Answer {
+	row := f.Get(o.table, o.id, p.Get(o.id))
+
+	if row == nil {
+		return NotFound(o.name, p.Get(o.id))
+	}
+
+	return Answer{http.StatusOK, []Row{row.copy()}}
+}
+
+// Answer a rest/*_list service, filtering rows with the WHERE parameter and paging with limit and offset
+func (f *Server) list(rows []Row, p url.Values) Answer {
+	where, err := fakeParseWhere(p.Get("WHERE"))
+
+	if err != nil {
+		f.t.Errorf("fake SOLIDserver: %s", err)
+		return Error(http.StatusBadRequest, 1, "Invalid WHERE clause: %s", err)
+	}
+
+	matching := []Row{}
+
+	for _, row := range rows {
+		if where(row) {
+			matching = append(matching, row.copy())
+		}
+	}
+
+	offset, _ := strconv.Atoi(p.Get("offset"))
+	limit, limitErr := strconv.Atoi(p.Get("limit"))
+
+	if offset > len(matching) {
+		offset = len(matching)
+	}
+
+	matching = matching[offset:]
+
+	if limitErr == nil && limit >= 0 && limit < len(matching) {
+		matching = matching[:limit]
+	}
+
+	if len(matching) == 0 {
+		return Answer{status: http.StatusNoContent}
+	}
+
+	return Answer{http.StatusOK, matching}
+}
+
+// Answer a rest/*_delete service, dependents are removed by the cascade function
+func (f *Server) remover(o *fakeObject, cascade func(row Row)) Service {
+	return func(method string, p url.Values) Answer {
+		oid := p.Get(o.id)
+		deleted := f.delete(o.table, func(row Row) bool { return oid != "" && row[o.id] == oid })
+
+		if len(deleted) == 0 {
+			return NotFound(o.name, oid)
+		}
+
+		if cascade != nil {
+			cascade(deleted[0])
+		}
+
+		return Answer{http.StatusOK, []Row{{"ret_oid": oid, "ret_msg": o.name + " deleted"}}}
+	}
+}
+
+func (f *Server) infoer(o *fakeObject) Service {
+	return func(method string, p url.Values) Answer { return f.info(o, p) }
+}
+
+func (f *Server) adder(o *fakeObject) Service {
+	return func(method string, p url.Values) Answer { return f.add(o, method, p) }
+}
+
+func (f *Server) lister(table string, filters ...string) Service {
+	return func(method string, p url.Values) Answer {
+		rows := []Row{}
+
+		for _, row := range f.tables[table] {
+			match := true
+
+			// Some list services accept filters as plain parameters
+			for _, filter := range filters {
+				if value := p.Get(filter); value != "" && row[filter] != value {
+					match = false
+				}
+			}
+
+			if match {
+				rows = append(rows, row)
+			}
+		}
+
+		return f.list(rows, p)
+	}
+}
+
+// Register the services of the fake SOLIDserver
+func (f *Server) register() {
+	site := &fakeObject{
+		name:     "Space",
+		table:    "ip_site",
+		id:       "site_id",
+		defaults: Row{"site_name": "", "site_class_name": "", "site_class_parameters": ""},
+		fields:   map[string]string{"site_name": "site_name", "site_class_name": "site_class_name", "site_class_parameters": "site_class_parameters"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["site_name"] == "" {
+				return fakeFailure(http.StatusBadRequest, 2003, "Space name is required")
+			}
+			if f.exist("ip_site", row, "site_id", "site_name") {
+				return fakeFailure(http.StatusBadRequest, 2004, "Space %s already exists", row["site_name"])
+			}
+			return nil
+		},
+	}
+
+	subnet := &fakeObject{
+		name:  "IP subnet",
+		table: "ip_subnet",
+		id:    "subnet_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet_name": "", "start_ip_addr": "", "end_ip_addr": "", "subnet_size": "",
+			"subnet_level": "", "is_terminal": "1", "parent_subnet_id": "0", "parent_subnet_name": "", "subnet_class_name": "", "subnet_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "subnet_name": "subnet_name", "subnet_addr": "subnet_addr", "subnet_prefix": "subnet_prefix",
+			"subnet_level": "subnet_level", "is_terminal": "is_terminal", "subnet_class_name": "subnet_class_name", "subnet_class_parameters": "subnet_class_parameters"},
+		check: (*Server).checksubnet,
+	}
+
+	address := &fakeObject{
+		name:  "IP address",
+		table: "ip_address",
+		id:    "ip_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet_id": "", "subnet_name": "", "ip_addr": "", "hostaddr": "", "name": "", "mac_addr": "",
+			"hostdev_id": "0", "hostdev_name": "", "ip_class_name": "", "ip_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "site_name": "site_name", "hostaddr": "hostaddr", "name": "name", "mac_addr": "mac_addr",
+			"hostdev_id": "hostdev_id", "ip_class_name": "ip_class_name", "ip_class_parameters": "ip_class_parameters"},
+		check: (*Server).checkaddress,
+	}
+
+	pool := &fakeObject{
+		name:  "IP pool",
+		table: "ip_pool",
+		id:    "pool_id",
+		defaults: Row{"site_id": "", "site_name": "", "subnet_id": "", "subnet_name": "", "pool_name": "", "start_ip_addr": "", "end_ip_addr": "",
+			"pool_size": "", "pool_read_only": "0", "pool_class_name": "", "pool_class_parameters": ""},
+		fields: map[string]string{"site_id": "site_id", "subnet_id": "subnet_id", "pool_name": "pool_name", "start_addr": "start_addr", "pool_size": "pool_size",
+			"pool_read_only": "pool_read_only", "pool_class_name": "pool_class_name", "pool_class_parameters": "pool_class_parameters"},
+		check: (*Server).checkpool,
+	}
+
+	zone := &fakeObject{
+		name:  "DNS zone",
+		table: "dns_zone",
+		id:    "dnszone_id",
+		defaults: Row{"dns_name": "", "dnsview_name": "#", "dnszone_name": "", "dnszone_type": "master", "dnszone_site_id": "0", "dnszone_site_name": "#",
+			"dnszone_class_name": "", "dnszone_class_parameters": ""},
+		fields: map[string]string{"dns_name": "dns_name", "dnsview_name": "dnsview_name", "dnszone_name": "dnszone_name", "dnszone_type": "dnszone_type",
+			"dnszone_site_id": "dnszone_site_id", "dnszone_class_name": "dnszone_class_name", "dnszone_class_parameters": "dnszone_class_parameters"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["dns_name"] == "" || row["dnszone_name"] == "" {
+				return fakeFailure(http.StatusBadRequest, 2003, "DNS server and zone name are required")
+			}
+			row["dnszone_site_name"] = "#"
+			if site := f.Get("ip_site", "site_id", row["dnszone_site_id"]); site != nil {
+				row["dnszone_site_name"] = site["site_name"]
+			} else if row["dnszone_site_id"] != "" && row["dnszone_site_id"] != "0" {
+				return fakeFailure(http.StatusBadRequest, 2012, "Space (oid: %s) does not exist", row["dnszone_site_id"])
+			}
+			if f.exist("dns_zone", row, "dnszone_id", "dns_name", "dnsview_name", "dnszone_name") {
+				return fakeFailure(http.StatusBadRequest, 2004, "DNS zone %s already exists", row["dnszone_name"])
+			}
+			return nil
+		},
+	}
+
+	rr := &fakeObject{
+		name:  "RR",
+		table: "dns_rr",
+		id:    "rr_id",
+		defaults: Row{"dns_name": "", "dnsview_name": "#", "dnszone_id": "", "dnszone_name": "", "rr_full_name": "", "rr_type": "", "ttl": "3600",
+			"value1": ""},
+		fields: map[string]string{"dns_name": "dns_name", "dnsview_name": "dnsview_name", "rr_name": "rr_full_name", "rr_type": "rr_type", "rr_ttl": "ttl",
+			"value1": "value1"},
+		check: (*Server).checkrr,
+	}
+
+	domain := &fakeObject{
+		name:  "VLAN domain",
+		table: "vlmdomain",
+		id:    "vlmdomain_id",
+		defaults: Row{"vlmdomain_name": "", "vlmdomain_start_vlan_id": "1", "vlmdomain_end_vlan_id": "4094", "support_vxlan": "0",
+			"vlmdomain_class_name": "", "vlmdomain_class_parameters": ""},
+		fields: map[string]string{"vlmdomain_name": "vlmdomain_name", "support_vxlan": "support_vxlan", "vlmdomain_class_name": "vlmdomain_class_name",
+			"vlmdomain_class_parameters": "vlmdomain_class_parameters"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["vlmdomain_name"] == "" {
+				return fakeFailure(http.StatusBadRequest, 2003, "VLAN domain name is required")
+			}
+			if f.exist("vlmdomain", row, "vlmdomain_id", "vlmdomain_name") {
+				return fakeFailure(http.StatusBadRequest, 2004, "VLAN domain %s already exists", row["vlmdomain_name"])
+			}
+			return nil
+		},
+	}
+
+	vlan := &fakeObject{
+		name:     "VLAN",
+		table:    "vlmvlan",
+		id:       "vlmvlan_id",
+		defaults: Row{"vlmdomain_id": "", "vlmdomain_name": "", "vlmvlan_vlan_id": "", "vlmvlan_name": "", "type": "vlan", "row_enabled": "1"},
+		fields:   map[string]string{"vlmdomain_name": "vlmdomain_name", "vlmvlan_vlan_id": "vlmvlan_vlan_id", "vlmvlan_name": "vlmvlan_name"},
+		check:    (*Server).checkvlan,
+	}
+
+	device := &fakeObject{
+		name:     "Device",
+		table:    "hostdev",
+		id:       "hostdev_id",
+		defaults: Row{"hostdev_name": "", "hostdev_class_name": "", "hostdev_class_parameters": ""},
+		fields:   map[string]string{"hostdev_name": "hostdev_name", "hostdev_class_name": "hostdev_class_name", "hostdev_class_parameters": "hostdev_class_parameters"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["hostdev_name"] == "" {
+				return fakeFailure(http.StatusBadRequest, 2003, "Device name is required")
+			}
+			if f.exist("hostdev", row, "hostdev_id", "hostdev_name") {
+				return fakeFailure(http.StatusBadRequest, 2004, "Device %s already exists", row["hostdev_name"])
+			}
+			return nil
+		},
+	}
+
+	user := &fakeObject{
+		name:  "User",
+		table: "user",
+		id:    "usr_id",
+		defaults: Row{"usr_login": "", "usr_description": "", "usr_fname": "", "usr_lname": "", "usr_email": "", "usr_class_name": "",
+			"usr_class_parameters": ""},
+		fields: map[string]string{"usr_login": "usr_login", "usr_description": "usr_description", "usr_fname": "usr_fname", "usr_lname": "usr_lname",
+			"usr_email": "usr_email", "usr_password": "usr_password"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["usr_login"] == "" || (create && row["usr_password"] == "") {
+				return fakeFailure(http.StatusBadRequest, 2003, "User login and password are required")
+			}
+			if f.exist("user", row, "usr_id", "usr_login") {
+				return fakeFailure(http.StatusBadRequest, 2004, "User %s already exists", row["usr_login"])
+			}
+			// Passwords are never returned
+			delete(row, "usr_password")
+			return nil
+		},
+	}
+
+	group := &fakeObject{
+		name:     "Group",
+		table:    "group",
+		id:       "grp_id",
+		defaults: Row{"grp_name": "", "grp_description": "", "grp_category": "user"},
+		fields:   map[string]string{"grp_name": "grp_name", "grp_description": "grp_description"},
+		check: func(f *Server, row Row, create bool) *Answer {
+			if row["grp_name"] == "" {
+				return fakeFailure(http.StatusBadRequest, 2003, "Group name is required")
+			}
+			if f.exist("group", row, "grp_id", "grp_name") {
+				return fakeFailure(http.StatusBadRequest, 2004, "Group %s already exists", row["grp_name"])
+			}
+			return nil
+		},
+	}
+
+	f.services = map[string]Service{
+		"rest/member_list": func(method string, p url.Values) Answer {
+			members := []Row{{"member_id": "1", "member_name": "sds.local", "member_is_me": "1", "member_role": f.role, "member_version": Version}}
+			return f.list(members, p)
+		},
+
+		"rest/ip_site_add":  f.adder(site),
+		"rest/ip_site_info": f.infoer(site),
+		"rest/ip_site_list": f.lister("ip_site"),
+		"rest/ip_site_delete": f.remover(site, func(row Row) {
+			f.delete("ip_subnet", func(r Row) bool { return r["site_id"] == row["site_id"] })
+			f.delete("ip_address", func(r Row) bool { return r["site_id"] == row["site_id"] })
+			f.delete("ip6_subnet6", func(r Row) bool { return r["site_id"] == row["site_id"] })
+			f.delete("ip6_pool6", func(r Row) bool { return r["site_id"] == row["site_id"] })
+			f.delete("ip6_address6", func(r Row) bool { return r["site_id"] == row["site_id"] })
+		}),
+
+		"rest/ip_subnet_add":         f.adder(subnet),
+		"rest/ip_block_subnet_info":  f.infoer(subnet),
+		"rest/ip_block_subnet_list":  f.lister("ip_subnet", "is_terminal"),
+		"rest/ip_subnet_delete":      f.remover(subnet, f.cascadesubnet),
+		"rpc/ip_find_free_subnet":    f.findfreesubnet,
+		"rest/ip_pool_add":           f.adder(pool),
+		"rest/ip_pool_info":          f.infoer(pool),
+		"rest/ip_pool_list":          f.lister("ip_pool"),
+		"rest/ip_pool_delete":        f.remover(pool, nil),
+		"rest/ip_add":                f.adder(address),
+		"rest/ip_address_info":       f.infoer(address),
+		"rest/ip_address_list":       f.lister("ip_address"),
+		"rest/ip_delete":             f.deleteaddress,
+		"rpc/ip_find_free_address":   f.findfreeaddress,
+		"rest/dns_zone_add":          f.adder(zone),
+		"rest/dns_zone_info":         f.infoer(zone),
+		"rest/dns_zone_list":         f.lister("dns_zone"),
+		"rest/dns_rr_add":            f.adder(rr),
+		"rest/dns_rr_info":           f.infoer(rr),
+		"rest/dns_rr_list":           f.lister("dns_rr"),
+		"rest/dns_rr_delete":         f.remover(rr, nil),
+		"rest/vlm_domain_add":        f.adder(domain),
+		"rest/vlmdomain_info":        f.infoer(domain),
+		"rest/vlmdomain_name":        f.lister("vlmdomain"),
+		"rest/vlmdomain_list":        f.lister("vlmdomain"),
+		"rest/vlm_vlan_add":          f.adder(vlan),
+		"rest/vlmvlan_info":          f.infoer(vlan),
+		"rest/vlmvlan_list":          f.listvlans,
+		"rest/vlm_vlan_delete":       f.remover(vlan, nil),
+		"rest/hostdev_add":           f.adder(device),
+		"rest/hostdev_info":          f.infoer(device),
+		"rest/hostdev_list":          f.lister("hostdev"),
+		"rest/user_add":              f.adder(user),
+		"rest/user_info":             f.infoer(user),
+		"rest/user_admin_info":       f.infoer(user),
+		"rest/user_admin_list":       f.lister("user"),
+		"rest/user_admin_group_list": f.listusergroups,
+		"rest/group_add":             f.adder(group),
+		"rest/group_admin_info":      f.infoer(group),
+		"rest/group_admin_list":      f.lister("group"),
+		"rest/group_user_add":        f.addgroupuser,
+		"rest/group_user_delete":     f.deletegroupuser,
+	}
+
+	f.services["rest/dns_zone_delete"] = f.remover(zone, func(row Row) {
+		f.delete("dns_rr", func(r Row) bool { return r["dnszone_id"] == row["dnszone_id"] })
+	})
+
+	f.services["rest/vlm_domain_delete"] = f.remover(domain, func(row Row) {
+		f.delete("vlmvlan", func(r Row) bool { return r["vlmdomain_id"] == row["vlmdomain_id"] })
+	})
+
+	f.services["rest/hostdev_delete"] = f.remover(device, func(row Row) {
+		for _, r := range f.tables["ip_address"] {
+			if r["hostdev_id"] == row["hostdev_id"] {
+				r["hostdev_id"], r["hostdev_name"] = "0", ""
+			}
+		}
+	})
+
+	f.services["rest/user_delete"] = f.remover(user, func(row Row) {
+		f.delete("group_user", func(r Row) bool { return r["usr_id"] == row["usr_id"] })
+	})
+
+	f.services["rest/group_delete"] = f.remover(group, func(row Row) {
+		f.delete("group_user", func(r Row) bool { return r["grp_id"] == row["grp_id"] })
+	})
+
+	f.register6()
+}
+
+func fakeFailure(status int, errno int, format string, args ...interface{}) *Answer {
+	answer := Error(status, errno, format, args...)
+	return &answer
+}
+
+func fakeInt(value string) int {
+	i, _ := strconv.Atoi(value)
+	return i
+}
+
+// First and last address of a subnet row
+func fakeRange(row Row) (uint32, uint32) {
+	start, _ := strconv.ParseUint(row["start_ip_addr"], 16, 32)
+	end, _ := strconv.ParseUint(row["end_ip_addr"], 16, 32)
+
+	return uint32(start), uint32(end)
+}
+
+func fakeContains(row Row, start uint32, end uint32) bool {
+	rowStart, rowEnd := fakeRange(row)
+
+	return rowStart <= start && end <= rowEnd
+}
+
+func fakeOverlaps(row Row, start uint32, end uint32) bool {
+	rowStart, rowEnd := fakeRange(row)
+
+	return rowStart <= end && start <= rowEnd
+}
+
+// Parse an IPv4 address given in dotted or hexadecimal notation
+func fakeParseIP(ip string) (uint32, bool) {
+	if parsed := net.ParseIP(ip).To4(); parsed != nil {
+		return uint32(parsed[0])<<24 | uint32(parsed[1])<<16 | uint32(parsed[2])<<8 | uint32(parsed[3]), true
+	}
+
+	if hex, err := strconv.ParseUint(ip, 16, 32); err == nil && len(ip) == 8 {
+		return uint32(hex), true
+	}
+
+	return 0, false
+}
+
+// Format an IPv4 address in dotted notation
+func fakeFormatIP(ip uint32) string {
+	return net.IPv4(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)).String()
+}
+
+// Validate a subnet and find its parent block
+func (f *Server) checksubnet(row Row, create bool) *Answer {
+	if row["subnet_name"] == "" {
+		return fakeFailure(http.StatusBadRequest, 2003, "IP subnet name is required")
+	}
+
+	if !create {
+		return nil
+	}
+
+	site := f.Get("ip_site", "site_id", row["site_id"])
+
+	if site == nil {
+		return fakeFailure(http.StatusBadRequest, 2012, "Space (oid: %s) does not exist", row["site_id"])
+	}
+
+	start, valid := fakeParseIP(row["subnet_addr"])
+	prefix, prefixErr := strconv.Atoi(row["subnet_prefix"])
+
+	if !valid || prefixErr != nil || prefix < 1 || prefix > 32 {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP subnet %s/%s", row["subnet_addr"], row["subnet_prefix"])
+	}
+
+	size := uint32(1) << uint(32-prefix)
+
+	if start%size != 0 {
+		return fakeFailure(http.StatusBadRequest, 2005, "IP subnet %s/%d is not aligned on its size", fakeFormatIP(start), prefix)
+	}
+
+	end := start + size - 1
+	row["site_name"] = site["site_name"]
+	row["start_ip_addr"] = fmt.Sprintf("%08x", start)
+	row["end_ip_addr"] = fmt.Sprintf("%08x", end)
+	row["subnet_size"] = strconv.FormatUint(uint64(size), 10)
+	row["parent_subnet_id"], row["parent_subnet_name"] = "0", ""
+	delete(row, "subnet_addr")
+	delete(row, "subnet_prefix")
+
+	if row["subnet_level"] != "0" {
+		var parent Row
+
+		// The parent is the smallest block or non terminal subnet containing the new one
+		for _, candidate := range f.tables["ip_subnet"] {
+			if candidate["site_id"] == row["site_id"] && candidate["is_terminal"] == "0" && fakeContains(candidate, start, end) {
+				if parent == nil || fakeInt(candidate["subnet_size"]) < fakeInt(parent["subnet_size"]) {
+					parent = candidate
+				}
+			}
+		}
+
+		if parent == nil {
+			return fakeFailure(http.StatusBadRequest, 2006, "No IP block found for %s/%d in space %s", fakeFormatIP(start), prefix, site["site_name"])
+		}
+
+		level, _ := strconv.Atoi(parent["subnet_level"])
+		row["parent_subnet_id"], row["parent_subnet_name"] = parent["subnet_id"], parent["subnet_name"]
+		row["subnet_level"] = strconv.Itoa(level + 1)
+	}
+
+	for _, other := range f.tables["ip_subnet"] {
+		if other["site_id"] == row["site_id"] && other["parent_subnet_id"] == row["parent_subnet_id"] && fakeOverlaps(other, start, end) {
+			return fakeFailure(http.StatusBadRequest, 2007, "IP subnet %s/%d overlaps %s", fakeFormatIP(start), prefix, other["subnet_name"])
+		}
+	}
+
+	return nil
+}
+
+// Remove the subnets and addresses of a deleted subnet
+func (f *Server) cascadesubnet(row Row) {
+	children := f.delete("ip_subnet", func(r Row) bool {
+		childStart, childEnd := fakeRange(r)
+		return r["site_id"] == row["site_id"] && fakeContains(row, childStart, childEnd) && fakeInt(r["subnet_level"]) > fakeInt(row["subnet_level"])
+	})
+
+	for _, child := range append(children, row) {
+		f.delete("ip_address", func(r Row) bool { return r["subnet_id"] == child["subnet_id"] })
+		f.delete("ip_pool", func(r Row) bool { return r["subnet_id"] == child["subnet_id"] })
+	}
+}
+
+// Validate a pool, it must fit in its terminal subnet without overlapping another pool
+func (f *Server) checkpool(row Row, create bool) *Answer {
+	if row["pool_name"] == "" {
+		return fakeFailure(http.StatusBadRequest, 2003, "IP pool name is required")
+	}
+
+	if !create {
+		return nil
+	}
+
+	subnet := f.Get("ip_subnet", "subnet_id", row["subnet_id"])
+
+	if subnet == nil || subnet["site_id"] != row["site_id"] || subnet["is_terminal"] != "1" {
+		return fakeFailure(http.StatusBadRequest, 2012, "IP subnet (oid: %s) does not exist", row["subnet_id"])
+	}
+
+	start, valid := fakeParseIP(row["start_addr"])
+	size := fakeInt(row["pool_size"])
+
+	if !valid || size < 1 || !fakeContains(subnet, start, start+uint32(size)-1) {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP pool range: %s (%s addresses)", row["start_addr"], row["pool_size"])
+	}
+
+	end := start + uint32(size) - 1
+
+	for _, other := range f.tables["ip_pool"] {
+		if other["subnet_id"] == row["subnet_id"] && fakeOverlaps(other, start, end) {
+			return fakeFailure(http.StatusBadRequest, 2004, "IP pool %s overlaps %s", row["pool_name"], other["pool_name"])
+		}
+	}
+
+	if f.exist("ip_pool", row, "pool_id", "subnet_id", "pool_name") {
+		return fakeFailure(http.StatusBadRequest, 2004, "IP pool %s already exists", row["pool_name"])
+	}
+
+	delete(row, "start_addr")
+	row["site_name"], row["subnet_name"] = subnet["site_name"], subnet["subnet_name"]
+	row["start_ip_addr"], row["end_ip_addr"] = fmt.Sprintf("%08x", start), fmt.Sprintf("%08x", end)
+
+	return nil
+}
+
+// Validate an address and find its subnet
+func (f *Server) checkaddress(row Row, create bool) *Answer {
+	if create {
+		site := f.Get("ip_site", "site_id", row["site_id"])
+
+		if site == nil {
+			site = f.Get("ip_site", "site_name", row["site_name"])
+		}
+
+		if site == nil {
+			return fakeFailure(http.StatusBadRequest, 2012, "Space (oid: %s) does not exist", row["site_id"])
+		}
+
+		ip, valid := fakeParseIP(row["hostaddr"])
+
+		if !valid {
+			return fakeFailure(http.StatusBadRequest, 2005, "Invalid IP address: %s", row["hostaddr"])
+		}
+
+		var subnet Row
+
+		for _, candidate := range f.tables["ip_subnet"] {
+			if candidate["site_id"] == site["site_id"] && candidate["is_terminal"] == "1" && fakeContains(candidate, ip, ip) {
+				subnet = candidate
+			}
+		}
+
+		if subnet == nil {
+			return fakeFailure(http.StatusBadRequest, 2006, "No IP subnet found for %s in space %s", fakeFormatIP(ip), site["site_name"])
+		}
+
+		if start, end := fakeRange(subnet); end-start > 1 && (ip == start || ip == end) {
+			return fakeFailure(http.StatusBadRequest, 2005, "%s is the network or broadcast address of %s", fakeFormatIP(ip), subnet["subnet_name"])
+		}
+
+		row["site_id"], row["site_name"] = site["site_id"], site["site_name"]
+		row["subnet_id"], row["subnet_name"] = subnet["subnet_id"], subnet["subnet_name"]
+		row["ip_addr"], row["hostaddr"] = fmt.Sprintf("%08x", ip), fakeFormatIP(ip)
+
+		if f.exist("ip_address", row, "ip_id", "site_id", "ip_addr") {
+			return fakeFailure(http.StatusBadRequest, 2008, "IP address %s is already used", fakeFormatIP(ip))
+		}
+	}
+
+	row["hostdev_name"] = ""
+
+	if row["hostdev_id"] == "" {
+		row["hostdev_id"] = "0"
+	}
+
+	if row["hostdev_id"] != "0" {
+		device := f.Get("hostdev", "hostdev_id", row["hostdev_id"])
+
+		if device == nil {
+			return fakeFailure(http.StatusBadRequest, 2012, "Device (oid: %s) does not exist", row["hostdev_id"])
+		}
+
+		row["hostdev_name"] = device["hostdev_name"]
+	}
+
+	return nil
+}
+
+// Delete an address from its oid, or from its space and address
+func (f *Server) deleteaddress(method string, p url.Values) Answer {
+	var match func(row Row) bool
+
+	if oid := p.Get("ip_id"); oid != "" {
+		match = func(row Row) bool { return row["ip_id"] == oid }
+	} else {
+		match = func(row Row) bool {
+			return (row["site_id"] == p.Get("site_id") || strings.EqualFold(row["site_name"], p.Get("site_name"))) && row["hostaddr"] == p.Get("hostaddr")
+		}
+	}
+
+	deleted := f.delete("ip_address", match)
+
+	if len(deleted) == 0 {
+		return NotFound("IP address", p.Get("ip_id")+p.Get("hostaddr"))
+	}
+
+	return Answer{http.StatusOK, []Row{{"ret_oid": deleted[0]["ip_id"], "ret_msg": "IP address deleted"}}}
+}
+
+// Suggest the first free addresses of a subnet or of one of its pools, from begin_addr if given
+// Network and broadcast addresses are excluded
+func (f *Server) findfreeaddress(method string, p url.Values) Answer {
+	subnet := f.Get("ip_subnet", "subnet_id", p.Get("subnet_id"))
+
+	if subnet == nil || subnet["is_terminal"] != "1" {
+		return NotFound("IP subnet", p.Get("subnet_id"))
+	}
+
+	max, maxErr := strconv.Atoi(p.Get("max_find"))
+
+	if maxErr != nil || max <= 0 {
+		max = 1
+	}
+
+	used := map[string]bool{}
+
+	for _, row := range f.tables["ip_address"] {
+		if row["subnet_id"] == subnet["subnet_id"] {
+			used[row["ip_addr"]] = true
+		}
+	}
+
+	start, end := fakeRange(subnet)
+
+	if end-start > 1 {
+		start, end = start+1, end-1
+	}
+
+	// Addresses are only found within the given pool, never in read-only pools
+	if oid := p.Get("pool_id"); oid != "" {
+		pool := f.Get("ip_pool", "pool_id", oid)
+
+		if pool == nil || pool["subnet_id"] != subnet["subnet_id"] {
+			return NotFound("IP pool", oid)
+		}
+
+		start, end = fakeRange(pool)
+	}
+
+	if begin, valid := fakeParseIP(p.Get("begin_addr")); valid && begin > start {
+		start = begin
+	}
+
+	for _, pool := range f.tables["ip_pool"] {
+		if pool["subnet_id"] == subnet["subnet_id"] && pool["pool_read_only"] == "1" {
+			poolStart, poolEnd := fakeRange(pool)
+
+			for ip := uint64(poolStart); ip <= uint64(poolEnd); ip++ {
+				used[fmt.Sprintf("%08x", ip)] = true
+			}
+		}
+	}
+
+	rows := []Row{}
+
+	for ip := uint64(start); ip <= uint64(end) && len(rows) < max; ip++ {
+		if hexip := fmt.Sprintf("%08x", ip); !used[hexip] {
+			rows = append(rows, Row{"hostaddr": fakeFormatIP(uint32(ip)), "ip_addr": hexip, "site_id": subnet["site_id"], "subnet_id": subnet["subnet_id"]})
+		}
+	}
+
+	if len(rows) == 0 {
+		return Answer{status: http.StatusNoContent}
+	}
+
+	return Answer{http.StatusOK, rows}
+}
+
+// Suggest the first free subnets of a block, starting at begin_addr if given
+func (f *Server) findfreesubnet(method string, p url.Values) Answer {
+	block := f.Get("ip_subnet", "subnet_id", p.Get("block_id"))
+
+	if block == nil || block["site_id"] != p.Get("site_id") {
+		return NotFound("IP block", p.Get("block_id"))
+	}
+
+	prefix, prefixErr := strconv.Atoi(p.Get("prefix"))
+
+	if prefixErr != nil || prefix < 1 || prefix > 32 {
+		return Error(http.StatusBadRequest, 2005, "Invalid prefix: %s", p.Get("prefix"))
+	}
+
+	max, maxErr := strconv.Atoi(p.Get("max_find"))
+
+	if maxErr != nil || max <= 0 {
+		max = 1
+	}
+
+	size := uint64(1) << uint(32-prefix)
+	start, end := fakeRange(block)
+	candidate := uint64(start)
+
+	if begin, valid := fakeParseIP(p.Get("begin_addr")); valid && begin > start {
+		candidate = (uint64(begin) + size - 1) / size * size
+	}
+
+	rows := []Row{}
+
+	for ; candidate+size-1 <= uint64(end) && len(rows) < max; candidate += size {
+		free := true
+
+		for _, other := range f.tables["ip_subnet"] {
+			if other["parent_subnet_id"] == block["subnet_id"] && fakeOverlaps(other, uint32(candidate), uint32(candidate+size-1)) {
+				free = false
+			}
+		}
+
+		if free {
+			rows = append(rows, Row{"start_ip_addr": fmt.Sprintf("%08x", candidate), "end_ip_addr": fmt.Sprintf("%08x", candidate+size-1),
+				"site_id": block["site_id"], "block_id": block["subnet_id"]})
+		}
+	}
+
+	if len(rows) == 0 {
+		return Answer{status: http.StatusNoContent}
+	}
+
+	return Answer{http.StatusOK, rows}
+}
+
+// Validate a RR and attach it to the zone serving its name
+func (f *Server) checkrr(row Row, create bool) *Answer {
+	row["rr_full_name"] = strings.TrimSuffix(strings.ToLower(row["rr_full_name"]), ".")
+	row["rr_type"] = strings.ToUpper(row["rr_type"])
+
+	if row["dnsview_name"] == "" {
+		row["dnsview_name"] = "#"
+	}
+
+	var zone Row
+
+	for _, candidate := range f.tables["dns_zone"] {
+		name := strings.ToLower(candidate["dnszone_name"])
+
+		if strings.EqualFold(candidate["dns_name"], row["dns_name"]) && candidate["dnsview_name"] == row["dnsview_name"] &&
+			(row["rr_full_name"] == name || strings.HasSuffix(row["rr_full_name"], "."+name)) {
+			if zone == nil || len(name) > len(zone["dnszone_name"]) {
+				zone = candidate
+			}
+		}
+	}
+
+	if zone == nil {
+		return fakeFailure(http.StatusBadRequest, 2006, "No DNS zone found for %s on %s", row["rr_full_name"], row["dns_name"])
+	}
+
+	switch row["rr_type"] {
+	case "A":
+		if ip := net.ParseIP(row["value1"]); ip == nil || ip.To4() == nil {
+			return fakeFailure(http.StatusBadRequest, 2005, "Invalid A record value: %s", row["value1"])
+		}
+	case "AAAA":
+		if ip := net.ParseIP(row["value1"]); ip == nil || ip.To4() != nil {
+			return fakeFailure(http.StatusBadRequest, 2005, "Invalid AAAA record value: %s", row["value1"])
+		}
+	case "CNAME", "TXT", "PTR", "NS", "MX":
+	default:
+		return fakeFailure(http.StatusBadRequest, 2005, "Unsupported RR type: %s", row["rr_type"])
+	}
+
+	if _, ttlErr := strconv.Atoi(row["ttl"]); ttlErr != nil {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid TTL: %s", row["ttl"])
+	}
+
+	row["dnszone_id"], row["dnszone_name"] = zone["dnszone_id"], zone["dnszone_name"]
+
+	if f.exist("dns_rr", row, "rr_id", "dns_name", "dnsview_name", "rr_full_name", "rr_type", "value1") {
+		return fakeFailure(http.StatusBadRequest, 2004, "RR %s %s %s already exists", row["rr_full_name"], row["rr_type"], row["value1"])
+	}
+
+	return nil
+}
+
+// Validate a vlan and attach it to its domain
+func (f *Server) checkvlan(row Row, create bool) *Answer {
+	domain := f.Get("vlmdomain", "vlmdomain_name", row["vlmdomain_name"])
+
+	if domain == nil {
+		return fakeFailure(http.StatusBadRequest, 2012, "VLAN domain %s does not exist", row["vlmdomain_name"])
+	}
+
+	vlanID, vlanErr := strconv.Atoi(row["vlmvlan_vlan_id"])
+	first, _ := strconv.Atoi(domain["vlmdomain_start_vlan_id"])
+	last, _ := strconv.Atoi(domain["vlmdomain_end_vlan_id"])
+
+	if vlanErr != nil || vlanID < first || vlanID > last {
+		return fakeFailure(http.StatusBadRequest, 2005, "Invalid VLAN ID %s for domain %s", row["vlmvlan_vlan_id"], domain["vlmdomain_name"])
+	}
+
+	row["vlmdomain_id"], row["vlmdomain_name"] = domain["vlmdomain_id"], domain["vlmdomain_name"]
+	row["vlmvlan_vlan_id"] = strconv.Itoa(vlanID)
+
+	if f.exist("vlmvlan", row, "vlmvlan_id", "vlmdomain_id", "vlmvlan_vlan_id") {
+		return fakeFailure(http.StatusBadRequest, 2008, "VLAN ID %d is already used in domain %s", vlanID, domain["vlmdomain_name"])
+	}
+
+	return nil
+}
+
+// List the vlans and the free vlan ranges of every domain
+// Free ranges are rows of type free, as returned by SOLIDserver 7 and later
+func (f *Server) listvlans(method string, p url.Values) Answer {
+	rows := []Row{}
+
+	for _, domain := range f.tables["vlmdomain"] {
+		used := []int{}
+
+		for _, vlan := range f.tables["vlmvlan"] {
+			if vlan["vlmdomain_id"] == domain["vlmdomain_id"] {
+				vlanID, _ := strconv.Atoi(vlan["vlmvlan_vlan_id"])
+				used = append(used, vlanID)
+				rows = append(rows, vlan)
+			}
+		}
+
+		sort.Ints(used)
+
+		first, _ := strconv.Atoi(domain["vlmdomain_start_vlan_id"])
+		last, _ := strconv.Atoi(domain["vlmdomain_end_vlan_id"])
+
+		for _, vlanID := range append(used, last+1) {
+			if vlanID > first {
+				rows = append(rows, Row{"vlmdomain_id": domain["vlmdomain_id"], "vlmdomain_name": domain["vlmdomain_name"], "type": "free", "row_enabled": "2",
+					"vlmvlan_vlan_id": strconv.Itoa(first), "free_start_vlan_id": strconv.Itoa(first), "free_end_vlan_id": strconv.Itoa(vlanID - 1)})
+			}
+			first = vlanID + 1
+		}
+	}
+
+	return f.list(rows, p)
+}
+
+func (f *Server) addgroupuser(method string, p url.Values) Answer {
+	group := f.Get("group", "grp_id", p.Get("grp_id"))
+	user := f.Get("user", "usr_id", p.Get("usr_id"))
+
+	if group == nil || user == nil {
+		return Error(http.StatusBadRequest, 2012, "Group (oid: %s) or user (oid: %s) does not exist", p.Get("grp_id"), p.Get("usr_id"))
+	}
+
+	for _, member := range f.tables["group_user"] {
+		if member["grp_id"] == group["grp_id"] && member["usr_id"] == user["usr_id"] {
+			return Error(http.StatusBadRequest, 2004, "User %s is already a member of group %s", user["usr_login"], group["grp_name"])
+		}
+	}
+
+	f.oid++
+	f.tables["group_user"] = append(f.tables["group_user"], Row{"grp_usr_id": strconv.Itoa(f.oid), "grp_id": group["grp_id"], "usr_id": user["usr_id"]})
+
+	return Answer{http.StatusCreated, []Row{{"errno": "0", "ret_oid": strconv.Itoa(f.oid)}}}
+}
+
+func (f *Server) deletegroupuser(method string, p url.Values) Answer {
+	user := f.Get("user", "usr_login", p.Get("usr_login"))
+
+	if user == nil {
+		user = f.Get("user", "usr_id", p.Get("usr_id"))
+	}
+
+	if user == nil {
+		return NotFound("User", p.Get("usr_login"))
+	}
+
+	deleted := f.delete("group_user", func(r Row) bool { return r["grp_id"] == p.Get("grp_id") && r["usr_id"] == user["usr_id"] })
+
+	if len(deleted) == 0 {
+		return Error(http.StatusBadRequest, 2012, "User %s is not a member of group (oid: %s)", user["usr_login"], p.Get("grp_id"))
+	}
+
+	return Answer{status: http.StatusNoContent}
+}
+
+func (f *Server) listusergroups(method string, p url.Values) Answer {
+	rows := []Row{}
+
+	for _, member := range f.tables["group_user"] {
+		if member["usr_id"] == p.Get("usr_id") {
+			if group := f.Get("group", "grp_id", member["grp_id"]); group != nil {
+				rows = append(rows, Row{"grp_id": group["grp_id"], "grp_name": group["grp_name"], "usr_id": member["usr_id"]})
+			}
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i]["grp_name"] < rows[j]["grp_name"] })
+
+	return f.list(rows, p)
+}
+
+// A WHERE clause condition, as built by solidserver-where.go
+var fakeWhereCondition = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)(=|!=|<=|>=|<|>| LIKE )'((?:[^']|'')*)'`)
+var fakeWhereIn = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*) IN \(((?:'(?:[^']|'')*',?)+)\)`)
+
+// Parse a WHERE clause into a row filter
+// Only the syntax produced by the Where builder is accepted, anything else is reported to the test
+func fakeParseWhere(clause string) (func(Row) bool, error) {
+	if clause == "" {
+		return func(Row) bool { return true }, nil
+	}
+
+	match, rest, err := fakeParseWhereExpr(clause)
+
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected %q in %q", rest, clause)
+	}
+
+	return match, err
+}
+
+func fakeParseWhereExpr(clause string) (func(Row) bool, string, error) {
+	switch {
+	case strings.HasPrefix(clause, "1=0"):
+		return func(Row) bool { return false }, clause[3:], nil
+
+	case strings.HasPrefix(clause, "("):
+		conditions := []func(Row) bool{}
+		operator := ""
+		rest := clause[1:]
+
+		for {
+			condition, next, err := fakeParseWhereExpr(rest)
+
+			if err != nil {
+				return nil, "", err
+			}
+
+			conditions = append(conditions, condition)
+
+			switch {
+			case strings.HasPrefix(next, ")"):
+				return fakeWhereJoin(operator, conditions), next[1:], nil
+			case strings.HasPrefix(next, " AND ") && operator != "OR":
+				operator, rest = "AND", next[5:]
+			case strings.HasPrefix(next, " OR ") && operator != "AND":
+				operator, rest = "OR", next[4:]
+			default:
+				return nil, "", fmt.Errorf("unexpected %q in WHERE clause", next)
+			}
+		}
+
+	case fakeWhereIn.MatchString(clause):
+		m := fakeWhereIn.FindStringSubmatch(clause)
+		values := []string{}
+
+		for _, value := range regexp.MustCompile(`'((?:[^']|'')*)'`).FindAllStringSubmatch(m[2], -1) {
+			values = append(values, strings.Replace(value[1], "''", "'", -1))
+		}
+
+		return func(row Row) bool {
+			for _, value := range values {
+				if strings.EqualFold(row[m[1]], value) {
+					return true
+				}
+			}
+			return false
+		}, clause[len(m[0]):], nil
+
+	case fakeWhereCondition.MatchString(clause):
+		m := fakeWhereCondition.FindStringSubmatch(clause)
+		column, operator, value := m[1], m[2], strings.Replace(m[3], "''", "'", -1)
+
+		return func(row Row) bool { return fakeCompare(row[column], operator, value) }, clause[len(m[0]):], nil
+	}
+
+	return nil, "", fmt.Errorf("invalid WHERE clause %q", clause)
+}
+
+func fakeWhereJoin(operator string, conditions []func(Row) bool) func(Row) bool {
+	return func(row Row) bool {
+		for _, condition := range conditions {
+			if condition(row) != (operator != "OR") {
+				return operator == "OR"
+			}
+		}
+		return operator != "OR"
+	}
+}
+
+func fakeCompare(column string, operator string, value string) bool {
+	if operator == " LIKE " {
+		pattern := ""
+		escaped := false
+
+		for _, r := range value {
+			switch {
+			case escaped:
+				pattern += regexp.QuoteMeta(string(r))
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '%':
+				pattern += ".*"
+			case r == '_':
+				pattern += "."
+			default:
+				pattern += regexp.QuoteMeta(string(r))
+			}
+		}
+
+		return regexp.MustCompile("(?i)^" + pattern + "$").MatchString(column)
+	}
+
+	order := strings.Compare(strings.ToLower(column), strings.ToLower(value))
+
+	// Numbers are compared as numbers
+	if a, aErr := strconv.ParseFloat(column, 64); aErr == nil {
+		if b, bErr := strconv.ParseFloat(value, 64); bErr == nil {
+			switch {
+			case a < b:
+				order = -1
+			case a > b:
+				order = 1
+			default:
+				order = 0
+			}
+		}
+	}
+
+	switch operator {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	}
+
+	return order >= 0
+}
@@ -0,0 +1,168 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+)
+
+const (
+	// Credentials are sent as X-IPM-Username/X-IPM-Password headers on every call
+	AuthModeHeaders = "headers"
+	// Credentials are sent once, the session cookie is reused for the following calls
+	AuthModeSession = "session"
+
+	// Service opening a session on the SOLIDserver
+	sessionLoginService = "rest/login"
+	// Service closing the session on the SOLIDserver
+	sessionLogoutService = "rest/logout"
+)
+
+// Cookie jar holding the session opened on the SOLIDserver
+// It can be cleared at any time to force a new login
+type sessionJar struct {
+	mutex sync.RWMutex
+	jar   *cookiejar.Jar
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil)
+
+	return &sessionJar{jar: jar}
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	return j.jar.Cookies(u)
+}
+
+// Forget the session cookies
+func (j *sessionJar) Clear() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.jar, _ = cookiejar.New(nil)
+}
+
+// Configured SOLIDserver clients, closed when the provider shuts down
+var clients = struct {
+	sync.Mutex
+	servers []*SOLIDserver
+}{}
+
+// Check if a session is currently open on the SOLIDserver
+func (s *SOLIDserver) hasSession() bool {
+	if s.session == nil {
+		return false
+	}
+
+	u, err := url.Parse(s.baseURL())
+
+	return err == nil && len(s.session.Cookies(u)) > 0
+}
+
+// Login opens a session on the SOLIDserver, reused by the following calls
+// Bad credentials are reported here, when the provider is configured, rather than by the first resource
+// Fall back to credential headers if the SOLIDserver did not open any session
+func (s *SOLIDserver) Login(ctx context.Context) error {
+	parameters := url.Values{}
+
+	if err := s.limiter.Acquire(ctx); err != nil {
+		return err
+	}
+
+	resp, _, err := s.do(ctx, http.MethodPost, s.baseURL(), sessionLoginService, &parameters)
+	s.limiter.Release()
+
+	if err != nil {
+		return connerror(s.currentHost(), err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("SOLIDServer - Authentication failed on %s for user %s (HTTP 401), check the username and password", s.currentHost(), s.Username)
+	case http.StatusForbidden:
+		return fmt.Errorf("SOLIDServer - Access denied on %s for user %s (HTTP 403), check the user's API permissions", s.currentHost(), s.Username)
+	}
+
+	if resp.StatusCode >= 300 || !s.hasSession() {
+		log.Printf("[WARN] SOLIDServer - No session opened by %s (HTTP %d), falling back to credential headers\n", s.currentHost(), resp.StatusCode)
+		s.AuthMode = AuthModeHeaders
+		s.session = nil
+		s.client.Jar = nil
+		return nil
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Session opened on %s\n", s.currentHost())
+
+	return nil
+}
+
+// Forget the current session, the next call logs in again
+// Return false if no session was open
+func (s *SOLIDserver) expireSession() bool {
+	if !s.hasSession() {
+		return false
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Session expired on %s, logging in again\n", s.currentHost())
+	s.session.Clear()
+
+	return true
+}
+
+// Close the session opened on the SOLIDserver, if any
+func (s *SOLIDserver) Logout() {
+	if !s.hasSession() {
+		return
+	}
+
+	parameters := url.Values{}
+
+	// The session is closed even when terraform is interrupted
+	s.limiter.Acquire(context.Background())
+	resp, _, err := s.do(context.Background(), http.MethodPost, s.baseURL(), sessionLogoutService, &parameters)
+	s.limiter.Release()
+
+	if err != nil || resp.StatusCode >= 300 {
+		log.Printf("[DEBUG] SOLIDServer - Unable to close the session on %s\n", s.currentHost())
+	} else {
+		log.Printf("[DEBUG] SOLIDServer - Session closed on %s\n", s.currentHost())
+	}
+
+	s.session.Clear()
+}
+
+// Close closes the session and the audit log of the SOLIDserver client
+func (s *SOLIDserver) Close() {
+	s.Logout()
+
+	if err := s.audit.Close(); err != nil {
+		log.Printf("[WARN] SOLIDServer - Unable to close the audit log: %s\n", err)
+	}
+}
+
+// Shutdown closes every SOLIDserver client created so far
+func Shutdown() {
+	clients.Lock()
+	defer clients.Unlock()
+
+	for _, s := range clients.servers {
+		s.Close()
+	}
+
+	clients.servers = nil
+}
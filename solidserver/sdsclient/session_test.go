@@ -0,0 +1,106 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSession_LoginOnceAndRefresh(t *testing.T) {
+	logins := 0
+	logouts := 0
+	session := ""
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+sessionLogoutService {
+			logouts++
+			return
+		}
+
+		if r.Header.Get("X-IPM-Username") != "" {
+			logins++
+			session = fmt.Sprintf("session-%d", logins)
+			http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: session, Path: "/"})
+		} else if cookie, err := r.Cookie("PHPSESSID"); err != nil || cookie.Value != session {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	s, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "admin", false, "", SOLIDserverOptions{AuthMode: AuthModeSession})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if resp, _, err := s.Request(context.Background(), "get", "rest/ip_site_list", &url.Values{}); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected answer: %v", err)
+		}
+	}
+
+	if logins != 1 {
+		t.Errorf("expected a single login, got %d", logins)
+	}
+
+	// Simulate a session expired on the SOLIDserver
+	session = "expired"
+
+	if resp, _, err := s.Request(context.Background(), "get", "rest/ip_site_list", &url.Values{}); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the session to be refreshed: %v", err)
+	}
+
+	if logins != 2 {
+		t.Errorf("expected a second login after the session expired, got %d", logins)
+	}
+
+	Shutdown()
+
+	if logouts != 1 || s.hasSession() {
+		t.Errorf("expected the session to be closed on shutdown, got %d logouts", logouts)
+	}
+}
+
+func TestSession_LoginFailsOnBadCredentials(t *testing.T) {
+	services := []string{}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		services = append(services, strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "wrong", false, "", SOLIDserverOptions{AuthMode: AuthModeSession})
+
+	if err == nil || !strings.Contains(err.Error(), "Authentication failed") {
+		t.Fatalf("expected the login to fail on bad credentials, got %v", err)
+	}
+
+	if len(services) != 1 || services[0] != sessionLoginService {
+		t.Errorf("expected a single call to %s, got %v", sessionLoginService, services)
+	}
+}
+
+func TestSession_FallbackToHeaders(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	s, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "admin", false, "", SOLIDserverOptions{AuthMode: AuthModeSession})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.AuthMode != AuthModeHeaders {
+		t.Errorf("expected a fallback to credential headers, got %s", s.AuthMode)
+	}
+}
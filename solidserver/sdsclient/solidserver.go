@@ -0,0 +1,564 @@
+package sdsclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrReadOnly is returned for write calls when the provider is in read-only mode
+var ErrReadOnly = errors.New("the provider is in read-only mode")
+
+const (
+	// Default size of the idle connection pool kept open to the SOLIDserver
+	DefaultMaxIdleConns = 16
+	// Default maximum number of connections opened to the SOLIDserver
+	DefaultMaxConnsPerHost = 16
+)
+
+// SOLIDserver is a client of the API of a SOLIDserver, or of the nodes of a cluster
+type SOLIDserver struct {
+	Host                     string
+	Hosts                    []string
+	Username                 string
+	Password                 string
+	BaseUrl                  string
+	Scheme                   string
+	Port                     int
+	PathPrefix               string
+	ProxyURL                 string
+	SSLVerify                bool
+	AdditionalTrustCertsFile string
+	MaxIdleConns             int
+	MaxConnsPerHost          int
+	Retry                    RetryPolicy
+	ReadOnly                 bool
+	ListPageSize             int
+	ListMaxResults           int
+	AuthMode                 string
+	ClientCertFile           string
+	ClientKeyFile            string
+	ClientCertPEM            string
+	ClientKeyPEM             string
+	Version                  Version
+	client                   *http.Client
+	limiter                  *Limiter
+	session                  *sessionJar
+	cache                    *lookupCache
+	credentials              CredentialSource
+	credentialsMutex         sync.RWMutex
+	audit                    *AuditLog
+	current                  int
+	endpointMutex            sync.RWMutex
+	failoverMutex            sync.Mutex
+	stopContext              context.Context
+}
+
+// SOLIDserverOptions holds the optional settings of the API client
+// Zero values select the defaults
+type SOLIDserverOptions struct {
+	BaseURL         string
+	Port            int
+	PathPrefix      string
+	ProxyURL        string
+	MaxIdleConns    int
+	MaxConnsPerHost int
+	Retry           RetryPolicy
+	ReadOnly        bool
+	ListPageSize    int
+	ListMaxResults  int
+	AuditLog        *AuditLog
+	Credentials     CredentialSource
+	Limiter         *Limiter
+	AuthMode        string
+	ClientCertFile  string
+	ClientKeyFile   string
+	ClientCertPEM   string
+	ClientKeyPEM    string
+	StopContext     context.Context
+}
+
+// NewSOLIDserver returns a client of the SOLIDserver at host, authenticated with the given credentials
+func NewSOLIDserver(host string, username string, password string, sslverify bool, certsfile string, options SOLIDserverOptions) (*SOLIDserver, error) {
+	scheme := "https"
+	prefix := options.PathPrefix
+
+	// A full base URL replaces the host, port and path prefix
+	if options.BaseURL != "" {
+		var err error
+
+		if scheme, host, prefix, err = ParseBaseURL(options.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts := SplitHosts(host)
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("SOLIDServer - No SOLIDserver host configured")
+	}
+
+	s := &SOLIDserver{
+		Host:                     host,
+		Hosts:                    hosts,
+		Username:                 username,
+		Password:                 password,
+		Scheme:                   scheme,
+		Port:                     options.Port,
+		PathPrefix:               prefix,
+		ProxyURL:                 options.ProxyURL,
+		SSLVerify:                sslverify,
+		AdditionalTrustCertsFile: certsfile,
+		MaxIdleConns:             options.MaxIdleConns,
+		MaxConnsPerHost:          options.MaxConnsPerHost,
+		Retry:                    options.Retry,
+		ReadOnly:                 options.ReadOnly,
+		ListPageSize:             options.ListPageSize,
+		ListMaxResults:           options.ListMaxResults,
+		AuthMode:                 options.AuthMode,
+		ClientCertFile:           options.ClientCertFile,
+		ClientKeyFile:            options.ClientKeyFile,
+		ClientCertPEM:            options.ClientCertPEM,
+		ClientKeyPEM:             options.ClientKeyPEM,
+		limiter:                  options.Limiter,
+		cache:                    newLookupCache(),
+		audit:                    options.AuditLog,
+		credentials:              options.Credentials,
+		stopContext:              options.StopContext,
+	}
+
+	s.BaseUrl = s.hostURL(hosts[0])
+
+	if s.limiter == nil {
+		s.limiter = NewLimiter(0, 0)
+	}
+
+	if s.stopContext == nil {
+		s.stopContext = context.Background()
+	}
+
+	if s.MaxIdleConns <= 0 {
+		s.MaxIdleConns = DefaultMaxIdleConns
+	}
+
+	if s.MaxConnsPerHost <= 0 {
+		s.MaxConnsPerHost = DefaultMaxConnsPerHost
+	}
+
+	if s.Retry.Timeout <= 0 {
+		s.Retry = DefaultRetryPolicy()
+	}
+
+	if s.ListPageSize <= 0 {
+		s.ListPageSize = DefaultListPageSize
+	}
+
+	if s.AuthMode == "" {
+		s.AuthMode = AuthModeHeaders
+	}
+
+	if err := s.loadCredentials(); err != nil {
+		return nil, fmt.Errorf("SOLIDServer - Unable to retrieve the credentials: %s", err)
+	}
+
+	if err := s.initClient(); err != nil {
+		return nil, fmt.Errorf("SOLIDServer - Unable to initialize the API client: %s", err)
+	}
+
+	// Start on the first healthy master when several endpoints are configured
+	if len(s.Hosts) > 1 {
+		s.selectEndpoint(s.stopContext)
+	}
+
+	// Log in first, bad credentials must fail the provider configuration
+	if s.AuthMode == AuthModeSession {
+		if err := s.Login(s.stopContext); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.GetVersion(s.stopContext); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	clients.Lock()
+	clients.servers = append(clients.servers, s)
+	clients.Unlock()
+
+	return s, nil
+}
+
+// Build the TLS configuration used for every API call
+// Certificates are loaded once, when the client is initialized
+func (s *SOLIDserver) tlsConfig() (*tls.Config, error) {
+	// Get the SystemCertPool, continue with an empty pool on error
+	rootCAs, x509err := x509.SystemCertPool()
+
+	if rootCAs == nil || x509err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if s.AdditionalTrustCertsFile != "" {
+		certs, readErr := ioutil.ReadFile(s.AdditionalTrustCertsFile)
+		log.Printf("[DEBUG] Certificates = %s\n", certs)
+
+		if readErr != nil {
+			return nil, fmt.Errorf("Failed to append %q to RootCAs: %v", s.AdditionalTrustCertsFile, readErr)
+		}
+
+		log.Printf("[DEBUG] Cert Subjects Before Append = %d\n", len(rootCAs.Subjects()))
+
+		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
+			log.Printf("No certs appended, using system certs only\n")
+		}
+		log.Printf("[DEBUG] Cert Subjects After Append = %d\n", len(rootCAs.Subjects()))
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: !s.SSLVerify, RootCAs: rootCAs}
+
+	// Authenticate the provider with a client certificate if configured
+	clientCert, certErr := s.clientCertificate()
+
+	if certErr != nil {
+		return nil, certErr
+	}
+
+	if clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Load the client certificate used for mutual TLS authentication
+// Return nil if no client certificate is configured
+func (s *SOLIDserver) clientCertificate() (*tls.Certificate, error) {
+	certPEM := []byte(s.ClientCertPEM)
+	keyPEM := []byte(s.ClientKeyPEM)
+
+	if s.ClientCertFile != "" {
+		var readErr error
+
+		if certPEM, readErr = ioutil.ReadFile(s.ClientCertFile); readErr != nil {
+			return nil, fmt.Errorf("Failed to read client certificate %q: %v", s.ClientCertFile, readErr)
+		}
+	}
+
+	if s.ClientKeyFile != "" {
+		var readErr error
+
+		if keyPEM, readErr = ioutil.ReadFile(s.ClientKeyFile); readErr != nil {
+			return nil, fmt.Errorf("Failed to read client key %q: %v", s.ClientKeyFile, readErr)
+		}
+	}
+
+	if len(certPEM) == 0 && len(keyPEM) == 0 {
+		return nil, nil
+	}
+
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, fmt.Errorf("Both a client certificate and its private key are required for mutual TLS authentication")
+	}
+
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the client certificate: %v", err)
+	}
+
+	return &clientCert, nil
+}
+
+// Build the long-lived HTTP client shared by every API call
+// Connections are kept alive and pooled between calls
+func (s *SOLIDserver) initClient() error {
+	tlsConfig, err := s.tlsConfig()
+
+	if err != nil {
+		return err
+	}
+
+	proxy, err := s.proxy()
+
+	if err != nil {
+		return err
+	}
+
+	transport := &http.Transport{
+		Proxy:               proxy,
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        s.MaxIdleConns,
+		MaxIdleConnsPerHost: s.MaxIdleConns,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	setmaxconnsperhost(transport, s.MaxConnsPerHost)
+
+	s.client = &http.Client{
+		Transport: transport,
+		Timeout:   s.Retry.Timeout,
+	}
+
+	if s.AuthMode == AuthModeSession {
+		s.session = newSessionJar()
+		s.client.Jar = s.session
+	}
+
+	return nil
+}
+
+// StopContext returns the context cancelled once the client must stop sending requests
+func (s *SOLIDserver) StopContext() context.Context {
+	return s.stopContext
+}
+
+// GetVersion reads the version of the SOLIDserver
+func (s *SOLIDserver) GetVersion(ctx context.Context) error {
+	parameters := url.Values{}
+	where, whereErr := WhereEq("member_is_me", "1").Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	resp, body, err := s.Request(ctx, "get", "rest/member_list", &parameters)
+
+	if err != nil {
+		return connerror(s.currentHost(), err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return fmt.Errorf("SOLIDServer - Authentication failed on %s for user %s (HTTP 401), check the username and password", s.currentHost(), s.Username)
+	case http.StatusForbidden:
+		return fmt.Errorf("SOLIDServer - Access denied on %s for user %s (HTTP 403), check the user's API permissions", s.currentHost(), s.Username)
+	default:
+		return fmt.Errorf("SOLIDServer - Unexpected answer from %s while retrieving the SOLIDserver version (HTTP %d)", s.currentHost(), resp.StatusCode)
+	}
+
+	var buf [](map[string]interface{})
+
+	if jsonErr := json.Unmarshal([]byte(body), &buf); jsonErr != nil {
+		return fmt.Errorf("SOLIDServer - Unexpected non-JSON answer from %s while retrieving the SOLIDserver version, check the host points to a SOLIDserver (%s)", s.currentHost(), jsonErr)
+	}
+
+	if len(buf) == 0 {
+		return fmt.Errorf("SOLIDServer - No local member returned by %s while retrieving the SOLIDserver version", s.currentHost())
+	}
+
+	version, versionExist := buf[0]["member_version"].(string)
+
+	if !versionExist {
+		return fmt.Errorf("SOLIDServer - Missing member_version field in the answer from %s while retrieving the SOLIDserver version", s.currentHost())
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Version: %s\n", version)
+
+	parsedVersion, versionErr := ParseVersion(version)
+
+	if versionErr != nil {
+		return fmt.Errorf("SOLIDServer - Unexpected member_version field in the answer from %s while retrieving the SOLIDserver version (%s)", s.currentHost(), versionErr)
+	}
+
+	s.Version = parsedVersion
+
+	log.Printf("[DEBUG] SOLIDServer - server version: %s\n", s.Version)
+
+	return nil
+}
+
+// Turn a connection error into a configuration error explaining what went wrong
+func connerror(host string, err error) error {
+	chain := causes(err)
+
+	// Report the transport error rather than the API call wrapping it
+	cause := err
+
+	if len(chain) > 1 {
+		cause = chain[1]
+	}
+
+	for _, e := range chain {
+		switch e.(type) {
+		case *net.DNSError:
+			return fmt.Errorf("SOLIDServer - Unable to resolve SOLIDserver host %s (%s)", host, e)
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError:
+			return fmt.Errorf("SOLIDServer - TLS verification failed for SOLIDserver host %s, check sslverify and additional_trust_certs_file (%s)", host, cause)
+		}
+	}
+
+	return fmt.Errorf("SOLIDServer - Unable to connect to SOLIDserver host %s (%s)", host, cause)
+}
+
+// Send a single HTTP request through the shared client
+// Return the response and its fully read body
+func (s *SOLIDserver) do(ctx context.Context, method string, baseurl string, service string, parameters *url.Values) (*http.Response, string, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s?%s", baseurl, service, parameters.Encode()), nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	req = req.WithContext(ctx)
+
+	// Credentials are only sent when no session is open
+	// The provider may also be authenticated by its client certificate only
+	if username, password := s.login(); !s.hasSession() && username != "" {
+		req.Header.Set("X-IPM-Username", base64.StdEncoding.EncodeToString([]byte(username)))
+		req.Header.Set("X-IPM-Password", base64.StdEncoding.EncodeToString([]byte(password)))
+	}
+
+	if method == http.MethodGet {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+
+	resp, err := s.client.Do(req)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp, string(body), nil
+}
+
+// Request sends a call to the SOLIDserver API, see RequestWithLookup
+func (s *SOLIDserver) Request(ctx context.Context, method string, service string, parameters *url.Values) (*http.Response, string, error) {
+	return s.RequestWithLookup(ctx, method, service, parameters, nil)
+}
+
+// Send an API call, retrying it according to the retry policy
+// GET calls are retried on every retryable failure. Write calls are only retried
+// when the SOLIDserver did not process them or, if a lookup function is provided,
+// once the lookup confirmed the write was not applied.
+// The call, its retries and the waits in between are abandoned once the context is done.
+func (s *SOLIDserver) RequestWithLookup(ctx context.Context, method string, service string, parameters *url.Values, applied func() (bool, error)) (*http.Response, string, error) {
+	var resp *http.Response = nil
+	var body string = ""
+	var err error = nil
+	var httpMethod string = ""
+
+	switch method {
+	case "post":
+		httpMethod = http.MethodPost
+	case "put":
+		httpMethod = http.MethodPut
+	case "delete":
+		httpMethod = http.MethodDelete
+	case "get":
+		httpMethod = http.MethodGet
+	default:
+		return nil, "", fmt.Errorf("SOLIDServer - Error initiating API call, unsupported HTTP request\n")
+	}
+
+	// Nothing may be changed on the SOLIDserver in read-only mode
+	if s.ReadOnly && httpMethod != http.MethodGet {
+		err = WrapError(ErrReadOnly, "SOLIDServer - Refusing to %s on %s", method, service)
+		s.audit.record(s.currentHost(), method, service, parameters, nil, "", err, time.Now(), 0)
+
+		return nil, "", err
+	}
+
+	start := time.Now()
+	attempts := 0
+
+	for retry := 0; ; retry++ {
+		baseurl := s.baseURL()
+
+		// Wait for the limiter before each attempt, not while backing off
+		if err = s.limiter.Acquire(ctx); err != nil {
+			resp, body = nil, ""
+			break
+		}
+
+		attempts++
+		resp, body, err = s.do(ctx, httpMethod, baseurl, service, parameters)
+
+		// Expired sessions and rotated credentials are rejected before the call is processed, log in again
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && s.reauthenticate() {
+			resp, body, err = s.do(ctx, httpMethod, baseurl, service, parameters)
+		}
+
+		s.limiter.Release()
+
+		// A cancelled call is neither retried nor a sign the endpoint is down
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+
+		// Switch to another endpoint when this one is down or no longer the master,
+		// the call is then sent again according to the retry policy
+		switched := endpointfailed(service, resp, body, err) && s.failover(ctx, baseurl)
+
+		retryable, processed := s.Retry.retryable(resp, err)
+
+		// A node which is no longer the master rejected the call without processing it,
+		// it is only sent again to the new master
+		if err == nil && endpointnotmaster(service, resp, body) {
+			retryable, processed = switched, false
+		}
+
+		if !retryable || retry >= s.Retry.MaxRetries {
+			break
+		}
+
+		// Never replay a write which may have been applied
+		if httpMethod != http.MethodGet && processed {
+			if applied == nil {
+				break
+			}
+
+			if done, lookupErr := applied(); lookupErr != nil || done {
+				log.Printf("[DEBUG] SOLIDServer - Not retrying %s on %s, the lookup could not confirm it was not applied\n", method, service)
+				break
+			}
+		}
+
+		// No need to wait before sending the call to a new endpoint
+		if switched {
+			log.Printf("[DEBUG] SOLIDServer - Retrying %s on %s on %s (%d/%d)\n", method, service, s.currentHost(), retry+1, s.Retry.MaxRetries)
+			continue
+		}
+
+		delay := s.Retry.backoff(retry)
+		log.Printf("[DEBUG] SOLIDServer - Retrying %s on %s in %s (%d/%d)\n", method, service, delay, retry+1, s.Retry.MaxRetries)
+
+		if err = sleep(ctx, delay); err != nil {
+			resp, body = nil, ""
+			break
+		}
+	}
+
+	s.audit.record(s.currentHost(), method, service, parameters, resp, body, err, start, attempts)
+
+	if err != nil {
+		return nil, "", WrapError(err, "SOLIDServer - Error initiating API call")
+	}
+
+	return resp, body, nil
+}
@@ -0,0 +1,195 @@
+package sdsclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSOLIDserverVersion(t *testing.T, handler http.HandlerFunc) (*SOLIDserver, error) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	return NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "admin", false, "", SOLIDserverOptions{})
+}
+
+func TestNewSOLIDserver_Version(t *testing.T) {
+	s, err := testSOLIDserverVersion(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1.12345", "member_is_me": "1"}]`)
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.Version != (Version{Major: 7, Minor: 0, Patch: 1, Build: 12345}) {
+		t.Errorf("expected version 7.0.1.12345, got %s", s.Version)
+	}
+}
+
+func TestNewSOLIDserver_ConfigurationErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `[{"errno": "1"}]`, "HTTP 401"},
+		{"forbidden", http.StatusForbidden, `[{"errno": "1"}]`, "HTTP 403"},
+		{"non-json", http.StatusOK, `<html>Login</html>`, "non-JSON"},
+		{"no-member", http.StatusOK, `[]`, "No local member"},
+		{"no-version", http.StatusOK, `[{"member_is_me": "1"}]`, "member_version"},
+	}
+
+	for _, c := range cases {
+		s, err := testSOLIDserverVersion(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			fmt.Fprint(w, c.body)
+		})
+
+		if s != nil || err == nil {
+			t.Errorf("%s: expected a configuration error", c.name)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected error containing %q, got %q", c.name, c.expected, err)
+		}
+	}
+}
+
+func TestNewSOLIDserver_TLSVerification(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	_, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "admin", true, "", SOLIDserverOptions{})
+
+	if err == nil || !strings.Contains(err.Error(), "TLS verification failed") {
+		t.Errorf("expected a TLS verification error, got %v", err)
+	}
+}
+
+func TestNewSOLIDserver_DNSResolution(t *testing.T) {
+	_, err := NewSOLIDserver("solidserver.invalid", "ipmadmin", "admin", true, "", SOLIDserverOptions{})
+
+	if err == nil || !strings.Contains(err.Error(), "Unable to resolve") {
+		t.Errorf("expected a DNS resolution error, got %v", err)
+	}
+}
+
+// Generate a self-signed client certificate and its key, PEM encoded
+func testClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})),
+		cert
+}
+
+func TestNewSOLIDserver_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM, cert := testClientCertificate(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-IPM-Username") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	if _, err := NewSOLIDserver(host, "", "", false, "", SOLIDserverOptions{ClientCertPEM: certPEM, ClientKeyPEM: keyPEM}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if _, err := NewSOLIDserver(host, "", "", false, "", SOLIDserverOptions{ClientCertPEM: certPEM}); err == nil {
+		t.Error("expected an error for a client certificate without key")
+	}
+}
+
+func TestRequest_ReadOnly(t *testing.T) {
+	writes := 0
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes++
+		}
+
+		switch r.URL.Path {
+		case "/rpc/ip_find_free_address":
+			fmt.Fprint(w, `[{"hostaddr": "10.0.0.1"}]`)
+		default:
+			fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewSOLIDserver(strings.TrimPrefix(server.URL, "https://"), "ipmadmin", "admin", false, "", SOLIDserverOptions{ReadOnly: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, method := range []string{"post", "put", "delete"} {
+		parameters := url.Values{}
+
+		if _, _, err := s.Request(context.Background(), method, "rest/ip_add", &parameters); !iserror(err, ErrReadOnly) {
+			t.Errorf("%s: expected a read-only error, got %v", method, err)
+		}
+	}
+
+	if writes != 0 {
+		t.Errorf("expected no write call to reach the SOLIDserver, got %d", writes)
+	}
+
+	if addresses, err := ipaddressfindfree(context.Background(), "3", "", s); err != nil || len(addresses) != 1 {
+		t.Errorf("expected lookups to keep working, got %v (%v)", addresses, err)
+	}
+}
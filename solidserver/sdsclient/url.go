@@ -0,0 +1,140 @@
+package sdsclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ParseBaseURL parses the base_url argument
+// Return the scheme, the host (with its port) and the path prefix of the SOLIDserver API
+func ParseBaseURL(baseurl string) (string, string, string, error) {
+	u, err := url.Parse(baseurl)
+
+	if err != nil {
+		return "", "", "", fmt.Errorf("SOLIDServer - Invalid base_url %s (%s)", baseurl, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", "", fmt.Errorf("SOLIDServer - Invalid base_url %s, the scheme must be http or https", baseurl)
+	}
+
+	if u.Host == "" {
+		return "", "", "", fmt.Errorf("SOLIDServer - Invalid base_url %s, missing host", baseurl)
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", "", "", fmt.Errorf("SOLIDServer - Invalid base_url %s, query strings and fragments are not supported", baseurl)
+	}
+
+	return u.Scheme, u.Host, u.Path, nil
+}
+
+// Build the base URL of the API of a SOLIDserver endpoint
+// Every API call URL is built from it, the port is only added if the host does not specify one
+func (s *SOLIDserver) hostURL(host string) string {
+	scheme := s.Scheme
+
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if s.Port > 0 {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), fmt.Sprintf("%d", s.Port))
+		}
+	}
+
+	prefix := strings.Trim(s.PathPrefix, "/")
+
+	if prefix != "" {
+		return fmt.Sprintf("%s://%s/%s", scheme, host, prefix)
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+// Return the function selecting the HTTP proxy of each API call
+// An explicit proxy_url takes precedence over HTTPS_PROXY/HTTP_PROXY, NO_PROXY applies to both
+func (s *SOLIDserver) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if s.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(s.ProxyURL)
+
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("Invalid proxy_url %s", s.ProxyURL)
+	}
+
+	noProxy := os.Getenv("NO_PROXY")
+
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if noproxy(noProxy, req.URL.Host) {
+			return nil, nil
+		}
+
+		return proxyURL, nil
+	}, nil
+}
+
+// Check if a host matches a NO_PROXY list
+// Entries are hosts, domain suffixes, IP addresses, CIDR ranges or * for every host
+func noproxy(list string, host string) bool {
+	hostname, port, err := net.SplitHostPort(host)
+
+	if err != nil {
+		hostname = strings.Trim(host, "[]")
+		port = ""
+	}
+
+	hostname = strings.ToLower(hostname)
+	ip := net.ParseIP(hostname)
+
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry == "" {
+			continue
+		}
+
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		// An entry with a port only matches this port
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+
+		entry = strings.TrimPrefix(strings.Trim(entry, "[]"), "*")
+
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(hostname, entry) || hostname == entry[1:] {
+				return true
+			}
+			continue
+		}
+
+		if hostname == entry || strings.HasSuffix(hostname, "."+entry) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package sdsclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestHostURL(t *testing.T) {
+	cases := []struct {
+		s        *SOLIDserver
+		host     string
+		expected string
+	}{
+		{&SOLIDserver{}, "sds.local", "https://sds.local"},
+		{&SOLIDserver{Port: 8443}, "sds.local", "https://sds.local:8443"},
+		{&SOLIDserver{Port: 8443}, "sds.local:9443", "https://sds.local:9443"},
+		{&SOLIDserver{Port: 8443}, "::1", "https://[::1]:8443"},
+		{&SOLIDserver{Scheme: "http", PathPrefix: "/solidserver/"}, "proxy.local", "http://proxy.local/solidserver"},
+	}
+
+	for _, c := range cases {
+		if url := c.s.hostURL(c.host); url != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.host, c.expected, url)
+		}
+	}
+}
+
+func TestParseBaseURL(t *testing.T) {
+	scheme, host, prefix, err := ParseBaseURL("http://proxy.local:8080/solidserver")
+
+	if err != nil || scheme != "http" || host != "proxy.local:8080" || prefix != "/solidserver" {
+		t.Errorf("unexpected result: %s %s %s %v", scheme, host, prefix, err)
+	}
+
+	for _, baseurl := range []string{"ftp://sds.local", "https://", "https://sds.local/?a=b", "sds.local"} {
+		if _, _, _, err := ParseBaseURL(baseurl); err == nil {
+			t.Errorf("%s: expected an error", baseurl)
+		}
+	}
+}
+
+func TestNoProxy(t *testing.T) {
+	list := "localhost, .internal.local, example.com, 10.0.0.0/8, sds.local:8443"
+
+	cases := map[string]bool{
+		"localhost:443":         true,
+		"sds.internal.local":    true,
+		"internal.local":        true,
+		"api.example.com":       true,
+		"example.org":           false,
+		"10.1.2.3:443":          true,
+		"192.168.0.1":           false,
+		"sds.local:8443":        true,
+		"sds.local:443":         false,
+		"notexample.com":        false,
+		"sds.internal.local:80": true,
+	}
+
+	for host, expected := range cases {
+		if noproxy(list, host) != expected {
+			t.Errorf("%s: expected %v", host, expected)
+		}
+	}
+
+	if !noproxy("*", "sds.local") {
+		t.Errorf("expected * to match every host")
+	}
+}
+
+func TestNewSOLIDserver_BaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/solidserver/rest/member_list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer server.Close()
+
+	s, err := NewSOLIDserver("", "ipmadmin", "admin", true, "", SOLIDserverOptions{BaseURL: server.URL + "/solidserver/"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.baseURL() != server.URL+"/solidserver" {
+		t.Errorf("unexpected base URL %s", s.baseURL())
+	}
+}
+
+func TestNewSOLIDserver_ProxyURL(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "sds.invalid:8080" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `[{"member_version": "7.0.1"}]`)
+	}))
+	defer proxy.Close()
+
+	defer os.Setenv("NO_PROXY", os.Getenv("NO_PROXY"))
+	defer os.Setenv("no_proxy", os.Getenv("no_proxy"))
+
+	os.Setenv("NO_PROXY", "")
+	os.Setenv("no_proxy", "")
+
+	_, err := NewSOLIDserver("sds.invalid:8080", "ipmadmin", "admin", true, "", SOLIDserverOptions{
+		BaseURL:  "http://sds.invalid:8080",
+		ProxyURL: proxy.URL,
+	})
+
+	if err != nil {
+		t.Fatalf("expected the call to go through the proxy, got %s", err)
+	}
+}
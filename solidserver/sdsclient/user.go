@@ -0,0 +1,369 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// User is an administration user
+type User struct {
+	ID              string
+	Login           string
+	Description     string
+	FirstName       string
+	LastName        string
+	Email           string
+	ClassParameters map[string]string
+	Groups          []string
+}
+
+// UserSpec describes an administration user to create or update
+// The password is left unchanged on update when empty
+type UserSpec struct {
+	Login       string
+	Password    string
+	Description string
+	FirstName   string
+	LastName    string
+	Email       string
+}
+
+// Group is a group of administration users
+type Group struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+// GroupSpec describes a group of administration users to create or update
+type GroupSpec struct {
+	Name        string
+	Description string
+}
+
+// CreateUser creates an administration user
+// The user is then added to its groups with AddUserToGroup
+func (s *SOLIDserver) CreateUser(ctx context.Context, spec UserSpec) (*User, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("add_flag", "new_only")
+	parameters.Add("usr_login", spec.Login)
+	parameters.Add("usr_password", spec.Password)
+
+	if len(spec.Description) > 0 {
+		parameters.Add("usr_description", spec.Description)
+	}
+
+	if len(spec.Email) > 0 {
+		parameters.Add("usr_email", spec.Email)
+	}
+
+	if len(spec.LastName) > 0 {
+		parameters.Add("usr_lname", spec.LastName)
+	}
+
+	if len(spec.FirstName) > 0 {
+		parameters.Add("usr_fname", spec.FirstName)
+	}
+
+	// Sending creation request of the user
+	oid, err := s.add(ctx, "post", "rest/user_add", &parameters, nil, "SOLIDServer - Unable to create user: %s", spec.Login)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] - Created user (oid): %s\n", oid)
+
+	return &User{
+		ID:          oid,
+		Login:       spec.Login,
+		Description: spec.Description,
+		FirstName:   spec.FirstName,
+		LastName:    spec.LastName,
+		Email:       spec.Email,
+	}, nil
+}
+
+// GetUser reads an administration user and its groups from its oid
+func (s *SOLIDserver) GetUser(ctx context.Context, id string) (*User, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("usr_id", id)
+
+	// Sending read request
+	row, err := s.info(ctx, "rest/user_admin_info", &parameters, "SOLIDServer - Unable to find user (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] found user (oid): %s\n", id)
+
+	user := &User{
+		ID:              id,
+		Login:           rowstring(row, "usr_login"),
+		Description:     rowstring(row, "usr_description"),
+		FirstName:       rowstring(row, "usr_fname"),
+		LastName:        rowstring(row, "usr_lname"),
+		Email:           rowstring(row, "usr_email"),
+		ClassParameters: rowclassparams(row, "usr_class_parameters"),
+		Groups:          []string{},
+	}
+
+	// Get groups for this user id
+	parameters = url.Values{}
+	parameters.Add("usr_id", id)
+	parameters.Add("ORDERBY", "grp_name")
+
+	// Sending the read request
+	groupRows, err := s.ListAll(ctx, "rest/user_admin_group_list", &parameters)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	for _, elem := range groupRows {
+		user.Groups = append(user.Groups, rowstring(elem, "grp_id"))
+	}
+
+	return user, nil
+}
+
+// UpdateUser updates an administration user, not its groups
+func (s *SOLIDserver) UpdateUser(ctx context.Context, id string, spec UserSpec) (*User, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("usr_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("usr_login", spec.Login)
+	parameters.Add("usr_description", spec.Description)
+	parameters.Add("usr_email", spec.Email)
+	parameters.Add("usr_lname", spec.LastName)
+	parameters.Add("usr_fname", spec.FirstName)
+
+	if len(spec.Password) > 0 {
+		parameters.Add("usr_password", spec.Password)
+	}
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/user_add", &parameters, nil, "SOLIDServer - Unable to update user: %s", spec.Login)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] - Updated user (oid): %s\n", oid)
+
+	return &User{
+		ID:          oid,
+		Login:       spec.Login,
+		Description: spec.Description,
+		FirstName:   spec.FirstName,
+		LastName:    spec.LastName,
+		Email:       spec.Email,
+	}, nil
+}
+
+// DeleteUser deletes an administration user
+func (s *SOLIDserver) DeleteUser(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("usr_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/user_delete", &parameters, "SOLIDServer - Unable to delete user (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted user (oid): %s\n", id)
+
+	return nil
+}
+
+// AddUserToGroup adds an administration user to a group
+func (s *SOLIDserver) AddUserToGroup(ctx context.Context, userID string, groupID string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("add_flag", "new_only")
+	parameters.Add("grp_id", groupID)
+	parameters.Add("usr_id", userID)
+
+	log.Printf("[DEBUG] - add user in group %s\n", parameters)
+
+	// Sending the request
+	resp, _, buf, err := s.call(ctx, "post", "rest/group_user_add", &parameters, nil)
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	// Checking the answer
+	if resp.StatusCode == 200 || resp.StatusCode == 201 || resp.StatusCode == 400 {
+		if len(buf) > 0 {
+			if errno, _ := buf[0]["errno"].(string); errno == "0" {
+				log.Printf("[DEBUG] - User affected to group %s\n", groupID)
+				return nil
+			}
+		} else if resp.StatusCode == 400 {
+			// need to FIX the #0048042 (04/04/19), return 400 as status code
+			log.Printf("[DEBUG] - waiting for path of #0048042, validate even with 400\n")
+			log.Printf("[DEBUG] - User affected to group %s\n", groupID)
+			return nil
+		}
+	}
+
+	// Reporting a failure
+	return fmt.Errorf("SOLIDServer - Unable to affect user (oid) %s to group (oid) %s", userID, groupID)
+}
+
+// RemoveUserFromGroup removes an administration user from a group
+func (s *SOLIDserver) RemoveUserFromGroup(ctx context.Context, login string, groupID string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("grp_id", groupID)
+	parameters.Add("usr_login", login)
+
+	// Sending the request
+	resp, _, buf, err := s.call(ctx, "delete", "rest/group_user_delete", &parameters, nil)
+
+	if err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	// Checking the answer
+	if resp.StatusCode == 204 && len(buf) == 0 {
+		log.Printf("[DEBUG] - User removed from group %s\n", groupID)
+		return nil
+	}
+
+	log.Printf("[DEBUG] - remove error %d %s\n", resp.StatusCode, buf)
+
+	// Reporting a failure
+	return fmt.Errorf("SOLIDServer - error in removing user (%s) from group (id=%s)", login, groupID)
+}
+
+// CreateGroup creates a group of administration users
+func (s *SOLIDserver) CreateGroup(ctx context.Context, spec GroupSpec) (*Group, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("add_flag", "new_only")
+	parameters.Add("grp_name", spec.Name)
+
+	if len(spec.Description) > 0 {
+		parameters.Add("grp_description", spec.Description)
+	}
+
+	// Sending creation request of the group
+	oid, err := s.add(ctx, "post", "rest/group_add", &parameters, nil, "SOLIDServer - Unable to create group: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] - Created group (oid): %s\n", oid)
+
+	return &Group{ID: oid, Name: spec.Name, Description: spec.Description}, nil
+}
+
+// GetGroup reads a group of administration users from its oid
+func (s *SOLIDserver) GetGroup(ctx context.Context, id string) (*Group, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("grp_id", id)
+
+	// Sending read request
+	row, err := s.info(ctx, "rest/group_admin_info", &parameters, "SOLIDServer - Unable to find group (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] found group (oid): %s\n", id)
+
+	return &Group{
+		ID:          id,
+		Name:        rowstring(row, "grp_name"),
+		Description: rowstring(row, "grp_description"),
+	}, nil
+}
+
+// GetGroupByName reads a group of administration users from its name
+func (s *SOLIDserver) GetGroupByName(ctx context.Context, name string) (*Group, error) {
+	// Building parameters
+	parameters := url.Values{}
+	where, whereErr := WhereEq("grp_name", name).Clause()
+
+	if whereErr != nil {
+		// Reporting a failure
+		return nil, whereErr
+	}
+
+	parameters.Add("WHERE", where)
+
+	// Sending read request
+	row, err := s.info(ctx, "rest/group_admin_list", &parameters, "SOLIDServer - Unable to find group: %s", name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Found group: %s\n", name)
+
+	return &Group{
+		ID:          rowstring(row, "grp_id"),
+		Name:        rowstring(row, "grp_name"),
+		Description: rowstring(row, "grp_description"),
+	}, nil
+}
+
+// UpdateGroup updates a group of administration users
+func (s *SOLIDserver) UpdateGroup(ctx context.Context, id string, spec GroupSpec) (*Group, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("grp_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("grp_name", spec.Name)
+	parameters.Add("grp_description", spec.Description)
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/group_add", &parameters, nil, "SOLIDServer - Unable to update group: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] - Updated group (oid): %s\n", oid)
+
+	return &Group{ID: oid, Name: spec.Name, Description: spec.Description}, nil
+}
+
+// DeleteGroup deletes a group of administration users
+func (s *SOLIDserver) DeleteGroup(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("grp_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/group_delete", &parameters, "SOLIDServer - error deleting group (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] - group deleted (oid): %s\n", id)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package sdsclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version of a SOLIDserver, as reported by its member_version field (ex: 7.0.1.12345)
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	Build int
+}
+
+// Parse a SOLIDserver version string, missing parts default to 0
+// Suffixes such as patch levels (ex: 7.0.1-p2) are ignored
+func ParseVersion(version string) (Version, error) {
+	v := Version{}
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	fields := []*int{&v.Major, &v.Minor, &v.Patch, &v.Build}
+
+	for i := 0; i < len(parts) && i < len(fields); i++ {
+		digits := strings.IndexFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+
+		if digits < 0 {
+			digits = len(parts[i])
+		}
+
+		if digits == 0 {
+			if i == 0 {
+				return Version{}, fmt.Errorf("SOLIDServer - Invalid version: %s", version)
+			}
+			break
+		}
+
+		*fields[i], _ = strconv.Atoi(parts[i][:digits])
+
+		// Ignore what follows a suffix
+		if digits < len(parts[i]) {
+			break
+		}
+	}
+
+	return v, nil
+}
+
+func (v Version) String() string {
+	if v.Build != 0 {
+		return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Build)
+	}
+
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Compare returns -1, 0 or 1 whether v is older, equal or newer than o
+func (v Version) Compare(o Version) int {
+	a := []int{v.Major, v.Minor, v.Patch, v.Build}
+	b := []int{o.Major, o.Minor, o.Patch, o.Build}
+
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// AtLeast reports whether v is equal to or newer than o
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+// Features depending on the SOLIDserver version
+const (
+	CapabilityApplications   = "applications"
+	CapabilityVXLAN          = "vxlan"
+	CapabilityFreeVlanRanges = "free vlan ranges"
+)
+
+// Minimum SOLIDserver version of each feature
+var capabilities = map[string]Version{
+	CapabilityApplications:   {Major: 7, Minor: 1},
+	CapabilityVXLAN:          {Major: 7},
+	CapabilityFreeVlanRanges: {Major: 7},
+}
+
+// Supports reports whether the SOLIDserver supports a feature
+func (s *SOLIDserver) Supports(feature string) bool {
+	minVersion, known := capabilities[feature]
+
+	return known && s.Version.AtLeast(minVersion)
+}
+
+// Requires returns an error explaining which version a feature requires if the SOLIDserver does not support it
+func (s *SOLIDserver) Requires(feature string) error {
+	if s.Supports(feature) {
+		return nil
+	}
+
+	minVersion, known := capabilities[feature]
+
+	if !known {
+		return fmt.Errorf("SOLIDServer - Unknown feature: %s", feature)
+	}
+
+	return fmt.Errorf("SOLIDServer - %s requires SOLIDserver >= %s (current version: %s)", feature, minVersion, s.Version)
+}
@@ -0,0 +1,76 @@
+package sdsclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]Version{
+		"7":             {Major: 7},
+		"7.0.1":         {Major: 7, Patch: 1},
+		"6.0.2.P3":      {Major: 6, Patch: 2},
+		"7.1.0.12345":   {Major: 7, Minor: 1, Build: 12345},
+		"7.0.1-p2.1234": {Major: 7, Patch: 1},
+		" 8.2.0 ":       {Major: 8, Minor: 2},
+	}
+
+	for version, expected := range cases {
+		v, err := ParseVersion(version)
+
+		if err != nil || v != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", version, expected, v, err)
+		}
+	}
+
+	for _, version := range []string{"", "v7.0.1", "unknown"} {
+		if _, err := ParseVersion(version); err == nil {
+			t.Errorf("%q: expected an error", version)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	cases := []struct {
+		a        Version
+		b        Version
+		expected int
+	}{
+		{Version{Major: 7}, Version{Major: 7}, 0},
+		{Version{Major: 6, Minor: 9}, Version{Major: 7}, -1},
+		{Version{Major: 7, Minor: 1}, Version{Major: 7, Patch: 9}, 1},
+		{Version{Major: 7, Patch: 1, Build: 2}, Version{Major: 7, Patch: 1, Build: 10}, -1},
+	}
+
+	for _, c := range cases {
+		if r := c.a.Compare(c.b); r != c.expected {
+			t.Errorf("%s vs %s: expected %d, got %d", c.a, c.b, c.expected, r)
+		}
+	}
+
+	if !(Version{Major: 7, Minor: 1}).AtLeast(Version{Major: 7, Minor: 1}) {
+		t.Errorf("expected a version to be at least itself")
+	}
+}
+
+func TestSupports(t *testing.T) {
+	s := &SOLIDserver{Version: Version{Major: 7, Patch: 1}}
+
+	if !s.Supports(CapabilityVXLAN) || !s.Supports(CapabilityFreeVlanRanges) {
+		t.Errorf("expected 7.0.1 to support vxlan and free vlan ranges")
+	}
+
+	if s.Supports(CapabilityApplications) || s.Supports("unknown") {
+		t.Errorf("expected 7.0.1 not to support applications")
+	}
+
+	err := s.Requires(CapabilityApplications)
+
+	if err == nil || !strings.Contains(err.Error(), "applications requires SOLIDserver >= 7.1.0") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := s.Requires(CapabilityVXLAN); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
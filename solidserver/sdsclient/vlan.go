@@ -0,0 +1,271 @@
+package sdsclient
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// VLANDomain is a VLAN or VXLAN domain
+type VLANDomain struct {
+	ID              string
+	Name            string
+	VXLAN           bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// VLANDomainSpec describes a VLAN or VXLAN domain to create or update
+type VLANDomainSpec struct {
+	Name            string
+	VXLAN           bool
+	Class           string
+	ClassParameters map[string]string
+}
+
+// VLAN is a VLAN of a VLAN domain
+type VLAN struct {
+	ID     string
+	Domain string
+	VLANID int
+	Name   string
+}
+
+// VLANSpec describes a VLAN to create or update
+// Without VLANID, a free vlan ID of the domain is used
+type VLANSpec struct {
+	Domain string
+	VLANID int
+	Name   string
+}
+
+// Build the parameters shared by the creation and the update of a VLAN domain
+func vlandomainparameters(s *SOLIDserver, spec VLANDomainSpec) (url.Values, error) {
+	parameters := url.Values{}
+	parameters.Add("vlmdomain_name", spec.Name)
+	parameters.Add("vlmdomain_class_name", spec.Class)
+	parameters.Add("vlmdomain_class_parameters", encodeclassparams(spec.ClassParameters).Encode())
+
+	if spec.VXLAN {
+		if err := s.Requires(CapabilityVXLAN); err != nil {
+			return nil, err
+		}
+
+		parameters.Add("support_vxlan", "1")
+	}
+
+	return parameters, nil
+}
+
+// CreateVLANDomain creates a VLAN domain
+func (s *SOLIDserver) CreateVLANDomain(ctx context.Context, spec VLANDomainSpec) (*VLANDomain, error) {
+	// Building parameters
+	parameters, err := vlandomainparameters(s, spec)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	parameters.Add("add_flag", "new_only")
+
+	// Sending the creation request
+	oid, err := s.add(ctx, "post", "rest/vlm_domain_add", &parameters, nil, "SOLIDServer - Unable to create VLAN Domain: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Created VLAN Domain (oid): %s\n", oid)
+	s.cache.invalidate(cacheVlmdomain, strings.ToLower(spec.Name))
+
+	return &VLANDomain{ID: oid, Name: spec.Name, VXLAN: spec.VXLAN, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// GetVLANDomain reads a VLAN domain from its oid
+func (s *SOLIDserver) GetVLANDomain(ctx context.Context, id string) (*VLANDomain, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("vlmdomain_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/vlmdomain_info", &parameters, "SOLIDServer - Unable to find VLAN Domain (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &VLANDomain{
+		ID:              id,
+		Name:            rowstring(row, "vlmdomain_name"),
+		VXLAN:           rowbool(row, "support_vxlan"),
+		Class:           rowstring(row, "vlmdomain_class_name"),
+		ClassParameters: rowclassparams(row, "vlmdomain_class_parameters"),
+	}, nil
+}
+
+// UpdateVLANDomain updates a VLAN domain
+func (s *SOLIDserver) UpdateVLANDomain(ctx context.Context, id string, spec VLANDomainSpec) (*VLANDomain, error) {
+	// Building parameters
+	parameters, err := vlandomainparameters(s, spec)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	parameters.Add("vlmdomain_id", id)
+	parameters.Add("add_flag", "edit_only")
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/vlm_domain_add", &parameters, nil, "SOLIDServer - Unable to update VLAN Domain: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated VLAN Domain (oid): %s\n", oid)
+	s.cache.forget(cacheVlmdomain, oid)
+
+	return &VLANDomain{ID: oid, Name: spec.Name, VXLAN: spec.VXLAN, Class: spec.Class, ClassParameters: spec.ClassParameters}, nil
+}
+
+// DeleteVLANDomain deletes a VLAN domain along with its vlans
+func (s *SOLIDserver) DeleteVLANDomain(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("vlmdomain_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/vlm_domain_delete", &parameters, "SOLIDServer - Unable to delete VLAN Domain (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted VLAN Domain (oid): %s\n", id)
+	s.cache.forget(cacheVlmdomain, id)
+
+	return nil
+}
+
+// CreateVLAN creates a VLAN in a VLAN domain
+func (s *SOLIDserver) CreateVLAN(ctx context.Context, spec VLANSpec) (*VLAN, error) {
+	var vlanIDs []string = nil
+
+	// Determining if a VLAN ID was submitted in or if we should get one from the VLAN Manager
+	if spec.VLANID > 0 {
+		vlanIDs = []string{strconv.Itoa(spec.VLANID)}
+	} else {
+		var vlanErr error = nil
+
+		vlanIDs, vlanErr = vlanidfindfree(ctx, spec.Domain, s)
+
+		if vlanErr != nil {
+			// Reporting a failure
+			return nil, vlanErr
+		}
+	}
+
+	failure := fmt.Errorf("SOLIDServer - Unable to create vlan: %s", spec.Name)
+
+	for i := 0; i < len(vlanIDs); i++ {
+		// Building parameters
+		parameters := url.Values{}
+		parameters.Add("add_flag", "new_only")
+		parameters.Add("vlmdomain_name", spec.Domain)
+		parameters.Add("vlmvlan_vlan_id", vlanIDs[i])
+		parameters.Add("vlmvlan_name", spec.Name)
+
+		// Sending creation request
+		resp, body, buf, err := s.call(ctx, "post", "rest/vlm_vlan_add", &parameters, nil)
+
+		if err != nil {
+			// Reporting a failure
+			return nil, err
+		}
+
+		// Checking the answer
+		if (resp.StatusCode == 200 || resp.StatusCode == 201) && len(buf) > 0 {
+			if oid, oidExist := buf[0]["ret_oid"].(string); oidExist {
+				log.Printf("[DEBUG] SOLIDServer - Created vlan (oid): %s\n", oid)
+
+				vnid, _ := strconv.Atoi(vlanIDs[i])
+
+				return &VLAN{ID: oid, Domain: spec.Domain, VLANID: vnid, Name: spec.Name}, nil
+			}
+		} else {
+			failure = apierrorf("rest/vlm_vlan_add", resp, body, "SOLIDServer - Unable to create vlan: %s", spec.Name)
+			log.Printf("[DEBUG] SOLIDServer - Failed vlan registration, trying another one.\n")
+		}
+	}
+
+	// Reporting a failure
+	return nil, failure
+}
+
+// GetVLAN reads a VLAN from its oid
+func (s *SOLIDserver) GetVLAN(ctx context.Context, id string) (*VLAN, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("vlmvlan_id", id)
+
+	// Sending the read request
+	row, err := s.info(ctx, "rest/vlmvlan_info", &parameters, "SOLIDServer - Unable to find vlan (oid): %s", id)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	return &VLAN{
+		ID:     id,
+		Domain: rowstring(row, "vlmdomain_name"),
+		VLANID: rowint(row, "vlmvlan_vlan_id"),
+		Name:   rowstring(row, "vlmvlan_name"),
+	}, nil
+}
+
+// UpdateVLAN updates the vlan ID and the name of a VLAN
+func (s *SOLIDserver) UpdateVLAN(ctx context.Context, id string, spec VLANSpec) (*VLAN, error) {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("vlmvlan_id", id)
+	parameters.Add("add_flag", "edit_only")
+	parameters.Add("vlmvlan_vlan_id", strconv.Itoa(spec.VLANID))
+	parameters.Add("vlmvlan_name", spec.Name)
+
+	// Sending the update request
+	oid, err := s.add(ctx, "put", "rest/vlm_vlan_add", &parameters, nil, "SOLIDServer - Unable to update vlan: %s", spec.Name)
+
+	if err != nil {
+		// Reporting a failure
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Updated vlan (oid): %s\n", oid)
+
+	return &VLAN{ID: oid, Domain: spec.Domain, VLANID: spec.VLANID, Name: spec.Name}, nil
+}
+
+// DeleteVLAN deletes a VLAN
+func (s *SOLIDserver) DeleteVLAN(ctx context.Context, id string) error {
+	// Building parameters
+	parameters := url.Values{}
+	parameters.Add("vlmvlan_id", id)
+
+	// Sending the deletion request
+	if err := s.remove(ctx, "rest/vlm_vlan_delete", &parameters, "SOLIDServer - Unable to delete vlan (oid): %s", id); err != nil {
+		// Reporting a failure
+		return err
+	}
+
+	log.Printf("[DEBUG] SOLIDServer - Deleted vlan (oid): %s\n", id)
+
+	return nil
+}
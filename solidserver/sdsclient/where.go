@@ -0,0 +1,144 @@
+package sdsclient
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Column names allowed in a WHERE clause
+var whereColumnFormat = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Where is a filter of a SOLIDserver list service, built from escaped conditions
+// It is sent as the WHERE parameter of the API call
+type Where struct {
+	clause string
+	err    error
+}
+
+// Clause returns the WHERE parameter of the API call
+// An invalid condition is reported rather than sending a call matching every object
+func (w Where) Clause() (string, error) {
+	if w.err != nil {
+		return "", w.err
+	}
+
+	return w.clause, nil
+}
+
+// Quote a value as a SOLIDserver string literal
+// Single quotes are doubled, so that the value can never end the literal
+func wherequote(value interface{}) string {
+	return "'" + strings.Replace(fmt.Sprint(value), "'", "''", -1) + "'"
+}
+
+// Check a column name, columns are always set by the provider itself
+func wherecolumn(column string) error {
+	if !whereColumnFormat.MatchString(column) {
+		return fmt.Errorf("SOLIDServer - Invalid column name in WHERE clause: %q", column)
+	}
+
+	return nil
+}
+
+func wherecompare(column string, operator string, value interface{}) Where {
+	if err := wherecolumn(column); err != nil {
+		return Where{err: err}
+	}
+
+	return Where{clause: column + operator + wherequote(value)}
+}
+
+// WhereEq matches the rows where column is equal to value
+func WhereEq(column string, value interface{}) Where {
+	return wherecompare(column, "=", value)
+}
+
+// WhereNe matches the rows where column is different from value
+func WhereNe(column string, value interface{}) Where {
+	return wherecompare(column, "!=", value)
+}
+
+// WhereLt matches the rows where column is lower than value
+func WhereLt(column string, value interface{}) Where {
+	return wherecompare(column, "<", value)
+}
+
+// WhereLe matches the rows where column is lower than or equal to value
+func WhereLe(column string, value interface{}) Where {
+	return wherecompare(column, "<=", value)
+}
+
+// WhereGt matches the rows where column is greater than value
+func WhereGt(column string, value interface{}) Where {
+	return wherecompare(column, ">", value)
+}
+
+// WhereGe matches the rows where column is greater than or equal to value
+func WhereGe(column string, value interface{}) Where {
+	return wherecompare(column, ">=", value)
+}
+
+// WhereLike matches the rows where column matches pattern
+// % and _ are wildcards, use WhereLikeEscape to match them literally
+func WhereLike(column string, pattern string) Where {
+	return wherecompare(column, " LIKE ", pattern)
+}
+
+// WhereLikeEscape escapes the LIKE wildcards of a value
+func WhereLikeEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// WhereIn matches the rows where column is one of values
+// No row matches an empty list of values
+func WhereIn(column string, values ...interface{}) Where {
+	if err := wherecolumn(column); err != nil {
+		return Where{err: err}
+	}
+
+	if len(values) == 0 {
+		return Where{clause: "1=0"}
+	}
+
+	quoted := make([]string, len(values))
+
+	for i, value := range values {
+		quoted[i] = wherequote(value)
+	}
+
+	return Where{clause: column + " IN (" + strings.Join(quoted, ",") + ")"}
+}
+
+func wherejoin(operator string, conditions []Where) Where {
+	clauses := []string{}
+
+	for _, condition := range conditions {
+		if condition.err != nil {
+			return condition
+		}
+
+		if condition.clause != "" {
+			clauses = append(clauses, condition.clause)
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return Where{}
+	case 1:
+		return Where{clause: clauses[0]}
+	}
+
+	return Where{clause: "(" + strings.Join(clauses, " "+operator+" ") + ")"}
+}
+
+// WhereAnd matches the rows matching every condition
+func WhereAnd(conditions ...Where) Where {
+	return wherejoin("AND", conditions)
+}
+
+// WhereOr matches the rows matching at least one condition
+func WhereOr(conditions ...Where) Where {
+	return wherejoin("OR", conditions)
+}
@@ -0,0 +1,72 @@
+package sdsclient
+
+import (
+	"testing"
+)
+
+func TestWhere_Escaping(t *testing.T) {
+	cases := []struct {
+		where    Where
+		expected string
+	}{
+		{WhereEq("site_name", "space"), `site_name='space'`},
+		{WhereEq("site_name", "O'Reilly"), `site_name='O''Reilly'`},
+		{WhereEq("site_name", "x' OR '1'='1"), `site_name='x'' OR ''1''=''1'`},
+		{WhereEq("site_name", "''"), `site_name=''''''`},
+		{WhereEq("site_name", ""), `site_name=''`},
+		{WhereEq("grp_name", "admins; DROP"), `grp_name='admins; DROP'`},
+		{WhereEq("grp_name", `back\slash`), `grp_name='back\slash'`},
+		{WhereEq("site_id", 12), `site_id='12'`},
+		{WhereNe("type", "free"), `type!='free'`},
+		{WhereLt("vlmvlan_vlan_id", 10), `vlmvlan_vlan_id<'10'`},
+		{WhereLe("vlmvlan_vlan_id", 10), `vlmvlan_vlan_id<='10'`},
+		{WhereGt("vlmvlan_vlan_id", 10), `vlmvlan_vlan_id>'10'`},
+		{WhereGe("vlmvlan_vlan_id", 10), `vlmvlan_vlan_id>='10'`},
+		{WhereLike("subnet_name", "web-%"), `subnet_name LIKE 'web-%'`},
+		{WhereLike("subnet_name", WhereLikeEscape("50%_o'k")+"%"), `subnet_name LIKE '50\%\_o''k%'`},
+		{WhereIn("site_id", "1", "2'"), `site_id IN ('1','2''')`},
+		{WhereIn("site_id"), `1=0`},
+	}
+
+	for _, c := range cases {
+		if clause, err := c.where.Clause(); err != nil || clause != c.expected {
+			t.Errorf("expected %s, got %s (%v)", c.expected, clause, err)
+		}
+	}
+}
+
+func TestWhere_Combination(t *testing.T) {
+	where := WhereAnd(
+		WhereEq("site_id", "2"),
+		WhereOr(WhereEq("subnet_name", "a'b"), WhereLike("subnet_name", "c%")),
+	)
+
+	expected := `(site_id='2' AND (subnet_name='a''b' OR subnet_name LIKE 'c%'))`
+
+	if clause, err := where.Clause(); err != nil || clause != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, clause, err)
+	}
+
+	if clause, _ := WhereAnd(Where{}, WhereEq("site_id", "2")).Clause(); clause != `site_id='2'` {
+		t.Errorf("expected empty conditions to be ignored, got %s", clause)
+	}
+
+	if clause, _ := WhereOr().Clause(); clause != "" {
+		t.Errorf("expected an empty clause, got %s", clause)
+	}
+}
+
+func TestWhere_InvalidColumn(t *testing.T) {
+	invalid := []Where{
+		WhereEq("site_name='x' OR site_name", "y"),
+		WhereLike("site_name LIKE '%' OR site_name", "y"),
+		WhereIn("1=1 OR site_id"),
+		WhereOr(WhereEq("site_id", "2"), WhereAnd(WhereNe("site_name", "a"), WhereGt("", "b"))),
+	}
+
+	for _, where := range invalid {
+		if clause, err := where.Clause(); err == nil {
+			t.Errorf("expected an invalid column name to be rejected, got %s", clause)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package solidserver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient/sdsfake"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// Start a fake SOLIDserver and a client connected to it, the server must be closed by the test
+func newFakeSOLIDserver(t *testing.T) (*sdsfake.Server, *sdsclient.SOLIDserver) {
+	f := sdsfake.New(t)
+
+	s, err := sdsclient.NewSOLIDserver("", sdsfake.Username, sdsfake.Password, false, "", sdsclient.SOLIDserverOptions{BaseURL: f.URL})
+
+	if err != nil {
+		f.Close()
+		t.Fatalf("unable to connect to the fake SOLIDserver: %s", err)
+	}
+
+	return f, s
+}
+
+// Create a resource from its configuration and read it back
+func testResourceCreate(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	d := schema.TestResourceDataRaw(t, r.Schema, raw)
+
+	if err := r.Create(d, s); err != nil {
+		t.Fatalf("unexpected error on create: %s", err)
+	}
+
+	if d.Id() == "" {
+		t.Fatalf("no oid set on create")
+	}
+
+	if err := r.Read(d, s); err != nil {
+		t.Fatalf("unexpected error on read: %s", err)
+	}
+
+	return d
+}
+
+// Build the configuration of a resource
+func testResourceConfig(t *testing.T, raw map[string]interface{}) *terraform.ResourceConfig {
+	t.Helper()
+
+	c, err := config.NewRawConfig(raw)
+
+	if err != nil {
+		t.Fatalf("invalid configuration: %s", err)
+	}
+
+	return terraform.NewResourceConfig(c)
+}
+
+// Plan a new configuration against the state of an existing resource, which must be updated in place
+func testResourceDiff(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, d *schema.ResourceData, raw map[string]interface{}) *terraform.InstanceDiff {
+	t.Helper()
+
+	diff, err := r.Diff(d.State(), testResourceConfig(t, raw), s)
+
+	if err != nil {
+		t.Fatalf("unexpected error on plan: %s", err)
+	}
+
+	if diff == nil || diff.RequiresNew() {
+		t.Fatalf("expected the resource to be updated in place, got %#v", diff)
+	}
+
+	return diff
+}
+
+// Apply a new configuration to an existing resource and read it back
+func testResourceUpdate(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, d *schema.ResourceData, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	state, err := r.Apply(d.State(), testResourceDiff(t, s, r, d, raw), s)
+
+	if err != nil {
+		t.Fatalf("unexpected error on update: %s", err)
+	}
+
+	updated := r.Data(state)
+
+	if updated.Id() != d.Id() {
+		t.Errorf("expected the oid %s to be kept on update, got %s", d.Id(), updated.Id())
+	}
+
+	if err := r.Read(updated, s); err != nil {
+		t.Fatalf("unexpected error on read: %s", err)
+	}
+
+	return updated
+}
+
+// Import a resource from its oid and read it back
+func testResourceImport(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, id string) *schema.ResourceData {
+	t.Helper()
+
+	d := r.TestResourceData()
+	d.SetId(id)
+
+	imported, err := r.Importer.State(d, s)
+
+	if err != nil {
+		t.Fatalf("unexpected error on import: %s", err)
+	}
+
+	if len(imported) != 1 || imported[0].Id() != id {
+		t.Fatalf("expected a single imported resource with oid %s", id)
+	}
+
+	if err := r.Read(imported[0], s); err != nil {
+		t.Fatalf("unexpected error on read: %s", err)
+	}
+
+	return imported[0]
+}
+
+// Delete a resource and check it is gone
+func testResourceDelete(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, d *schema.ResourceData) {
+	t.Helper()
+
+	id := d.Id()
+
+	if err := r.Delete(d, s); err != nil {
+		t.Fatalf("unexpected error on delete: %s", err)
+	}
+
+	if d.Id() != "" {
+		t.Errorf("expected the oid to be unset on delete")
+	}
+
+	testResourceGone(t, s, r, id)
+}
+
+// Check the SOLIDserver reports a resource as gone
+func testResourceGone(t *testing.T, s *sdsclient.SOLIDserver, r *schema.Resource, id string) {
+	t.Helper()
+
+	d := r.TestResourceData()
+	d.SetId(id)
+
+	exists, err := r.Exists(d, s)
+
+	if err != nil || exists {
+		t.Errorf("expected oid %s to be gone, got exists: %t, error: %v", id, exists, err)
+	}
+}
+
+// Check the attributes of a resource
+func testResourceAttributes(t *testing.T, d *schema.ResourceData, expected map[string]interface{}) {
+	t.Helper()
+
+	for k, v := range expected {
+		if got := d.Get(k); !reflect.DeepEqual(got, v) {
+			t.Errorf("expected %s to be %#v, got %#v", k, v, got)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package solidserver
+
+import (
+	"context"
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+	"time"
+)
+
+const (
+	// Default time given to create a resource, including retries
+	DefaultCreateTimeout = 10 * time.Minute
+	// Default time given to update a resource, including retries
+	DefaultUpdateTimeout = 10 * time.Minute
+	// Default time given to delete a resource, including retries
+	DefaultDeleteTimeout = 10 * time.Minute
+)
+
+// Return the operation timeouts of a resource, each can be overridden
+// within the timeouts block of the resource
+func resourcetimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(DefaultCreateTimeout),
+		Update: schema.DefaultTimeout(DefaultUpdateTimeout),
+		Delete: schema.DefaultTimeout(DefaultDeleteTimeout),
+	}
+}
+
+// Return the context of a resource operation (schema.TimeoutCreate, ...)
+// Its API calls are abandoned once the operation timed out or terraform is interrupted
+func resourcecontext(d *schema.ResourceData, meta interface{}, operation string) (context.Context, context.CancelFunc) {
+	s := meta.(*sdsclient.SOLIDserver)
+
+	return context.WithTimeout(s.StopContext(), d.Timeout(operation))
+}
@@ -0,0 +1,24 @@
+package solidserver
+
+import (
+	"github.com/alexissavin/terraform-provider-solidserver/solidserver/sdsclient"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Build a CustomizeDiff function failing at plan time when the SOLIDserver does not support a feature
+// The feature is only checked when the used function returns true, or always if it is nil
+func resourcerequires(feature string, used func(d *schema.ResourceDiff) bool) schema.CustomizeDiffFunc {
+	return func(d *schema.ResourceDiff, meta interface{}) error {
+		s, configured := meta.(*sdsclient.SOLIDserver)
+
+		if !configured || s == nil {
+			return nil
+		}
+
+		if used != nil && !used(d) {
+			return nil
+		}
+
+		return s.Requires(feature)
+	}
+}